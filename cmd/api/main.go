@@ -59,20 +59,13 @@ func main() {
 	}
 	defer redisClient.Close()
 
-	// Инициализируем MinIO клиент
-	minioClient, err := storage.NewMinioClient(
-		cfg.Minio.Endpoint,
-		cfg.Storage.BaseURL,
-		cfg.Minio.AccessKey,
-		cfg.Minio.SecretKey,
-		cfg.Minio.UseSSL,
-		cfg.Storage.UseSSL,
-	)
+	// Инициализируем хранилище объектов (MinIO или локальный диск)
+	objectStorage, err := storage.NewObjectStorage(cfg)
 	if err != nil {
-		log.Fatal("Failed to create MinIO client: %v", err)
+		log.Fatal("Failed to create object storage: %v", err)
 	}
 
-	if err := minioClient.EnsureBucketExists(ctx, constants.ThumbnailsBucket); err != nil {
+	if err := objectStorage.EnsureBucketExists(ctx, constants.ThumbnailsBucket); err != nil {
 		log.Fatal("Failed to create thumbnails bucket: %v", err)
 	}
 
@@ -86,6 +79,16 @@ func main() {
 	}
 	defer kafkaProducer.Close()
 
+	// Dead letter топик для заданий конвертации, исчерпавших попытки
+	deadLetterProducer, err := kafka.NewProducer(
+		cfg.Kafka.Brokers,
+		cfg.Kafka.DeadLetterTopic,
+	)
+	if err != nil {
+		log.Fatal("Failed to connect kafka: %v", err)
+	}
+	defer deadLetterProducer.Close()
+
 	// Инициализируем валидатор
 	validator := validator.NewValidator()
 
@@ -97,6 +100,7 @@ func main() {
 		cfg.Auth.RefreshTokenDuration,
 	)
 	passwordService := jwt.NewPasswordService()
+	shareTokenService := jwt.NewShareTokenService(cfg.Auth.ShareTokenSecret)
 
 	// Инициализируем репозитории
 	userRepo := repositories.NewUserRepository(db)
@@ -104,29 +108,78 @@ func main() {
 	videoRepo := repositories.NewVideoRepository(db)
 	commentRepo := repositories.NewCommentRepository(db)
 	categoryRepo := repositories.NewCategoryRepository(db)
+	conversionJobRepo := repositories.NewConversionJobRepository(db)
+	videoThumbnailRepo := repositories.NewVideoThumbnailRepository(db)
+	videoSubtitleRepo := repositories.NewVideoSubtitleRepository(db)
+	videoUploadRepo := repositories.NewVideoUploadRepository(db)
+	uploadQuotaRepo := repositories.NewUploadQuotaRepository(db)
+	videoImportRepo := repositories.NewVideoImportRepository(db)
+	uploadBatchRepo := repositories.NewUploadBatchRepository(db)
+	videoShareRepo := repositories.NewVideoShareRepository(db)
 
 	// Инициализируем бизнес-логику
 	authService := services.NewAuthService(userRepo, tokenRepo, passwordService, jwtService)
-	videoService := services.NewVideoService(videoRepo, minioClient, kafkaProducer, redisClient, cfg.Storage.ShardCount)
+	quotaService := services.NewQuotaService(uploadQuotaRepo, userRepo, cfg.Upload.MaxFileSize)
+	shareService := services.NewShareService(videoShareRepo, userRepo, shareTokenService, passwordService, redisClient)
+	videoService := services.NewVideoService(videoRepo, userRepo, objectStorage, kafkaProducer, redisClient, quotaService, shareService, cfg.Storage.ShardCount)
 	commentService := services.NewCommentService(commentRepo, videoService)
 	categoryService := services.NewCategoryService(categoryRepo, redisClient)
+	thumbnailService := services.NewThumbnailService(videoThumbnailRepo, objectStorage)
+	subtitleService := services.NewSubtitleService(videoSubtitleRepo, objectStorage)
+	uploadService := services.NewUploadService(videoUploadRepo, videoService, objectStorage)
+	importService := services.NewImportService(videoImportRepo, videoRepo, videoService, cfg.Upload.ImportTimeout)
+	batchService := services.NewBatchService(uploadBatchRepo, videoService, cfg.Upload.BatchTempDir, cfg.Upload.MaxFileSize, cfg.Upload.MaxBatchSize)
 
 	// Инициализируем HTTP обработчики
 	authHandler := handlers.NewAuthHandler(authService, validator)
-	videoHandler := handlers.NewVideoHandler(videoService, validator)
+	videoHandler := handlers.NewVideoHandler(videoService, subtitleService, categoryService, validator)
 	commentHandler := handlers.NewCommentHandler(commentService, validator)
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
+	thumbnailHandler := handlers.NewThumbnailHandler(videoService, thumbnailService)
+	subtitleHandler := handlers.NewSubtitleHandler(videoService, subtitleService)
+	tusHandler := handlers.NewTusHandler(uploadService)
+	uploadHandler := handlers.NewUploadHandler(uploadService, validator)
+	importHandler := handlers.NewImportHandler(videoService, importService, validator)
+	batchHandler := handlers.NewBatchHandler(batchService, categoryService, validator)
+	shareHandler := handlers.NewShareHandler(videoService, shareService, validator)
 
 	// Конвертация
 	conversionService := services.NewConversionService(
 		videoRepo,
-		minioClient,
-		"/tmp/video-conversion",
+		conversionJobRepo,
+		videoThumbnailRepo,
+		videoSubtitleRepo,
+		objectStorage,
+		redisClient,
+		cfg.Conversion.TempDir,
+		cfg.Conversion.RetryPolicies,
+		cfg.Upload.MaxDuration,
+		deadLetterProducer,
+		kafkaProducer,
 	)
+	conversionHandler := handlers.NewConversionHandler(conversionService)
+
+	// Запуск очереди конвертации, если нет отдельных воркеров (cmd/worker)
+	if cfg.Conversion.EmbeddedQueue {
+		conversionService.StartConversionQueue()
+		defer conversionService.StopConversionQueue()
+	}
+
+	// Удаление просроченных возобновляемых загрузок
+	uploadService.StartCleanup()
+	defer uploadService.StopCleanup()
 
-	// Запуск очереди конвертации
-	conversionService.StartConversionQueue()
-	defer conversionService.StopConversionQueue()
+	// Публикация запланированных видео
+	videoService.StartPublishScheduler()
+	defer videoService.StopPublishScheduler()
+
+	// Скачивание видео, импортируемых по ссылке
+	importService.StartImportQueue()
+	defer importService.StopImportQueue()
+
+	// Завершение пакетных загрузок, прерванных падением процесса
+	batchService.StartBatchProcessing()
+	defer batchService.StopBatchProcessing()
 
 	// Создаем Echo-сервер
 	e := echo.New()
@@ -134,10 +187,15 @@ func main() {
 	// Настраиваем middleware
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		// Клиенту tus нужны заголовки с состоянием загрузки
+		ExposeHeaders: handlers.TusExposeHeaders,
+	}))
 
 	// Добавляем аутентификационное middleware
 	authMiddleware := apiMiddleware.AuthMiddleware(jwtService)
+	// Публичные эндпоинты видео узнают владельца по токену, если он передан
+	optionalAuthMiddleware := apiMiddleware.OptionalAuthMiddleware(jwtService)
 
 	// Роут для swagger UI
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
@@ -154,7 +212,13 @@ func main() {
 	// Публичные эндпоинты видео
 	apiV1.GET("/videos/new", videoHandler.GetNewVideos)
 	apiV1.GET("/videos/popular", videoHandler.GetPopularVideos)
-	apiV1.GET("/videos/:code", videoHandler.GetVideoByCode)
+	apiV1.GET("/videos/:code", videoHandler.GetVideoByCode, optionalAuthMiddleware)
+	apiV1.GET("/videos/:code/hls", videoHandler.GetVideoStreaming, optionalAuthMiddleware)
+	apiV1.GET("/videos/:code/hls/master.m3u8", videoHandler.GetHLSMasterPlaylist, optionalAuthMiddleware)
+	apiV1.GET("/videos/:code/hls/:quality/index.m3u8", videoHandler.GetHLSVariantPlaylist, optionalAuthMiddleware)
+	apiV1.GET("/videos/:code/dash/manifest.mpd", videoHandler.GetDASHManifest, optionalAuthMiddleware)
+	apiV1.GET("/videos/:code/subtitles", subtitleHandler.GetSubtitles, optionalAuthMiddleware)
+	apiV1.POST("/videos/:code/shares/unlock", shareHandler.UnlockShareLink)
 
 	// Категории
 	apiV1.GET("/categories", categoryHandler.GetCategories)
@@ -163,7 +227,17 @@ func main() {
 	apiV1.GET("/videos/:code/comments", commentHandler.GetVideoComments)
 
 	// Видео пользователя (чтение)
-	apiV1.GET("/api/users/:user_id/videos", videoHandler.GetUserVideos)
+	apiV1.GET("/api/users/:user_id/videos", videoHandler.GetUserVideos, optionalAuthMiddleware)
+
+	// Возможности сервера возобновляемой загрузки
+	apiV1.OPTIONS("/uploads", tusHandler.Options)
+
+	// Объекты локального хранилища, MinIO отдает и принимает их сам
+	if localStorage, ok := objectStorage.(*storage.LocalStorage); ok {
+		storageHandler := handlers.NewStorageHandler(localStorage)
+		apiV1.GET("/storage/:bucket/*", storageHandler.GetObject)
+		apiV1.POST("/storage/:bucket", storageHandler.PostObject)
+	}
 
 	// Защищенные маршруты (требуют аутентификации)
 	apiV1auth := apiV1
@@ -174,6 +248,24 @@ func main() {
 	apiV1auth.PUT("/videos/:code", videoHandler.UpdateVideo)
 	apiV1auth.DELETE("/videos/:code", videoHandler.DeleteVideo)
 
+	// Импорт видео по ссылке
+	apiV1auth.POST("/videos/import", importHandler.CreateImport)
+	apiV1auth.GET("/videos/:code/import", importHandler.GetImport)
+
+	// Возобновляемая загрузка видео (tus 1.0)
+	apiV1auth.POST("/uploads", tusHandler.CreateUpload, tusHandler.TusResumable)
+	apiV1auth.HEAD("/uploads/:id", tusHandler.GetUploadOffset, tusHandler.TusResumable)
+	apiV1auth.PATCH("/uploads/:id", tusHandler.WriteChunk, tusHandler.TusResumable)
+	apiV1auth.DELETE("/uploads/:id", tusHandler.TerminateUpload, tusHandler.TusResumable)
+
+	// Загрузка видео формой прямо в хранилище
+	apiV1auth.POST("/uploads/direct", uploadHandler.CreateDirectUpload)
+	apiV1auth.POST("/uploads/direct/:id/complete", uploadHandler.CompleteDirectUpload)
+
+	// Пакетная загрузка видео с манифестом
+	apiV1auth.POST("/uploads/batches", batchHandler.CreateBatch)
+	apiV1auth.GET("/uploads/batches/:id", batchHandler.GetBatch)
+
 	// Реакции на видео
 	apiV1auth.POST("/videos/:id/like", videoHandler.LikeVideo)
 	apiV1auth.POST("/videos/:id/dislike", videoHandler.DislikeVideo)
@@ -185,6 +277,32 @@ func main() {
 	// Получение информации для юзера о видео
 	apiV1auth.GET("/videos/user/:code", videoHandler.GetVideoUserByCode)
 
+	// Прогресс обработки видео
+	apiV1auth.GET("/videos/:code/processing", videoHandler.GetVideoProcessing)
+	apiV1auth.GET("/videos/:code/processing/stream", videoHandler.StreamVideoProcessing)
+
+	// Обложки видео
+	apiV1auth.GET("/videos/:code/thumbnails", thumbnailHandler.GetThumbnails)
+	apiV1auth.POST("/videos/:code/thumbnails", thumbnailHandler.UploadThumbnail)
+	apiV1auth.PUT("/videos/:code/thumbnails/:id/select", thumbnailHandler.SelectThumbnail)
+
+	// Субтитры видео
+	apiV1auth.POST("/videos/:code/subtitles", subtitleHandler.UploadSubtitle)
+	apiV1auth.DELETE("/videos/:code/subtitles/:language", subtitleHandler.DeleteSubtitle)
+
+	// Ссылки и выдача доступа к неопубликованным видео
+	apiV1auth.POST("/videos/:code/shares", shareHandler.CreateShareLink)
+	apiV1auth.GET("/videos/:code/shares", shareHandler.GetShareLinks)
+	apiV1auth.DELETE("/videos/:code/shares/:id", shareHandler.RevokeShareLink)
+	apiV1auth.POST("/videos/:code/grants", shareHandler.GrantAccess)
+	apiV1auth.GET("/videos/:code/grants", shareHandler.GetAccessGrants)
+	apiV1auth.DELETE("/videos/:code/grants/:user_id", shareHandler.RevokeAccess)
+
+	// Администрирование
+	adminV1 := apiV1auth.Group("/admin", apiMiddleware.RoleMiddleware(userRepo, constants.RoleAdmin))
+	adminV1.GET("/conversions/dead-letters", conversionHandler.GetDeadLetters)
+	adminV1.POST("/conversions/dead-letters/:id/redrive", conversionHandler.RedriveDeadLetter)
+
 	// Запускаем сервер с graceful shutdown
 	go func() {
 		if err := e.Start(fmt.Sprintf(":%d", cfg.Server.Port)); err != nil && err != http.ErrServerClosed {
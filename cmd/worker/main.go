@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"github.com/mrkbwp/gotube/internal/infrastructure/repositories"
+	"github.com/mrkbwp/gotube/internal/infrastructure/services"
+	"github.com/mrkbwp/gotube/internal/infrastructure/storage"
+	"github.com/mrkbwp/gotube/pkg/config"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"github.com/mrkbwp/gotube/pkg/kafka"
+	"github.com/mrkbwp/gotube/pkg/logger"
+	"github.com/mrkbwp/gotube/pkg/postgres"
+	"github.com/mrkbwp/gotube/pkg/redis"
+
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Воркер конвертации: читает сообщения о загруженных видео из Kafka
+// и масштабируется независимо от HTTP API
+func main() {
+	// Контекст, отменяемый по сигналу остановки
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Инициализируем логгер
+	log := logger.NewLogger(true)
+
+	// Загружаем конфигурацию из переменных окружения
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load config: %v", err)
+	}
+
+	// Инициализируем соединение с базой данных
+	db, err := postgres.NewPostgresDB(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to postgres: %v", err)
+	}
+	defer db.Close()
+
+	// Инициализируем Redis, через него публикуется прогресс конвертации
+	redisClient, err := redis.NewRedisClient(cfg.Redis)
+	if err != nil {
+		log.Fatal("Failed to connect to Redis: %v", err)
+	}
+	defer redisClient.Close()
+
+	// Инициализируем хранилище объектов (MinIO или локальный диск)
+	objectStorage, err := storage.NewObjectStorage(cfg)
+	if err != nil {
+		log.Fatal("Failed to create object storage: %v", err)
+	}
+
+	if err := objectStorage.EnsureBucketExists(ctx, constants.ThumbnailsBucket); err != nil {
+		log.Fatal("Failed to create thumbnails bucket: %v", err)
+	}
+
+	if err := os.MkdirAll(cfg.Conversion.TempDir, 0755); err != nil {
+		log.Fatal("Failed to create temp dir: %v", err)
+	}
+
+	// Инициализируем Kafka consumer
+	kafkaConsumer, err := kafka.NewConsumer(
+		cfg.Kafka.Brokers,
+		cfg.Kafka.ConsumerGroup,
+		cfg.Kafka.VideoProcessingTopic,
+	)
+	if err != nil {
+		log.Fatal("Failed to connect kafka: %v", err)
+	}
+	defer kafkaConsumer.Close()
+
+	// Dead letter топик для заданий конвертации, исчерпавших попытки
+	deadLetterProducer, err := kafka.NewProducer(
+		cfg.Kafka.Brokers,
+		cfg.Kafka.DeadLetterTopic,
+	)
+	if err != nil {
+		log.Fatal("Failed to connect kafka: %v", err)
+	}
+	defer deadLetterProducer.Close()
+
+	// Топик обработки: сборщик аренд возвращает в него задания упавших воркеров и повторы
+	processingProducer, err := kafka.NewProducer(
+		cfg.Kafka.Brokers,
+		cfg.Kafka.VideoProcessingTopic,
+	)
+	if err != nil {
+		log.Fatal("Failed to connect kafka: %v", err)
+	}
+	defer processingProducer.Close()
+
+	// Инициализируем репозитории
+	videoRepo := repositories.NewVideoRepository(db)
+	conversionJobRepo := repositories.NewConversionJobRepository(db)
+	videoThumbnailRepo := repositories.NewVideoThumbnailRepository(db)
+	videoSubtitleRepo := repositories.NewVideoSubtitleRepository(db)
+
+	// Конвертация
+	conversionService := services.NewConversionService(
+		videoRepo,
+		conversionJobRepo,
+		videoThumbnailRepo,
+		videoSubtitleRepo,
+		objectStorage,
+		redisClient,
+		cfg.Conversion.TempDir,
+		cfg.Conversion.RetryPolicies,
+		cfg.Upload.MaxDuration,
+		deadLetterProducer,
+		processingProducer,
+	)
+
+	// Задания воркер получает только через consumer, из базы их не выбирает.
+	// Сборщик аренд возвращает задания упавших воркеров и повторы обратно в топик
+	conversionService.StartLeaseReaper()
+	defer conversionService.StopLeaseReaper()
+
+	log.Info("Conversion worker started, consuming %s as %s", cfg.Kafka.VideoProcessingTopic, cfg.Kafka.ConsumerGroup)
+	err = kafkaConsumer.Consume(ctx, func(ctx context.Context, msg kafka.VideoProcessingMessage) error {
+		log.Info("Received processing message for video %s", msg.VideoID)
+		return conversionService.ProcessVideo(ctx, msg.VideoID)
+	})
+	if err != nil {
+		log.Error("Consumer stopped with error: %v", err)
+	}
+
+	log.Info("Conversion worker stopped gracefully")
+}
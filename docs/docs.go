@@ -192,6 +192,115 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/admin/conversions/dead-letters": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает задания конвертации, исчерпавшие попытки, с пагинацией (только для администраторов)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Список неудачных конвертаций",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество на странице",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/responses.PaginatedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/conversions/dead-letters/{id}/redrive": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сбрасывает попытки задания конвертации и возвращает его в очередь (только для администраторов)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Повтор неудачной конвертации",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID записи dead letter",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/responses.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/api/categories": {
             "get": {
                 "description": "Возвращает список всех доступных категорий",
@@ -382,34 +491,79 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users/{user_id}/videos": {
+        "/api/storage/{bucket}": {
+            "post": {
+                "description": "Принимает multipart форму из PresignedPostPolicy: поля key, Content-Type, policy, signature и последним файл в поле file",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "tags": [
+                    "storage"
+                ],
+                "summary": "Загрузка объекта формой",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Бакет",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/storage/{bucket}/{object}": {
             "get": {
-                "description": "Возвращает список видео конкретного пользователя с пагинацией",
+                "description": "Отдает файл локального хранилища с поддержкой Range. Объекты непубличных бакетов требуют подписи из ссылки",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "videos"
+                    "storage"
                 ],
-                "summary": "Список видео пользователя",
+                "summary": "Объект хранилища",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID пользователя",
-                        "name": "user_id",
+                        "description": "Бакет",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Путь к объекту",
+                        "name": "object",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Номер страницы",
-                        "name": "page",
+                        "description": "Срок действия ссылки (unix)",
+                        "name": "expires",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Количество на странице",
-                        "name": "limit",
+                        "type": "string",
+                        "description": "Подпись ссылки",
+                        "name": "signature",
                         "in": "query"
                     }
                 ],
@@ -417,11 +571,17 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/responses.PaginatedResponse"
+                            "type": "file"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
@@ -429,30 +589,41 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/videos": {
+        "/api/uploads": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Загружает новое видео в систему, название и описание берутся из имени файла",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+                "description": "Создает видео в статусе uploading и загрузку его исходника. Upload-Metadata: filename, category_id (обязательны), title, description",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "videos"
+                    "uploads"
                 ],
-                "summary": "Загрузка видео",
+                "summary": "Создание загрузки tus",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Видеофайл",
-                        "name": "video",
-                        "in": "formData",
+                        "type": "string",
+                        "description": "Версия tus (1.0.0)",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер файла в байтах",
+                        "name": "Upload-Length",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Метаданные tus: ключ и значение в base64 через пробел, пары через запятую",
+                        "name": "Upload-Metadata",
+                        "in": "header",
                         "required": true
                     }
                 ],
@@ -460,7 +631,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/entity.Video"
+                            "$ref": "#/definitions/entity.VideoUpload"
                         }
                     },
                     "400": {
@@ -469,6 +640,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -476,37 +665,76 @@ const docTemplate = `{
                         }
                     }
                 }
+            },
+            "options": {
+                "description": "Возвращает версию и расширения протокола tus",
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Возможности tus",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
             }
         },
-        "/api/videos/new": {
-            "get": {
-                "description": "Возвращает список новых видео с пагинацией",
+        "/api/uploads/batches": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Принимает multipart: первым полем manifest (JSON массив или CSV с колонками file, title, description,\ncategory_id, privacy, publish_at, tags), затем ZIP в поле archive или файлы в полях files. Видео загружаются в фоне,\nсостояние каждого файла возвращает GET /api/uploads/batches/{id}",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "videos"
+                    "uploads"
                 ],
-                "summary": "Список новых видео",
+                "summary": "Пакетная загрузка видео",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Номер страницы",
-                        "name": "page",
-                        "in": "query"
+                        "type": "file",
+                        "description": "Манифест JSON или CSV",
+                        "name": "manifest",
+                        "in": "formData",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Количество на странице",
-                        "name": "limit",
-                        "in": "query"
+                        "type": "file",
+                        "description": "ZIP с видео",
+                        "name": "archive",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Файлы видео",
+                        "name": "files",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/responses.PaginatedResponse"
+                            "$ref": "#/definitions/entity.UploadBatch"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
                     "500": {
@@ -518,35 +746,41 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/videos/popular": {
+        "/api/uploads/batches/{id}": {
             "get": {
-                "description": "Возвращает список популярных видео с пагинацией",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает пакет и состояние каждого файла: pending, processing, completed с кодом видео или failed с ошибкой",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "videos"
+                    "uploads"
                 ],
-                "summary": "Список популярных видео",
+                "summary": "Состояние пакетной загрузки",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Номер страницы",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Количество на странице",
-                        "name": "limit",
-                        "in": "query"
+                        "type": "string",
+                        "description": "ID пакета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/responses.PaginatedResponse"
+                            "$ref": "#/definitions/entity.UploadBatch"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
                     "500": {
@@ -558,34 +792,56 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/videos/{code}": {
-            "get": {
-                "description": "Возвращает информацию о видео по его коду",
+        "/api/uploads/direct": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает видео в статусе uploading и подписанную форму POST. Поля form_data отправляются multipart на url,\nфайл передается последним полем file. Хранилище примет только файл заявленных размера, типа и контрольной суммы",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "videos"
+                    "uploads"
                 ],
-                "summary": "Получение видео по коду",
+                "summary": "Прямая загрузка в хранилище",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Код видео",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
+                        "description": "Сведения о файле",
+                        "name": "upload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.CreateDirectUploadRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/entity.Video"
+                            "$ref": "#/definitions/dto.DirectUploadResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
@@ -597,47 +853,37 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/uploads/direct/{id}/complete": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Обновляет информацию о существующем видео",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Проверяет, что исходник загружен в хранилище и является видео, и отправляет видео на обработку",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "videos"
+                    "uploads"
                 ],
-                "summary": "Обновление видео",
+                "summary": "Завершение прямой загрузки",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID видео",
+                        "description": "ID загрузки",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Данные для обновления видео",
-                        "name": "video",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/requests.UpdateVideoRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Video"
+                            "$ref": "#/definitions/entity.VideoUpload"
                         }
                     },
                     "400": {
@@ -646,14 +892,32 @@ const docTemplate = `{
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "Locked",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
@@ -667,89 +931,88 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/videos/{code}/comments": {
-            "get": {
-                "description": "Возвращает список комментариев к видео с пагинацией",
-                "produces": [
-                    "application/json"
+        "/api/uploads/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Прерывает незавершенную загрузку и удаляет созданное для нее видео",
                 "tags": [
-                    "comments"
+                    "uploads"
                 ],
-                "summary": "Получение комментариев к видео",
+                "summary": "Прерывание загрузки tus",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID видео",
-                        "name": "code",
+                        "description": "ID загрузки",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Номер страницы",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Количество на странице",
-                        "name": "limit",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Версия tus (1.0.0)",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/responses.PaginatedResponse"
+                            "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "Locked",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/api/videos/{id}": {
-            "delete": {
+            },
+            "head": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Удаляет видео по ID",
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Возвращает в заголовках, сколько байт загрузки уже принято",
                 "tags": [
-                    "videos"
+                    "uploads"
                 ],
-                "summary": "Удаление видео",
+                "summary": "Состояние загрузки tus",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID видео",
+                        "description": "ID загрузки",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Версия tus (1.0.0)",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/responses.SuccessResponse"
-                        }
-                    },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/responses.ErrorResponse"
-                        }
+                        "description": "OK"
                     },
                     "404": {
                         "description": "Not Found",
@@ -757,57 +1020,54 @@ const docTemplate = `{
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "410": {
+                        "description": "Gone",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/api/videos/{id}/comments": {
-            "post": {
+            },
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Добавляет новый комментарий к видео",
+                "description": "Дописывает байты тела с позиции Upload-Offset. Последний кусок отправляет видео на обработку",
                 "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+                    "application/offset+octet-stream"
                 ],
                 "tags": [
-                    "comments"
+                    "uploads"
                 ],
-                "summary": "Добавление комментария",
+                "summary": "Кусок загрузки tus",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID видео",
+                        "description": "ID загрузки",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Данные комментария",
-                        "name": "comment",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/requests.CommentRequest"
-                        }
+                        "type": "string",
+                        "description": "Версия tus (1.0.0)",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение куска",
+                        "name": "Upload-Offset",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/entity.Comment"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -821,6 +1081,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Тип тела запроса или файл без сигнатуры видео",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -830,35 +1114,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/videos/{id}/dislike": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Добавляет дизлайк к видео от текущего пользователя",
+        "/api/users/{user_id}/videos": {
+            "get": {
+                "description": "Возвращает список видео пользователя с пагинацией. Владелец видит все свои видео, остальные - только опубликованные публичные",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "videos"
                 ],
-                "summary": "Дизлайк видео",
+                "summary": "Список видео пользователя",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID видео",
-                        "name": "id",
+                        "description": "ID пользователя",
+                        "name": "user_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество на странице",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/responses.SuccessResponse"
+                            "$ref": "#/definitions/responses.PaginatedResponse"
                         }
                     },
                     "500": {
@@ -870,26 +1161,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/videos/{id}/like": {
-            "post": {
+        "/api/v1/auth/videos/{code}": {
+            "get": {
                 "security": [
                     {
-                        "BearerAuth": []
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "Добавляет лайк к видео от текущего пользователя",
+                "description": "Возвращает видео по коду с информацией о лайках/дислайках текущего пользователя",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "videos"
+                    "auth-videos"
                 ],
-                "summary": "Лайк видео",
+                "summary": "Получение видео с реакциями пользователя",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID видео",
-                        "name": "id",
+                        "description": "Код видео",
+                        "name": "code",
                         "in": "path",
                         "required": true
                     }
@@ -898,195 +1189,2556 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/responses.SuccessResponse"
+                            "$ref": "#/definitions/dto.VideoUserResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
-                    }
-                }
-            }
-        }
-    },
-    "definitions": {
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Загружает новое видео в систему. Без названия используется имя файла, теги - повторяющимся полем или через запятую",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Загрузка видео",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Видеофайл",
+                        "name": "video",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название",
+                        "name": "title",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Описание",
+                        "name": "description",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID категории",
+                        "name": "category_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Видимость: public, unlisted, private или scheduled",
+                        "name": "privacy",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Время публикации в RFC 3339, обязательно для scheduled",
+                        "name": "publish_at",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Теги",
+                        "name": "tags",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Video"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает видео в статусе importing и скачивает исходник по HTTP(S) ссылке в фоне.\nСсылки на внутренние адреса не принимаются. После скачивания видео обрабатывается как обычная загрузка",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Импорт видео по ссылке",
+                "parameters": [
+                    {
+                        "description": "Ссылка на видео",
+                        "name": "import",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.CreateImportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/entity.VideoImport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/new": {
+            "get": {
+                "description": "Возвращает список новых видео с пагинацией",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Список новых видео",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество на странице",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/responses.PaginatedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/popular": {
+            "get": {
+                "description": "Возвращает список популярных видео с пагинацией",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Список популярных видео",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество на странице",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/responses.PaginatedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}": {
+            "get": {
+                "description": "Возвращает информацию о видео по его коду. Неопубликованные и приватные видео доступны только владельцу и администратору",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Получение видео по коду",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Video"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновляет информацию о существующем видео",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Обновление видео",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID видео",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные для обновления видео",
+                        "name": "video",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.UpdateVideoRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Video"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}/comments": {
+            "get": {
+                "description": "Возвращает список комментариев к видео с пагинацией",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Получение комментариев к видео",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество на странице",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/responses.PaginatedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}/dash/manifest.mpd": {
+            "get": {
+                "description": "Возвращает DASH манифест со всеми качествами и временными ссылками на сегменты",
+                "produces": [
+                    "application/dash+xml"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "DASH манифест",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}/grants": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает пользователей, которым выдан доступ к видео (только для владельца)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Выданные доступы к видео",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.VideoAccessGrant"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выдает пользователю доступ к неопубликованному видео, он смотрит его со своим токеном авторизации (только для владельца)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Выдача доступа к видео",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Пользователь",
+                        "name": "grant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.GrantAccessRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entity.VideoAccessGrant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}/grants/{user_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отзывает выданный пользователю доступ к видео (только для владельца)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Отзыв доступа к видео",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/responses.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}/hls": {
+            "get": {
+                "description": "Возвращает ссылку на мастер-плейлист HLS, плеер сам переключает качество. Токен ссылки доступа передается в ссылке",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Ссылка на адаптивный стрим",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StreamingResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}/hls/master.m3u8": {
+            "get": {
+                "description": "Возвращает мастер-плейлист HLS со списком доступных качеств, к их ссылкам дописывается токен ссылки доступа",
+                "produces": [
+                    "application/vnd.apple.mpegurl"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Мастер-плейлист HLS",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}/hls/{quality}/index.m3u8": {
+            "get": {
+                "description": "Возвращает плейлист качества с временными ссылками на сегменты",
+                "produces": [
+                    "application/vnd.apple.mpegurl"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Плейлист качества HLS",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Качество",
+                        "name": "quality",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}/import": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает состояние импорта видео по ссылке (только для владельца видео)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Состояние импорта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.VideoImport"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}/processing": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает статус видео и процент готовности каждого качества (только для владельца)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Прогресс обработки видео",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProcessingResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}/processing/stream": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events с прогрессом обработки, поток закрывается, когда все качества готовы или обработка завершилась ошибкой (только для владельца)",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Поток прогресса обработки видео",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProcessingResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}/shares": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает ссылки доступа к видео с токенами, в том числе отозванные и истекшие (только для владельца)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Ссылки доступа к видео",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.VideoShareLink"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает подписанную ссылку на неопубликованное видео (только для владельца). Токен передается параметром share\nили заголовком X-Share-Token, пароль - заголовком X-Share-Password. Просмотр списывается при открытии видео",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Создание ссылки доступа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Параметры ссылки",
+                        "name": "share",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.CreateShareLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entity.VideoShareLink"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}/shares/unlock": {
+            "post": {
+                "description": "Проверяет пароль ссылки (заголовок X-Share-Password) и возвращает короткоживущий токен зрителя.\nПлеер передает его параметром share или заголовком X-Share-Token вместо токена ссылки, пароль больше не нужен.\nЧисло неверных паролей с одного IP ограничено",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Ввод пароля ссылки доступа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен ссылки доступа",
+                        "name": "share",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ShareViewerToken"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}/shares/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отзывает ссылку доступа к видео, после этого ее токен не принимается (только для владельца)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Отзыв ссылки доступа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID ссылки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/responses.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}/subtitles": {
+            "get": {
+                "description": "Возвращает дорожки субтитров видео со ссылками на WebVTT",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Субтитры видео",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.VideoSubtitle"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Загружает субтитры SRT или WebVTT до 2 МБ для языка, SRT конвертируется в WebVTT. Дорожка того же языка заменяется (только для владельца)",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Загрузка субтитров",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Файл SRT или WebVTT",
+                        "name": "subtitle",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык в формате BCP 47, например en или pt-BR",
+                        "name": "language",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название дорожки для плеера",
+                        "name": "label",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entity.VideoSubtitle"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}/subtitles/{language}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет дорожку субтитров языка (только для владельца)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Удаление субтитров",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык в формате BCP 47",
+                        "name": "language",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/responses.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}/thumbnails": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает кадры-кандидаты и загруженные обложки видео (только для владельца)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Варианты обложки видео",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.VideoThumbnail"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Загружает изображение JPEG, PNG или GIF не меньше 640x360 и до 5 МБ, сохраняет его в стандартных размерах и делает обложкой видео (только для владельца)",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Загрузка обложки видео",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Изображение обложки",
+                        "name": "thumbnail",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entity.VideoThumbnail"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{code}/thumbnails/{id}/select": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Делает один из вариантов обложкой видео (только для владельца)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Выбор обложки видео",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код видео",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID варианта обложки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.VideoThumbnail"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет видео по ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Удаление видео",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID видео",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/responses.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{id}/comments": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет новый комментарий к видео",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Добавление комментария",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID видео",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные комментария",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.CommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Comment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{id}/dislike": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет дизлайк к видео от текущего пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Дизлайк видео",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID видео",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/responses.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/videos/{id}/like": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет лайк к видео от текущего пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Лайк видео",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID видео",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/responses.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.DirectUploadResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "form_data": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "upload": {
+                    "$ref": "#/definitions/entity.VideoUpload"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ProcessingResponse": {
+            "type": "object",
+            "properties": {
+                "error_message": {
+                    "type": "string"
+                },
+                "finished": {
+                    "type": "boolean"
+                },
+                "progress": {
+                    "type": "integer"
+                },
+                "qualities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.QualityProgress"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "video_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.QualityProgress": {
+            "type": "object",
+            "properties": {
+                "progress": {
+                    "type": "integer"
+                },
+                "quality": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ShareViewerToken": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.StreamingResponse": {
+            "type": "object",
+            "properties": {
+                "hls_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.VideoUserResponse": {
+            "type": "object",
+            "properties": {
+                "bucket_id": {
+                    "type": "string"
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "disliked": {
+                    "type": "boolean"
+                },
+                "dislikes": {
+                    "type": "integer"
+                },
+                "duration": {
+                    "type": "integer"
+                },
+                "error_message": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_blocked": {
+                    "type": "boolean"
+                },
+                "is_private": {
+                    "description": "IsPrivate выводится из Visibility при сохранении, см. IsPrivateVisibility",
+                    "type": "boolean"
+                },
+                "liked": {
+                    "type": "boolean"
+                },
+                "likes": {
+                    "type": "integer"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/entity.Metadata"
+                },
+                "original_checksum": {
+                    "type": "string"
+                },
+                "original_content_type": {
+                    "type": "string"
+                },
+                "original_filename": {
+                    "type": "string"
+                },
+                "original_size": {
+                    "description": "Сведения о загруженном исходнике: размер, MIME тип и SHA-256",
+                    "type": "integer"
+                },
+                "path_segment1": {
+                    "type": "string"
+                },
+                "path_segment2": {
+                    "type": "string"
+                },
+                "preview_clip_url": {
+                    "description": "PreviewClipURL - короткий ролик без звука для карточки видео",
+                    "type": "string"
+                },
+                "previews_vtt_url": {
+                    "description": "PreviewsVTTURL - WebVTT с превью кадров для полосы перемотки",
+                    "type": "string"
+                },
+                "processed_at": {
+                    "type": "string"
+                },
+                "publish_at": {
+                    "type": "string"
+                },
+                "shard_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subtitles": {
+                    "description": "Subtitles - дорожки субтитров, заполняются только для страницы видео",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.VideoSubtitle"
+                    }
+                },
+                "tags": {
+                    "description": "Tags - теги в нижнем регистре без повторов",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "thumbnail_url": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "video_code": {
+                    "type": "string"
+                },
+                "video_files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.VideoFile"
+                    }
+                },
+                "views": {
+                    "type": "integer"
+                },
+                "visibility": {
+                    "type": "string"
+                }
+            }
+        },
         "entity.Category": {
             "type": "object",
             "properties": {
-                "description": {
+                "description": {
+                    "type": "string"
+                },
+                "icon": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Comment": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "description": "Для построения дерева комментариев",
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "video_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Metadata": {
+            "type": "object",
+            "additionalProperties": true
+        },
+        "entity.UploadBatch": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.UploadBatchItem"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total_items": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.UploadBatchItem": {
+            "type": "object",
+            "properties": {
+                "batch_id": {
+                    "type": "string"
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "privacy": {
+                    "type": "string"
+                },
+                "publish_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "video_code": {
+                    "description": "VideoCode - код созданного видео, заполняется при чтении пакета",
+                    "type": "string"
+                },
+                "video_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Video": {
+            "type": "object",
+            "properties": {
+                "bucket_id": {
+                    "type": "string"
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "dislikes": {
+                    "type": "integer"
+                },
+                "duration": {
+                    "type": "integer"
+                },
+                "error_message": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_blocked": {
+                    "type": "boolean"
+                },
+                "is_private": {
+                    "description": "IsPrivate выводится из Visibility при сохранении, см. IsPrivateVisibility",
+                    "type": "boolean"
+                },
+                "likes": {
+                    "type": "integer"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/entity.Metadata"
+                },
+                "original_checksum": {
+                    "type": "string"
+                },
+                "original_content_type": {
+                    "type": "string"
+                },
+                "original_filename": {
+                    "type": "string"
+                },
+                "original_size": {
+                    "description": "Сведения о загруженном исходнике: размер, MIME тип и SHA-256",
+                    "type": "integer"
+                },
+                "path_segment1": {
+                    "type": "string"
+                },
+                "path_segment2": {
+                    "type": "string"
+                },
+                "preview_clip_url": {
+                    "description": "PreviewClipURL - короткий ролик без звука для карточки видео",
+                    "type": "string"
+                },
+                "previews_vtt_url": {
+                    "description": "PreviewsVTTURL - WebVTT с превью кадров для полосы перемотки",
+                    "type": "string"
+                },
+                "processed_at": {
+                    "type": "string"
+                },
+                "publish_at": {
+                    "type": "string"
+                },
+                "shard_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subtitles": {
+                    "description": "Subtitles - дорожки субтитров, заполняются только для страницы видео",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.VideoSubtitle"
+                    }
+                },
+                "tags": {
+                    "description": "Tags - теги в нижнем регистре без повторов",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "thumbnail_url": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "video_code": {
+                    "type": "string"
+                },
+                "video_files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.VideoFile"
+                    }
+                },
+                "views": {
+                    "type": "integer"
+                },
+                "visibility": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.VideoAccessGrant": {
+            "type": "object",
+            "properties": {
+                "created_at": {
                     "type": "string"
                 },
-                "icon": {
+                "granted_by": {
                     "type": "string"
                 },
-                "id": {
+                "user_id": {
                     "type": "string"
                 },
-                "name": {
+                "video_id": {
                     "type": "string"
                 }
             }
         },
-        "entity.Comment": {
+        "entity.VideoFile": {
             "type": "object",
             "properties": {
+                "bitrate": {
+                    "type": "integer"
+                },
+                "checksum": {
+                    "type": "string"
+                },
+                "codec": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "file_size": {
+                    "type": "integer"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "string"
                 },
-                "parent_id": {
-                    "description": "Для построения дерева комментариев",
+                "language": {
+                    "description": "Language - язык звуковой дорожки, пустой если не указан",
                     "type": "string"
                 },
-                "text": {
+                "profile_id": {
+                    "type": "string"
+                },
+                "quality": {
+                    "type": "string"
+                },
+                "quality_id": {
                     "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
                 },
-                "user_id": {
+                "url": {
                     "type": "string"
                 },
                 "video_id": {
                     "type": "string"
+                },
+                "width": {
+                    "type": "integer"
                 }
             }
         },
-        "entity.Metadata": {
-            "type": "object",
-            "additionalProperties": true
-        },
-        "entity.Video": {
+        "entity.VideoImport": {
             "type": "object",
             "properties": {
-                "bucket_id": {
-                    "type": "string"
+                "attempts": {
+                    "type": "integer"
                 },
-                "category_id": {
+                "completed_at": {
                     "type": "string"
                 },
                 "created_at": {
                     "type": "string"
                 },
-                "deleted_at": {
+                "error": {
                     "type": "string"
                 },
-                "description": {
+                "id": {
                     "type": "string"
                 },
-                "dislikes": {
+                "size": {
                     "type": "integer"
                 },
-                "duration": {
-                    "type": "integer"
+                "source_url": {
+                    "type": "string"
                 },
-                "error_message": {
+                "status": {
                     "type": "string"
                 },
-                "filename": {
+                "updated_at": {
                     "type": "string"
                 },
-                "id": {
+                "user_id": {
                     "type": "string"
                 },
-                "is_blocked": {
-                    "type": "boolean"
+                "video_code": {
+                    "description": "VideoCode - код созданного видео, заполняется при создании импорта",
+                    "type": "string"
                 },
-                "is_private": {
+                "video_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.VideoShareLink": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "has_password": {
                     "type": "boolean"
                 },
-                "likes": {
-                    "type": "integer"
+                "id": {
+                    "type": "string"
                 },
-                "metadata": {
-                    "$ref": "#/definitions/entity.Metadata"
+                "max_views": {
+                    "type": "integer"
                 },
-                "original_filename": {
+                "revoked_at": {
                     "type": "string"
                 },
-                "path_segment1": {
+                "token": {
+                    "description": "Token и HasPassword заполняются сервисом",
                     "type": "string"
                 },
-                "path_segment2": {
+                "video_id": {
                     "type": "string"
                 },
-                "processed_at": {
+                "views": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.VideoSubtitle": {
+            "type": "object",
+            "properties": {
+                "created_at": {
                     "type": "string"
                 },
-                "shard_id": {
+                "cues_count": {
+                    "type": "integer"
+                },
+                "id": {
                     "type": "string"
                 },
-                "status": {
+                "label": {
                     "type": "string"
                 },
-                "thumbnail_url": {
+                "language": {
+                    "description": "Language - язык в формате BCP 47",
                     "type": "string"
                 },
-                "title": {
+                "source": {
+                    "description": "Source - upload или embedded",
                     "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
                 },
-                "user_id": {
+                "url": {
                     "type": "string"
                 },
-                "video_code": {
+                "video_id": {
                     "type": "string"
-                },
-                "video_files": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/entity.VideoFile"
-                    }
-                },
-                "views": {
-                    "type": "integer"
                 }
             }
         },
-        "entity.VideoFile": {
+        "entity.VideoThumbnail": {
             "type": "object",
             "properties": {
-                "bitrate": {
-                    "type": "integer"
-                },
                 "created_at": {
                     "type": "string"
                 },
-                "file_size": {
-                    "type": "integer"
+                "id": {
+                    "type": "string"
                 },
-                "format": {
+                "is_selected": {
+                    "type": "boolean"
+                },
+                "position": {
+                    "description": "Position - секунда кадра в видео, для загруженных обложек пустая",
+                    "type": "number"
+                },
+                "sizes": {
+                    "$ref": "#/definitions/entity.Metadata"
+                },
+                "source": {
+                    "description": "Source - frame, scene или custom",
                     "type": "string"
                 },
-                "height": {
-                    "type": "integer"
+                "url": {
+                    "type": "string"
+                },
+                "video_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.VideoUpload": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "quality": {
+                "kind": {
                     "type": "string"
                 },
-                "quality_id": {
+                "metadata": {
+                    "$ref": "#/definitions/entity.Metadata"
+                },
+                "status": {
                     "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
                 },
-                "url": {
+                "upload_length": {
+                    "type": "integer"
+                },
+                "upload_offset": {
+                    "type": "integer"
+                },
+                "user_id": {
                     "type": "string"
                 },
-                "video_id": {
+                "video_code": {
+                    "description": "VideoCode - код созданного видео, заполняется при создании загрузки",
                     "type": "string"
                 },
-                "width": {
-                    "type": "integer"
+                "video_id": {
+                    "type": "string"
                 }
             }
         },
@@ -1101,6 +3753,94 @@ const docTemplate = `{
                 }
             }
         },
+        "requests.CreateDirectUploadRequest": {
+            "type": "object",
+            "required": [
+                "category_id",
+                "content_type",
+                "filename",
+                "size"
+            ],
+            "properties": {
+                "category_id": {
+                    "type": "string"
+                },
+                "checksum": {
+                    "description": "Checksum - SHA-256 файла в hex, хранилище отклонит файл с другой суммой",
+                    "type": "string"
+                },
+                "content_type": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3
+                }
+            }
+        },
+        "requests.CreateImportRequest": {
+            "type": "object",
+            "required": [
+                "category_id",
+                "url"
+            ],
+            "properties": {
+                "category_id": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048
+                }
+            }
+        },
+        "requests.CreateShareLinkRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "max_views": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 4
+                }
+            }
+        },
+        "requests.GrantAccessRequest": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
         "requests.UpdateVideoRequest": {
             "type": "object",
             "required": [
@@ -1114,6 +3854,18 @@ const docTemplate = `{
                 "description": {
                     "type": "string"
                 },
+                "privacy": {
+                    "type": "string",
+                    "enum": [
+                        "public",
+                        "unlisted",
+                        "private",
+                        "scheduled"
+                    ]
+                },
+                "publish_at": {
+                    "type": "string"
+                },
                 "title": {
                     "type": "string",
                     "maxLength": 100,
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mrkbwp/gotube/internal/api/requests"
+	"github.com/mrkbwp/gotube/internal/api/responses"
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/services"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"github.com/mrkbwp/gotube/pkg/validator"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// batchManifestField - поле multipart с манифестом, оно идет первым
+const batchManifestField = "manifest"
+
+// BatchHandler обработчик пакетной загрузки видео
+type BatchHandler struct {
+	batchService    services.BatchService
+	categoryService services.CategoryService
+	validator       *validator.Validator
+}
+
+// NewBatchHandler создает новый BatchHandler
+func NewBatchHandler(batchService services.BatchService, categoryService services.CategoryService, validator *validator.Validator) *BatchHandler {
+	return &BatchHandler{
+		batchService:    batchService,
+		categoryService: categoryService,
+		validator:       validator,
+	}
+}
+
+// CreateBatch принимает пакет видео с манифестом
+// @Summary Пакетная загрузка видео
+// @Description Принимает multipart: первым полем manifest (JSON массив или CSV с колонками file, title, description,
+// @Description category_id, privacy, publish_at, tags), затем ZIP в поле archive или файлы в полях files. Видео загружаются в фоне,
+// @Description состояние каждого файла возвращает GET /api/uploads/batches/{id}
+// @Tags uploads
+// @Accept multipart/form-data
+// @Produce json
+// @Param manifest formData file true "Манифест JSON или CSV"
+// @Param archive formData file false "ZIP с видео"
+// @Param files formData file false "Файлы видео"
+// @Security BearerAuth
+// @Success 202 {object} entity.UploadBatch
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 413 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/uploads/batches [post]
+func (h *BatchHandler) CreateBatch(c echo.Context) error {
+	userID := c.Get("userID").(uuid.UUID)
+	ctx := c.Request().Context()
+
+	reader, err := c.Request().MultipartReader()
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Multipart form is required")
+	}
+
+	part, err := reader.NextPart()
+	if err != nil || part.FormName() != batchManifestField {
+		return responses.Error(c, http.StatusBadRequest, "Manifest must be the first field")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(part, constants.MaxBatchManifestSize+1))
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Failed to read manifest")
+	}
+	if len(data) > constants.MaxBatchManifestSize {
+		return responses.Error(c, http.StatusRequestEntityTooLarge, "Manifest is too large")
+	}
+
+	manifest, err := parseBatchManifest(data, part.FileName(), part.Header.Get("Content-Type"))
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, err.Error())
+	}
+	if len(manifest) == 0 || len(manifest) > constants.MaxBatchItems {
+		return responses.Error(c, http.StatusBadRequest, fmt.Sprintf("Manifest must list from 1 to %d files", constants.MaxBatchItems))
+	}
+
+	items := make([]*entity.UploadBatchItem, 0, len(manifest))
+	categories := make(map[uuid.UUID]bool)
+	for i, entry := range manifest {
+		if err := h.validator.Validate(entry); err != nil {
+			return responses.Error(c, http.StatusBadRequest, fmt.Sprintf("manifest item %d: %v", i+1, err))
+		}
+
+		categoryID := uuid.MustParse(entry.CategoryID)
+		if !categories[categoryID] {
+			if _, err := h.categoryService.GetCategoryByID(ctx, categoryID); err != nil {
+				if errors.Is(err, constants.ErrNotFound) {
+					return responses.Error(c, http.StatusBadRequest, fmt.Sprintf("manifest item %d: category not found", i+1))
+				}
+				return responses.Error(c, http.StatusInternalServerError, "Failed to get category")
+			}
+			categories[categoryID] = true
+		}
+
+		publishAt, err := parsePublishAt(entry.Privacy, entry.PublishAt)
+		if err != nil {
+			return responses.Error(c, http.StatusBadRequest, fmt.Sprintf("manifest item %d: %v", i+1, err))
+		}
+
+		items = append(items, newBatchItem(entry, categoryID, publishAt))
+	}
+
+	batch, err := h.batchService.CreateBatch(ctx, userID, items, reader)
+	if err != nil {
+		if status := uploadLimitStatus(err); status != 0 {
+			return uploadLimitError(c, status, err)
+		}
+		if errors.Is(err, constants.ErrInvalidBatch) {
+			return responses.Error(c, http.StatusBadRequest, err.Error())
+		}
+		return responses.Error(c, http.StatusInternalServerError, "Failed to create batch")
+	}
+
+	return responses.JSON(c, http.StatusAccepted, batch)
+}
+
+// GetBatch возвращает состояние пакетной загрузки
+// @Summary Состояние пакетной загрузки
+// @Description Возвращает пакет и состояние каждого файла: pending, processing, completed с кодом видео или failed с ошибкой
+// @Tags uploads
+// @Produce json
+// @Param id path string true "ID пакета"
+// @Security BearerAuth
+// @Success 200 {object} entity.UploadBatch
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/uploads/batches/{id} [get]
+func (h *BatchHandler) GetBatch(c echo.Context) error {
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return responses.Error(c, http.StatusNotFound, "Batch not found")
+	}
+
+	batch, err := h.batchService.GetBatch(c.Request().Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, constants.ErrBatchNotFound) {
+			return responses.Error(c, http.StatusNotFound, "Batch not found")
+		}
+		return responses.Error(c, http.StatusInternalServerError, "Failed to get batch")
+	}
+
+	return responses.JSON(c, http.StatusOK, batch)
+}
+
+// newBatchItem создает элемент пакета, название по умолчанию - имя файла без расширения
+func newBatchItem(entry requests.BatchManifestItem, categoryID uuid.UUID, publishAt *time.Time) *entity.UploadBatchItem {
+	title := entry.Title
+	if title == "" {
+		title = titleFromFilename(path.Base(entry.File))
+	}
+
+	privacy := entry.Privacy
+	if privacy == "" {
+		privacy = constants.VideoVisibilityPublic
+	}
+
+	tags := entry.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return &entity.UploadBatchItem{
+		Filename:    entry.File,
+		Title:       title,
+		Description: entry.Description,
+		CategoryID:  categoryID,
+		Privacy:     privacy,
+		PublishAt:   publishAt,
+		Tags:        tags,
+	}
+}
+
+// parseBatchManifest разбирает манифест JSON или CSV. Формат определяется по имени файла,
+// типу содержимого или первому символу
+func parseBatchManifest(data []byte, filename, contentType string) ([]requests.BatchManifestItem, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+
+	isJSON := strings.EqualFold(path.Ext(filename), ".json") || mediaType == "application/json"
+	isCSV := strings.EqualFold(path.Ext(filename), ".csv") || mediaType == "text/csv"
+	if !isJSON && !isCSV && len(trimmed) > 0 {
+		isJSON = trimmed[0] == '[' || trimmed[0] == '{'
+	}
+
+	if isJSON {
+		return parseJSONManifest(trimmed)
+	}
+	return parseCSVManifest(trimmed)
+}
+
+// parseJSONManifest принимает массив элементов или объект с полем items
+func parseJSONManifest(data []byte) ([]requests.BatchManifestItem, error) {
+	var items []requests.BatchManifestItem
+
+	if len(data) > 0 && data[0] == '{' {
+		var manifest struct {
+			Items []requests.BatchManifestItem `json:"items"`
+		}
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("invalid JSON manifest: %v", err)
+		}
+		return manifest.Items, nil
+	}
+
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("invalid JSON manifest: %v", err)
+	}
+
+	return items, nil
+}
+
+// parseCSVManifest принимает CSV с заголовком, колонки file и category_id обязательны
+func parseCSVManifest(data []byte) ([]requests.BatchManifestItem, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV manifest: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("invalid CSV manifest: header is required")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "file", "title", "description", "category_id", "privacy", "publish_at", "tags":
+			columns[name] = i
+		default:
+			return nil, fmt.Errorf("invalid CSV manifest: unknown column %q", name)
+		}
+	}
+	for _, name := range []string{"file", "category_id"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("invalid CSV manifest: column %s is required", name)
+		}
+	}
+
+	items := make([]requests.BatchManifestItem, 0, len(records)-1)
+	for _, record := range records[1:] {
+		value := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		var tags []string
+		for _, tag := range strings.Split(value("tags"), ";") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+
+		items = append(items, requests.BatchManifestItem{
+			File:        value("file"),
+			Title:       value("title"),
+			Description: value("description"),
+			CategoryID:  value("category_id"),
+			Privacy:     strings.ToLower(value("privacy")),
+			PublishAt:   value("publish_at"),
+			Tags:        tags,
+		})
+	}
+
+	return items, nil
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mrkbwp/gotube/internal/api/responses"
+	"github.com/mrkbwp/gotube/internal/domain/services"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"github.com/mrkbwp/gotube/pkg/pagination"
+	"net/http"
+)
+
+// ConversionHandler обработчик для администрирования конвертации
+type ConversionHandler struct {
+	conversionService services.ConversionService
+}
+
+// NewConversionHandler создает новый ConversionHandler
+func NewConversionHandler(conversionService services.ConversionService) *ConversionHandler {
+	return &ConversionHandler{
+		conversionService: conversionService,
+	}
+}
+
+// GetDeadLetters возвращает задания конвертации, исчерпавшие попытки
+// @Summary Список неудачных конвертаций
+// @Description Возвращает задания конвертации, исчерпавшие попытки, с пагинацией (только для администраторов)
+// @Tags admin
+// @Produce json
+// @Param page query int false "Номер страницы"
+// @Param limit query int false "Количество на странице"
+// @Security BearerAuth
+// @Success 200 {object} responses.PaginatedResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/admin/conversions/dead-letters [get]
+func (h *ConversionHandler) GetDeadLetters(c echo.Context) error {
+	paginationParams := pagination.ExtractPaginationParams(c)
+	ctx := c.Request().Context()
+
+	deadLetters, total, err := h.conversionService.GetDeadLetters(ctx, paginationParams.Page, paginationParams.Limit)
+	if err != nil {
+		return responses.Error(c, http.StatusInternalServerError, "Failed to get dead letters")
+	}
+
+	return responses.JSON(c, http.StatusOK, responses.PaginatedResponse{
+		Data:  deadLetters,
+		Page:  paginationParams.Page,
+		Limit: paginationParams.Limit,
+		Total: total,
+	})
+}
+
+// RedriveDeadLetter возвращает неудачную конвертацию в очередь
+// @Summary Повтор неудачной конвертации
+// @Description Сбрасывает попытки задания конвертации и возвращает его в очередь (только для администраторов)
+// @Tags admin
+// @Produce json
+// @Param id path string true "ID записи dead letter"
+// @Security BearerAuth
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/admin/conversions/dead-letters/{id}/redrive [post]
+func (h *ConversionHandler) RedriveDeadLetter(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid dead letter ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.conversionService.RedriveDeadLetter(ctx, id); err != nil {
+		if errors.Is(err, constants.ErrDeadLetterNotFound) {
+			return responses.Error(c, http.StatusNotFound, "Dead letter not found")
+		}
+		return responses.Error(c, http.StatusInternalServerError, "Failed to redrive dead letter")
+	}
+
+	return responses.Success(c, "Conversion requeued successfully")
+}
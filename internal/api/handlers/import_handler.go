@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mrkbwp/gotube/internal/api/requests"
+	"github.com/mrkbwp/gotube/internal/api/responses"
+	"github.com/mrkbwp/gotube/internal/domain/services"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"github.com/mrkbwp/gotube/pkg/validator"
+	"net/http"
+)
+
+// ImportHandler обработчик импорта видео по ссылке
+type ImportHandler struct {
+	videoService  services.VideoService
+	importService services.ImportService
+	validator     *validator.Validator
+}
+
+// NewImportHandler создает новый ImportHandler
+func NewImportHandler(videoService services.VideoService, importService services.ImportService, validator *validator.Validator) *ImportHandler {
+	return &ImportHandler{
+		videoService:  videoService,
+		importService: importService,
+		validator:     validator,
+	}
+}
+
+// CreateImport ставит импорт видео по ссылке в очередь
+// @Summary Импорт видео по ссылке
+// @Description Создает видео в статусе importing и скачивает исходник по HTTP(S) ссылке в фоне.
+// @Description Ссылки на внутренние адреса не принимаются. После скачивания видео обрабатывается как обычная загрузка
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Param import body requests.CreateImportRequest true "Ссылка на видео"
+// @Security BearerAuth
+// @Success 202 {object} entity.VideoImport
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 413 {object} responses.ErrorResponse
+// @Failure 429 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/videos/import [post]
+func (h *ImportHandler) CreateImport(c echo.Context) error {
+	userID := c.Get("userID").(uuid.UUID)
+
+	var req requests.CreateImportRequest
+	if err := c.Bind(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request data")
+	}
+	if err := h.validator.Validate(req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, err.Error())
+	}
+
+	categoryID, err := uuid.Parse(req.CategoryID)
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid category ID")
+	}
+
+	videoImport, err := h.importService.CreateImport(c.Request().Context(), userID, categoryID, req.URL, req.Title, req.Description)
+	if err != nil {
+		if status := uploadLimitStatus(err); status != 0 {
+			return uploadLimitError(c, status, err)
+		}
+		if errors.Is(err, constants.ErrInvalidImportURL) {
+			return responses.Error(c, http.StatusBadRequest, err.Error())
+		}
+		return responses.Error(c, http.StatusInternalServerError, "Failed to create import")
+	}
+
+	return responses.JSON(c, http.StatusAccepted, videoImport)
+}
+
+// GetImport возвращает состояние импорта видео
+// @Summary Состояние импорта
+// @Description Возвращает состояние импорта видео по ссылке (только для владельца видео)
+// @Tags videos
+// @Produce json
+// @Param code path string true "Код видео"
+// @Security BearerAuth
+// @Success 200 {object} entity.VideoImport
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/import [get]
+func (h *ImportHandler) GetImport(c echo.Context) error {
+	userID := c.Get("userID").(uuid.UUID)
+	ctx := c.Request().Context()
+
+	video, err := h.videoService.GetVideoByCode(ctx, c.Param("code"))
+	if err != nil {
+		return responses.Error(c, http.StatusNotFound, "Video not found")
+	}
+
+	if video.UserID != userID {
+		return responses.Error(c, http.StatusForbidden, "You don't have permission to view this import")
+	}
+
+	videoImport, err := h.importService.GetImport(ctx, video)
+	if err != nil {
+		if errors.Is(err, constants.ErrImportNotFound) {
+			return responses.Error(c, http.StatusNotFound, "Import not found")
+		}
+		return responses.Error(c, http.StatusInternalServerError, "Failed to get import")
+	}
+
+	return responses.JSON(c, http.StatusOK, videoImport)
+}
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mrkbwp/gotube/internal/api/requests"
+	"github.com/mrkbwp/gotube/internal/api/responses"
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/services"
+	"github.com/mrkbwp/gotube/internal/dto"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"github.com/mrkbwp/gotube/pkg/validator"
+	"net/http"
+	"time"
+)
+
+// errNotVideoOwner - видео принадлежит другому пользователю
+var errNotVideoOwner = errors.New("not the video owner")
+
+// ShareHandler обработчик ссылок доступа и выдачи доступа к неопубликованным видео
+type ShareHandler struct {
+	videoService services.VideoService
+	shareService services.ShareService
+	validator    *validator.Validator
+}
+
+// NewShareHandler создает новый ShareHandler
+func NewShareHandler(videoService services.VideoService, shareService services.ShareService, validator *validator.Validator) *ShareHandler {
+	return &ShareHandler{
+		videoService: videoService,
+		shareService: shareService,
+		validator:    validator,
+	}
+}
+
+// CreateShareLink создает ссылку доступа к видео
+// @Summary Создание ссылки доступа
+// @Description Создает подписанную ссылку на неопубликованное видео (только для владельца). Токен передается параметром share
+// @Description или заголовком X-Share-Token, пароль - заголовком X-Share-Password. Просмотр списывается при открытии видео
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Param code path string true "Код видео"
+// @Param share body requests.CreateShareLinkRequest true "Параметры ссылки"
+// @Security BearerAuth
+// @Success 201 {object} entity.VideoShareLink
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/shares [post]
+func (h *ShareHandler) CreateShareLink(c echo.Context) error {
+	userID := c.Get("userID").(uuid.UUID)
+	ctx := c.Request().Context()
+
+	var req requests.CreateShareLinkRequest
+	if err := c.Bind(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request data")
+	}
+	if err := h.validator.Validate(req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, err.Error())
+	}
+
+	options := dto.ShareLinkOptions{
+		Password: req.Password,
+		MaxViews: req.MaxViews,
+	}
+	if req.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			return responses.Error(c, http.StatusBadRequest, "expires_at must be a time in RFC 3339 format")
+		}
+		options.ExpiresAt = expiresAt
+	}
+
+	video, err := h.getOwnVideo(c)
+	if err != nil {
+		return shareError(c, err)
+	}
+
+	link, err := h.shareService.CreateShareLink(ctx, video, userID, options)
+	if err != nil {
+		return shareError(c, err)
+	}
+
+	return responses.JSON(c, http.StatusCreated, link)
+}
+
+// GetShareLinks возвращает ссылки доступа к видео
+// @Summary Ссылки доступа к видео
+// @Description Возвращает ссылки доступа к видео с токенами, в том числе отозванные и истекшие (только для владельца)
+// @Tags videos
+// @Produce json
+// @Param code path string true "Код видео"
+// @Security BearerAuth
+// @Success 200 {array} entity.VideoShareLink
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/shares [get]
+func (h *ShareHandler) GetShareLinks(c echo.Context) error {
+	video, err := h.getOwnVideo(c)
+	if err != nil {
+		return shareError(c, err)
+	}
+
+	links, err := h.shareService.GetShareLinks(c.Request().Context(), video)
+	if err != nil {
+		return shareError(c, err)
+	}
+
+	return responses.JSON(c, http.StatusOK, links)
+}
+
+// RevokeShareLink отзывает ссылку доступа к видео
+// @Summary Отзыв ссылки доступа
+// @Description Отзывает ссылку доступа к видео, после этого ее токен не принимается (только для владельца)
+// @Tags videos
+// @Produce json
+// @Param code path string true "Код видео"
+// @Param id path string true "ID ссылки"
+// @Security BearerAuth
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/shares/{id} [delete]
+func (h *ShareHandler) RevokeShareLink(c echo.Context) error {
+	linkID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return shareError(c, constants.ErrShareLinkNotFound)
+	}
+
+	video, err := h.getOwnVideo(c)
+	if err != nil {
+		return shareError(c, err)
+	}
+
+	if err := h.shareService.RevokeShareLink(c.Request().Context(), video, linkID); err != nil {
+		return shareError(c, err)
+	}
+
+	return responses.Success(c, "Share link revoked successfully")
+}
+
+// UnlockShareLink обменивает пароль ссылки на токен зрителя
+// @Summary Ввод пароля ссылки доступа
+// @Description Проверяет пароль ссылки (заголовок X-Share-Password) и возвращает короткоживущий токен зрителя.
+// @Description Плеер передает его параметром share или заголовком X-Share-Token вместо токена ссылки, пароль больше не нужен.
+// @Description Число неверных паролей с одного IP ограничено
+// @Tags videos
+// @Produce json
+// @Param code path string true "Код видео"
+// @Param share query string false "Токен ссылки доступа"
+// @Success 200 {object} dto.ShareViewerToken
+// @Failure 401 {object} responses.ErrorResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 410 {object} responses.ErrorResponse
+// @Failure 429 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/shares/unlock [post]
+func (h *ShareHandler) UnlockShareLink(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	video, err := h.videoService.GetVideoByCode(ctx, c.Param("code"))
+	if err != nil {
+		return shareError(c, constants.ErrVideoNotFound)
+	}
+
+	token, err := h.shareService.UnlockShareLink(ctx, video, videoViewer(c))
+	if err != nil {
+		return videoAccessError(c, err)
+	}
+
+	return responses.JSON(c, http.StatusOK, token)
+}
+
+// GrantAccess выдает пользователю доступ к видео
+// @Summary Выдача доступа к видео
+// @Description Выдает пользователю доступ к неопубликованному видео, он смотрит его со своим токеном авторизации (только для владельца)
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Param code path string true "Код видео"
+// @Param grant body requests.GrantAccessRequest true "Пользователь"
+// @Security BearerAuth
+// @Success 201 {object} entity.VideoAccessGrant
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/grants [post]
+func (h *ShareHandler) GrantAccess(c echo.Context) error {
+	userID := c.Get("userID").(uuid.UUID)
+
+	var req requests.GrantAccessRequest
+	if err := c.Bind(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request data")
+	}
+	if err := h.validator.Validate(req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, err.Error())
+	}
+
+	video, err := h.getOwnVideo(c)
+	if err != nil {
+		return shareError(c, err)
+	}
+
+	grant, err := h.shareService.GrantAccess(c.Request().Context(), video, uuid.MustParse(req.UserID), userID)
+	if err != nil {
+		return shareError(c, err)
+	}
+
+	return responses.JSON(c, http.StatusCreated, grant)
+}
+
+// GetAccessGrants возвращает пользователей с доступом к видео
+// @Summary Выданные доступы к видео
+// @Description Возвращает пользователей, которым выдан доступ к видео (только для владельца)
+// @Tags videos
+// @Produce json
+// @Param code path string true "Код видео"
+// @Security BearerAuth
+// @Success 200 {array} entity.VideoAccessGrant
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/grants [get]
+func (h *ShareHandler) GetAccessGrants(c echo.Context) error {
+	video, err := h.getOwnVideo(c)
+	if err != nil {
+		return shareError(c, err)
+	}
+
+	grants, err := h.shareService.GetAccessGrants(c.Request().Context(), video)
+	if err != nil {
+		return shareError(c, err)
+	}
+
+	return responses.JSON(c, http.StatusOK, grants)
+}
+
+// RevokeAccess отзывает доступ пользователя к видео
+// @Summary Отзыв доступа к видео
+// @Description Отзывает выданный пользователю доступ к видео (только для владельца)
+// @Tags videos
+// @Produce json
+// @Param code path string true "Код видео"
+// @Param user_id path string true "ID пользователя"
+// @Security BearerAuth
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/grants/{user_id} [delete]
+func (h *ShareHandler) RevokeAccess(c echo.Context) error {
+	grantUserID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		return shareError(c, constants.ErrAccessGrantNotFound)
+	}
+
+	video, err := h.getOwnVideo(c)
+	if err != nil {
+		return shareError(c, err)
+	}
+
+	if err := h.shareService.RevokeAccess(c.Request().Context(), video, grantUserID); err != nil {
+		return shareError(c, err)
+	}
+
+	return responses.Success(c, "Access revoked successfully")
+}
+
+// getOwnVideo возвращает видео из параметра code, если его владелец - текущий пользователь
+func (h *ShareHandler) getOwnVideo(c echo.Context) (*entity.Video, error) {
+	userID := c.Get("userID").(uuid.UUID)
+
+	video, err := h.videoService.GetVideoByCode(c.Request().Context(), c.Param("code"))
+	if err != nil {
+		return nil, constants.ErrVideoNotFound
+	}
+
+	if video.UserID != userID {
+		return nil, errNotVideoOwner
+	}
+
+	return video, nil
+}
+
+// shareError переводит ошибки ссылок и выдачи доступа в коды ответа
+func shareError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, constants.ErrVideoNotFound):
+		return responses.Error(c, http.StatusNotFound, "Video not found")
+	case errors.Is(err, errNotVideoOwner):
+		return responses.Error(c, http.StatusForbidden, "You don't have permission to share this video")
+	case errors.Is(err, constants.ErrShareLinkNotFound):
+		return responses.Error(c, http.StatusNotFound, "Share link not found")
+	case errors.Is(err, constants.ErrAccessGrantNotFound):
+		return responses.Error(c, http.StatusNotFound, "Access grant not found")
+	case errors.Is(err, constants.ErrNotFound):
+		return responses.Error(c, http.StatusNotFound, "User not found")
+	case errors.Is(err, constants.ErrInvalidShareExpiry):
+		return responses.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		return responses.Error(c, http.StatusInternalServerError, "Failed to process share request")
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/mrkbwp/gotube/internal/api/responses"
+	"github.com/mrkbwp/gotube/internal/infrastructure/storage"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"io"
+	"net/http"
+)
+
+// StorageHandler отдает объекты локального хранилища по подписанным ссылкам
+type StorageHandler struct {
+	storage *storage.LocalStorage
+}
+
+// NewStorageHandler создает новый StorageHandler
+func NewStorageHandler(localStorage *storage.LocalStorage) *StorageHandler {
+	return &StorageHandler{
+		storage: localStorage,
+	}
+}
+
+// GetObject отдает объект хранилища
+// @Summary Объект хранилища
+// @Description Отдает файл локального хранилища с поддержкой Range. Объекты непубличных бакетов требуют подписи из ссылки
+// @Tags storage
+// @Produce octet-stream
+// @Param bucket path string true "Бакет"
+// @Param object path string true "Путь к объекту"
+// @Param expires query int false "Срок действия ссылки (unix)"
+// @Param signature query string false "Подпись ссылки"
+// @Success 200 {file} file
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/storage/{bucket}/{object} [get]
+func (h *StorageHandler) GetObject(c echo.Context) error {
+	bucket := c.Param("bucket")
+	object := c.Param("*")
+
+	if !constants.PublicBuckets[bucket] &&
+		!h.storage.VerifySignature(bucket, object, c.QueryParam("expires"), c.QueryParam("signature")) {
+		return responses.Error(c, http.StatusForbidden, "Invalid or expired signature")
+	}
+
+	info, err := h.storage.StatFile(c.Request().Context(), bucket, object)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return responses.Error(c, http.StatusNotFound, "Object not found")
+		}
+		return responses.Error(c, http.StatusBadRequest, "Invalid object path")
+	}
+
+	file, err := h.storage.Open(bucket, object)
+	if err != nil {
+		return responses.Error(c, http.StatusNotFound, "Object not found")
+	}
+	defer file.Close()
+
+	c.Response().Header().Set(echo.HeaderContentType, info.ContentType)
+	c.Response().Header().Set("ETag", `"`+info.ETag+`"`)
+
+	http.ServeContent(c.Response(), c.Request(), info.Key, info.LastModified, file)
+	return nil
+}
+
+// maxPostFieldSize - ограничение текстовых полей формы загрузки
+const maxPostFieldSize = 64 << 10
+
+// PostObject загружает объект формой с подписанными условиями
+// @Summary Загрузка объекта формой
+// @Description Принимает multipart форму из PresignedPostPolicy: поля key, Content-Type, policy, signature и последним файл в поле file
+// @Tags storage
+// @Accept multipart/form-data
+// @Param bucket path string true "Бакет"
+// @Success 204
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Router /api/storage/{bucket} [post]
+func (h *StorageHandler) PostObject(c echo.Context) error {
+	reader, err := c.Request().MultipartReader()
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Multipart form is required")
+	}
+
+	fields := map[string]string{}
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return responses.Error(c, http.StatusBadRequest, "File is required")
+			}
+			return responses.Error(c, http.StatusBadRequest, "Invalid multipart form")
+		}
+
+		// Как и в S3, файл идет последним полем, поля после него игнорируются
+		if part.FormName() == "file" {
+			_, err := h.storage.PostObject(c.Request().Context(), c.Param("bucket"), fields, part)
+			part.Close()
+			if err != nil {
+				switch {
+				case errors.Is(err, constants.ErrInvalidSignature):
+					return responses.Error(c, http.StatusForbidden, "Invalid or expired signature")
+				case errors.Is(err, constants.ErrPolicyViolation):
+					return responses.Error(c, http.StatusBadRequest, err.Error())
+				}
+				return responses.Error(c, http.StatusInternalServerError, "Failed to upload object")
+			}
+			return c.NoContent(http.StatusNoContent)
+		}
+
+		value, err := io.ReadAll(io.LimitReader(part, maxPostFieldSize))
+		part.Close()
+		if err != nil {
+			return responses.Error(c, http.StatusBadRequest, "Invalid multipart form")
+		}
+		fields[part.FormName()] = string(value)
+	}
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mrkbwp/gotube/internal/api/responses"
+	"github.com/mrkbwp/gotube/internal/domain/services"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"net/http"
+)
+
+// SubtitleHandler обработчик для работы с субтитрами видео
+type SubtitleHandler struct {
+	videoService    services.VideoService
+	subtitleService services.SubtitleService
+}
+
+// NewSubtitleHandler создает новый SubtitleHandler
+func NewSubtitleHandler(videoService services.VideoService, subtitleService services.SubtitleService) *SubtitleHandler {
+	return &SubtitleHandler{
+		videoService:    videoService,
+		subtitleService: subtitleService,
+	}
+}
+
+// GetSubtitles возвращает субтитры видео
+// @Summary Субтитры видео
+// @Description Возвращает дорожки субтитров видео со ссылками на WebVTT
+// @Tags videos
+// @Produce json
+// @Param code path string true "Код видео"
+// @Success 200 {array} entity.VideoSubtitle
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/subtitles [get]
+func (h *SubtitleHandler) GetSubtitles(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	video, err := h.videoService.GetVideoByCode(ctx, c.Param("code"))
+	if err != nil {
+		return responses.Error(c, http.StatusNotFound, "Video not found")
+	}
+
+	if err := h.videoService.CheckVideoAccess(ctx, video, videoViewer(c)); err != nil {
+		return videoAccessError(c, err)
+	}
+
+	subtitles, err := h.subtitleService.GetSubtitles(ctx, video)
+	if err != nil {
+		return responses.Error(c, http.StatusInternalServerError, "Failed to get subtitles")
+	}
+
+	return responses.JSON(c, http.StatusOK, subtitles)
+}
+
+// UploadSubtitle загружает субтитры видео
+// @Summary Загрузка субтитров
+// @Description Загружает субтитры SRT или WebVTT до 2 МБ для языка, SRT конвертируется в WebVTT. Дорожка того же языка заменяется (только для владельца)
+// @Tags videos
+// @Accept multipart/form-data
+// @Produce json
+// @Param code path string true "Код видео"
+// @Param subtitle formData file true "Файл SRT или WebVTT"
+// @Param language formData string true "Язык в формате BCP 47, например en или pt-BR"
+// @Param label formData string false "Название дорожки для плеера"
+// @Security BearerAuth
+// @Success 201 {object} entity.VideoSubtitle
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 413 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/subtitles [post]
+func (h *SubtitleHandler) UploadSubtitle(c echo.Context) error {
+	userID := c.Get("userID").(uuid.UUID)
+	ctx := c.Request().Context()
+
+	video, err := h.videoService.GetVideoByCode(ctx, c.Param("code"))
+	if err != nil {
+		return responses.Error(c, http.StatusNotFound, "Video not found")
+	}
+
+	if video.UserID != userID {
+		return responses.Error(c, http.StatusForbidden, "You don't have permission to update this video")
+	}
+
+	file, fileHeader, err := c.Request().FormFile("subtitle")
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Subtitle file is required")
+	}
+	defer file.Close()
+
+	if fileHeader.Size > constants.MaxSubtitleUploadSize {
+		return responses.Error(c, http.StatusRequestEntityTooLarge, "Subtitle file is too large")
+	}
+
+	subtitle, err := h.subtitleService.UploadSubtitle(ctx, video, c.FormValue("language"), c.FormValue("label"), file)
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrInvalidLanguage):
+			return responses.Error(c, http.StatusBadRequest, "Invalid subtitle language")
+		case errors.Is(err, constants.ErrSubtitlesTooLarge):
+			return responses.Error(c, http.StatusRequestEntityTooLarge, "Subtitle file is too large")
+		case errors.Is(err, constants.ErrInvalidSubtitles):
+			return responses.Error(c, http.StatusBadRequest, err.Error())
+		}
+		return responses.Error(c, http.StatusInternalServerError, "Failed to upload subtitles")
+	}
+
+	return responses.JSON(c, http.StatusCreated, subtitle)
+}
+
+// DeleteSubtitle удаляет субтитры видео
+// @Summary Удаление субтитров
+// @Description Удаляет дорожку субтитров языка (только для владельца)
+// @Tags videos
+// @Produce json
+// @Param code path string true "Код видео"
+// @Param language path string true "Язык в формате BCP 47"
+// @Security BearerAuth
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/subtitles/{language} [delete]
+func (h *SubtitleHandler) DeleteSubtitle(c echo.Context) error {
+	userID := c.Get("userID").(uuid.UUID)
+	ctx := c.Request().Context()
+
+	video, err := h.videoService.GetVideoByCode(ctx, c.Param("code"))
+	if err != nil {
+		return responses.Error(c, http.StatusNotFound, "Video not found")
+	}
+
+	if video.UserID != userID {
+		return responses.Error(c, http.StatusForbidden, "You don't have permission to update this video")
+	}
+
+	if err := h.subtitleService.DeleteSubtitle(ctx, video, c.Param("language")); err != nil {
+		switch {
+		case errors.Is(err, constants.ErrInvalidLanguage):
+			return responses.Error(c, http.StatusBadRequest, "Invalid subtitle language")
+		case errors.Is(err, constants.ErrSubtitlesNotFound):
+			return responses.Error(c, http.StatusNotFound, "Subtitles not found")
+		}
+		return responses.Error(c, http.StatusInternalServerError, "Failed to delete subtitles")
+	}
+
+	return responses.Success(c, "Subtitles deleted successfully")
+}
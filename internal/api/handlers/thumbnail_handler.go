@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mrkbwp/gotube/internal/api/responses"
+	"github.com/mrkbwp/gotube/internal/domain/services"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"net/http"
+)
+
+// ThumbnailHandler обработчик для работы с обложками видео
+type ThumbnailHandler struct {
+	videoService     services.VideoService
+	thumbnailService services.ThumbnailService
+}
+
+// NewThumbnailHandler создает новый ThumbnailHandler
+func NewThumbnailHandler(videoService services.VideoService, thumbnailService services.ThumbnailService) *ThumbnailHandler {
+	return &ThumbnailHandler{
+		videoService:     videoService,
+		thumbnailService: thumbnailService,
+	}
+}
+
+// GetThumbnails возвращает варианты обложки видео
+// @Summary Варианты обложки видео
+// @Description Возвращает кадры-кандидаты и загруженные обложки видео (только для владельца)
+// @Tags videos
+// @Produce json
+// @Param code path string true "Код видео"
+// @Security BearerAuth
+// @Success 200 {array} entity.VideoThumbnail
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/thumbnails [get]
+func (h *ThumbnailHandler) GetThumbnails(c echo.Context) error {
+	userID := c.Get("userID").(uuid.UUID)
+	ctx := c.Request().Context()
+
+	video, err := h.videoService.GetVideoByCode(ctx, c.Param("code"))
+	if err != nil {
+		return responses.Error(c, http.StatusNotFound, "Video not found")
+	}
+
+	if video.UserID != userID {
+		return responses.Error(c, http.StatusForbidden, "You don't have permission to view this video thumbnails")
+	}
+
+	thumbnails, err := h.thumbnailService.GetThumbnails(ctx, video)
+	if err != nil {
+		return responses.Error(c, http.StatusInternalServerError, "Failed to get thumbnails")
+	}
+
+	return responses.JSON(c, http.StatusOK, thumbnails)
+}
+
+// SelectThumbnail делает вариант обложкой видео
+// @Summary Выбор обложки видео
+// @Description Делает один из вариантов обложкой видео (только для владельца)
+// @Tags videos
+// @Produce json
+// @Param code path string true "Код видео"
+// @Param id path string true "ID варианта обложки"
+// @Security BearerAuth
+// @Success 200 {object} entity.VideoThumbnail
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/thumbnails/{id}/select [put]
+func (h *ThumbnailHandler) SelectThumbnail(c echo.Context) error {
+	userID := c.Get("userID").(uuid.UUID)
+	ctx := c.Request().Context()
+
+	thumbnailID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid thumbnail ID")
+	}
+
+	video, err := h.videoService.GetVideoByCode(ctx, c.Param("code"))
+	if err != nil {
+		return responses.Error(c, http.StatusNotFound, "Video not found")
+	}
+
+	if video.UserID != userID {
+		return responses.Error(c, http.StatusForbidden, "You don't have permission to update this video")
+	}
+
+	thumbnail, err := h.thumbnailService.SelectThumbnail(ctx, video, thumbnailID)
+	if err != nil {
+		if errors.Is(err, constants.ErrThumbnailNotFound) {
+			return responses.Error(c, http.StatusNotFound, "Thumbnail not found")
+		}
+		return responses.Error(c, http.StatusInternalServerError, "Failed to select thumbnail")
+	}
+
+	return responses.JSON(c, http.StatusOK, thumbnail)
+}
+
+// UploadThumbnail загружает свою обложку видео
+// @Summary Загрузка обложки видео
+// @Description Загружает изображение JPEG, PNG или GIF не меньше 640x360 и до 5 МБ, сохраняет его в стандартных размерах и делает обложкой видео (только для владельца)
+// @Tags videos
+// @Accept multipart/form-data
+// @Produce json
+// @Param code path string true "Код видео"
+// @Param thumbnail formData file true "Изображение обложки"
+// @Security BearerAuth
+// @Success 201 {object} entity.VideoThumbnail
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 413 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/thumbnails [post]
+func (h *ThumbnailHandler) UploadThumbnail(c echo.Context) error {
+	userID := c.Get("userID").(uuid.UUID)
+	ctx := c.Request().Context()
+
+	video, err := h.videoService.GetVideoByCode(ctx, c.Param("code"))
+	if err != nil {
+		return responses.Error(c, http.StatusNotFound, "Video not found")
+	}
+
+	if video.UserID != userID {
+		return responses.Error(c, http.StatusForbidden, "You don't have permission to update this video")
+	}
+
+	file, fileHeader, err := c.Request().FormFile("thumbnail")
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Thumbnail file is required")
+	}
+	defer file.Close()
+
+	if fileHeader.Size > constants.MaxThumbnailUploadSize {
+		return responses.Error(c, http.StatusRequestEntityTooLarge, "Thumbnail image is too large")
+	}
+
+	thumbnail, err := h.thumbnailService.UploadCustomThumbnail(ctx, video, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrThumbnailTooLarge):
+			return responses.Error(c, http.StatusRequestEntityTooLarge, "Thumbnail image is too large")
+		case errors.Is(err, constants.ErrThumbnailTooSmall):
+			return responses.Error(c, http.StatusBadRequest, "Thumbnail image must be at least 640x360")
+		case errors.Is(err, constants.ErrInvalidThumbnail):
+			return responses.Error(c, http.StatusBadRequest, "Thumbnail must be a JPEG, PNG or GIF image")
+		}
+		return responses.Error(c, http.StatusInternalServerError, "Failed to upload thumbnail")
+	}
+
+	return responses.JSON(c, http.StatusCreated, thumbnail)
+}
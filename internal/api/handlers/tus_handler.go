@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mrkbwp/gotube/internal/api/responses"
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/services"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Заголовки протокола tus
+const (
+	headerTusResumable   = "Tus-Resumable"
+	headerTusVersion     = "Tus-Version"
+	headerTusExtension   = "Tus-Extension"
+	headerUploadLength   = "Upload-Length"
+	headerUploadOffset   = "Upload-Offset"
+	headerUploadMetadata = "Upload-Metadata"
+	headerUploadExpires  = "Upload-Expires"
+)
+
+// TusExposeHeaders - заголовки tus, которые браузерный клиент должен видеть в ответах (CORS)
+var TusExposeHeaders = []string{
+	echo.HeaderLocation,
+	headerTusResumable,
+	headerTusVersion,
+	headerTusExtension,
+	headerUploadLength,
+	headerUploadOffset,
+	headerUploadMetadata,
+	headerUploadExpires,
+}
+
+// TusHandler обработчик возобновляемой загрузки видео по протоколу tus 1.0
+type TusHandler struct {
+	uploadService services.UploadService
+}
+
+// NewTusHandler создает новый TusHandler
+func NewTusHandler(uploadService services.UploadService) *TusHandler {
+	return &TusHandler{
+		uploadService: uploadService,
+	}
+}
+
+// TusResumable проверяет версию протокола клиента и добавляет ее в ответ
+func (h *TusHandler) TusResumable(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Response().Header().Set(headerTusResumable, constants.TusVersion)
+
+		if c.Request().Header.Get(headerTusResumable) != constants.TusVersion {
+			c.Response().Header().Set(headerTusVersion, constants.TusVersion)
+			return responses.Error(c, http.StatusPreconditionFailed, "Unsupported tus version")
+		}
+
+		return next(c)
+	}
+}
+
+// Options возвращает возможности сервера tus
+// @Summary Возможности tus
+// @Description Возвращает версию и расширения протокола tus
+// @Tags uploads
+// @Success 204
+// @Router /api/uploads [options]
+func (h *TusHandler) Options(c echo.Context) error {
+	header := c.Response().Header()
+	header.Set(headerTusResumable, constants.TusVersion)
+	header.Set(headerTusVersion, constants.TusVersion)
+	header.Set(headerTusExtension, constants.TusExtensions)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CreateUpload создает возобновляемую загрузку
+// @Summary Создание загрузки tus
+// @Description Создает видео в статусе uploading и загрузку его исходника. Upload-Metadata: filename, category_id (обязательны), title, description
+// @Tags uploads
+// @Produce json
+// @Param Tus-Resumable header string true "Версия tus (1.0.0)"
+// @Param Upload-Length header int true "Размер файла в байтах"
+// @Param Upload-Metadata header string true "Метаданные tus: ключ и значение в base64 через пробел, пары через запятую"
+// @Security BearerAuth
+// @Success 201 {object} entity.VideoUpload
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 412 {object} responses.ErrorResponse
+// @Failure 413 {object} responses.ErrorResponse
+// @Failure 429 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/uploads [post]
+func (h *TusHandler) CreateUpload(c echo.Context) error {
+	userID := c.Get("userID").(uuid.UUID)
+
+	length, err := strconv.ParseInt(c.Request().Header.Get(headerUploadLength), 10, 64)
+	if err != nil || length <= 0 {
+		return responses.Error(c, http.StatusBadRequest, "Upload-Length is required")
+	}
+
+	metadata, err := parseUploadMetadata(c.Request().Header.Get(headerUploadMetadata))
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid Upload-Metadata")
+	}
+
+	upload, err := h.uploadService.CreateUpload(c.Request().Context(), userID, length, metadata)
+	if err != nil {
+		if status := uploadLimitStatus(err); status != 0 {
+			return uploadLimitError(c, status, err)
+		}
+		if errors.Is(err, constants.ErrInvalidUpload) {
+			return responses.Error(c, http.StatusBadRequest, err.Error())
+		}
+		return responses.Error(c, http.StatusInternalServerError, "Failed to create upload")
+	}
+
+	c.Response().Header().Set(echo.HeaderLocation, c.Scheme()+"://"+c.Request().Host+c.Request().URL.Path+"/"+upload.ID.String())
+	setUploadHeaders(c, upload)
+
+	return responses.JSON(c, http.StatusCreated, upload)
+}
+
+// GetUploadOffset возвращает смещение загрузки
+// @Summary Состояние загрузки tus
+// @Description Возвращает в заголовках, сколько байт загрузки уже принято
+// @Tags uploads
+// @Param id path string true "ID загрузки"
+// @Param Tus-Resumable header string true "Версия tus (1.0.0)"
+// @Security BearerAuth
+// @Success 200
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 410 {object} responses.ErrorResponse
+// @Router /api/uploads/{id} [head]
+func (h *TusHandler) GetUploadOffset(c echo.Context) error {
+	upload, err := h.getUpload(c)
+	if err != nil {
+		return uploadError(c, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	setUploadHeaders(c, upload)
+
+	return c.NoContent(http.StatusOK)
+}
+
+// WriteChunk дописывает кусок загрузки
+// @Summary Кусок загрузки tus
+// @Description Дописывает байты тела с позиции Upload-Offset. Последний кусок отправляет видео на обработку
+// @Tags uploads
+// @Accept application/offset+octet-stream
+// @Param id path string true "ID загрузки"
+// @Param Tus-Resumable header string true "Версия tus (1.0.0)"
+// @Param Upload-Offset header int true "Смещение куска"
+// @Security BearerAuth
+// @Success 204
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 409 {object} responses.ErrorResponse
+// @Failure 410 {object} responses.ErrorResponse
+// @Failure 415 {object} responses.ErrorResponse "Тип тела запроса или файл без сигнатуры видео"
+// @Failure 423 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/uploads/{id} [patch]
+func (h *TusHandler) WriteChunk(c echo.Context) error {
+	if c.Request().Header.Get(echo.HeaderContentType) != constants.TusContentType {
+		return responses.Error(c, http.StatusUnsupportedMediaType, "Content-Type must be "+constants.TusContentType)
+	}
+
+	offset, err := strconv.ParseInt(c.Request().Header.Get(headerUploadOffset), 10, 64)
+	if err != nil || offset < 0 {
+		return responses.Error(c, http.StatusBadRequest, "Upload-Offset is required")
+	}
+
+	upload, err := h.getUpload(c)
+	if err != nil {
+		return uploadError(c, err)
+	}
+
+	if c.Request().ContentLength > upload.Length-offset {
+		return responses.Error(c, http.StatusBadRequest, "Chunk exceeds Upload-Length")
+	}
+
+	upload, err = h.uploadService.WriteChunk(c.Request().Context(), upload, offset, c.Request().Body)
+	if err != nil {
+		return uploadError(c, err)
+	}
+
+	setUploadHeaders(c, upload)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// TerminateUpload прерывает загрузку
+// @Summary Прерывание загрузки tus
+// @Description Прерывает незавершенную загрузку и удаляет созданное для нее видео
+// @Tags uploads
+// @Param id path string true "ID загрузки"
+// @Param Tus-Resumable header string true "Версия tus (1.0.0)"
+// @Security BearerAuth
+// @Success 204
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 409 {object} responses.ErrorResponse
+// @Failure 423 {object} responses.ErrorResponse
+// @Router /api/uploads/{id} [delete]
+func (h *TusHandler) TerminateUpload(c echo.Context) error {
+	upload, err := h.getUpload(c)
+	if err != nil {
+		return uploadError(c, err)
+	}
+
+	if err := h.uploadService.TerminateUpload(c.Request().Context(), upload); err != nil {
+		return uploadError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getUpload возвращает загрузку текущего пользователя из параметра id
+func (h *TusHandler) getUpload(c echo.Context) (*entity.VideoUpload, error) {
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, constants.ErrUploadNotFound
+	}
+
+	return h.uploadService.GetUpload(c.Request().Context(), id, userID)
+}
+
+// uploadError переводит ошибки загрузки в коды ответа
+func uploadError(c echo.Context, err error) error {
+	if status := uploadLimitStatus(err); status != 0 {
+		return uploadLimitError(c, status, err)
+	}
+
+	switch {
+	case errors.Is(err, constants.ErrUploadNotFound):
+		return responses.Error(c, http.StatusNotFound, "Upload not found")
+	case errors.Is(err, constants.ErrUploadExpired):
+		return responses.Error(c, http.StatusGone, "Upload expired")
+	case errors.Is(err, constants.ErrUploadOffsetMismatch):
+		return responses.Error(c, http.StatusConflict, "Upload-Offset does not match upload offset")
+	case errors.Is(err, constants.ErrUploadCompleted):
+		return responses.Error(c, http.StatusConflict, "Upload already completed")
+	case errors.Is(err, constants.ErrUploadLocked):
+		return responses.Error(c, http.StatusLocked, "Upload is locked by another request")
+	case errors.Is(err, constants.ErrUploadNotReceived):
+		return responses.Error(c, http.StatusConflict, "File has not been uploaded to storage yet")
+	case errors.Is(err, constants.ErrInvalidUpload):
+		return responses.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		return responses.Error(c, http.StatusInternalServerError, "Failed to process upload")
+	}
+}
+
+func setUploadHeaders(c echo.Context, upload *entity.VideoUpload) {
+	header := c.Response().Header()
+	header.Set(headerUploadOffset, strconv.FormatInt(upload.Offset, 10))
+	header.Set(headerUploadLength, strconv.FormatInt(upload.Length, 10))
+	if upload.Status == constants.UploadStatusUploading {
+		header.Set(headerUploadExpires, upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// parseUploadMetadata разбирает Upload-Metadata: пары "ключ base64(значение)" через запятую, значение может отсутствовать
+func parseUploadMetadata(value string) (map[string]string, error) {
+	metadata := map[string]string{}
+	if strings.TrimSpace(value) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("empty metadata key")
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, err
+		}
+		metadata[key] = string(decoded)
+	}
+
+	return metadata, nil
+}
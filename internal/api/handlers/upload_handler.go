@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mrkbwp/gotube/internal/api/requests"
+	"github.com/mrkbwp/gotube/internal/api/responses"
+	"github.com/mrkbwp/gotube/internal/domain/services"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"github.com/mrkbwp/gotube/pkg/validator"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// UploadHandler обработчик загрузки исходников прямо в хранилище, минуя API
+type UploadHandler struct {
+	uploadService services.UploadService
+	validator     *validator.Validator
+}
+
+// NewUploadHandler создает новый UploadHandler
+func NewUploadHandler(uploadService services.UploadService, validator *validator.Validator) *UploadHandler {
+	return &UploadHandler{
+		uploadService: uploadService,
+		validator:     validator,
+	}
+}
+
+// CreateDirectUpload создает загрузку исходника прямо в хранилище
+// @Summary Прямая загрузка в хранилище
+// @Description Создает видео в статусе uploading и подписанную форму POST. Поля form_data отправляются multipart на url,
+// @Description файл передается последним полем file. Хранилище примет только файл заявленных размера, типа и контрольной суммы
+// @Tags uploads
+// @Accept json
+// @Produce json
+// @Param upload body requests.CreateDirectUploadRequest true "Сведения о файле"
+// @Security BearerAuth
+// @Success 201 {object} dto.DirectUploadResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 413 {object} responses.ErrorResponse
+// @Failure 429 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/uploads/direct [post]
+func (h *UploadHandler) CreateDirectUpload(c echo.Context) error {
+	userID := c.Get("userID").(uuid.UUID)
+
+	var req requests.CreateDirectUploadRequest
+	if err := c.Bind(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request data")
+	}
+	if err := h.validator.Validate(req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, err.Error())
+	}
+
+	upload, err := h.uploadService.CreateDirectUpload(c.Request().Context(), userID, req.Size, map[string]string{
+		"filename":     req.Filename,
+		"title":        req.Title,
+		"description":  req.Description,
+		"category_id":  req.CategoryID,
+		"content_type": req.ContentType,
+		"checksum":     req.Checksum,
+	})
+	if err != nil {
+		if status := uploadLimitStatus(err); status != 0 {
+			return uploadLimitError(c, status, err)
+		}
+		if errors.Is(err, constants.ErrInvalidUpload) {
+			return responses.Error(c, http.StatusBadRequest, err.Error())
+		}
+		return responses.Error(c, http.StatusInternalServerError, "Failed to create upload")
+	}
+
+	return responses.JSON(c, http.StatusCreated, upload)
+}
+
+// CompleteDirectUpload завершает загрузку исходника прямо в хранилище
+// @Summary Завершение прямой загрузки
+// @Description Проверяет, что исходник загружен в хранилище и является видео, и отправляет видео на обработку
+// @Tags uploads
+// @Produce json
+// @Param id path string true "ID загрузки"
+// @Security BearerAuth
+// @Success 200 {object} entity.VideoUpload
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 409 {object} responses.ErrorResponse
+// @Failure 410 {object} responses.ErrorResponse
+// @Failure 415 {object} responses.ErrorResponse
+// @Failure 423 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/uploads/direct/{id}/complete [post]
+func (h *UploadHandler) CompleteDirectUpload(c echo.Context) error {
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uploadError(c, constants.ErrUploadNotFound)
+	}
+
+	upload, err := h.uploadService.GetUpload(c.Request().Context(), id, userID)
+	if err != nil {
+		return uploadError(c, err)
+	}
+
+	upload, err = h.uploadService.CompleteDirectUpload(c.Request().Context(), upload)
+	if err != nil {
+		return uploadError(c, err)
+	}
+
+	return responses.JSON(c, http.StatusOK, upload)
+}
+
+// uploadLimitStatus возвращает код ответа для ошибки ограничений загрузки, 0 для остальных ошибок
+func uploadLimitStatus(err error) int {
+	switch {
+	case errors.Is(err, constants.ErrFileTooLarge), errors.Is(err, constants.ErrStorageQuotaExceeded),
+		errors.Is(err, constants.ErrBatchTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, constants.ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, constants.ErrDailyUploadQuotaExceeded):
+		return http.StatusTooManyRequests
+	}
+	return 0
+}
+
+// uploadLimitError отвечает на ошибку ограничений загрузки. Суточная квота обновляется в полночь UTC,
+// до нее клиент получает Retry-After
+func uploadLimitError(c echo.Context, status int, err error) error {
+	if status == http.StatusTooManyRequests {
+		now := time.Now().UTC()
+		nextDay := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(nextDay.Sub(now).Seconds())+1))
+	}
+
+	return responses.Error(c, status, err.Error())
+}
@@ -1,45 +1,104 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"github.com/mrkbwp/gotube/internal/api/requests"
 	"github.com/mrkbwp/gotube/internal/api/responses"
 	"github.com/mrkbwp/gotube/internal/domain/services"
+	"github.com/mrkbwp/gotube/internal/dto"
+	"github.com/mrkbwp/gotube/pkg/constants"
 	"github.com/mrkbwp/gotube/pkg/pagination"
 	"github.com/mrkbwp/gotube/pkg/validator"
 	"net/http"
+	"net/url"
 	"path/filepath"
 	"strings"
+	"time"
+)
+
+const (
+	hlsContentType  = "application/vnd.apple.mpegurl"
+	dashContentType = "application/dash+xml"
+
+	// Ссылка доступа передается параметром share или заголовком, пароль ссылки - только заголовком
+	shareTokenParam     = "share"
+	shareTokenHeader    = "X-Share-Token"
+	sharePasswordHeader = "X-Share-Password"
 )
 
 // VideoHandler обработчик для видео-API
 type VideoHandler struct {
-	videoService services.VideoService
-	validator    *validator.Validator
+	videoService    services.VideoService
+	subtitleService services.SubtitleService
+	categoryService services.CategoryService
+	validator       *validator.Validator
 }
 
 // NewVideoHandler создает новый VideoHandler
-func NewVideoHandler(videoService services.VideoService, validator *validator.Validator) *VideoHandler {
+func NewVideoHandler(
+	videoService services.VideoService,
+	subtitleService services.SubtitleService,
+	categoryService services.CategoryService,
+	validator *validator.Validator,
+) *VideoHandler {
 	return &VideoHandler{
-		videoService: videoService,
-		validator:    validator,
+		videoService:    videoService,
+		subtitleService: subtitleService,
+		categoryService: categoryService,
+		validator:       validator,
 	}
 }
 
 // UploadVideo загружает новое видео
 // @Summary Загрузка видео
-// @Description Загружает новое видео в систему, название и описание берутся из имени файла
+// @Description Загружает новое видео в систему. Без названия используется имя файла, теги - повторяющимся полем или через запятую
 // @Tags videos
 // @Accept multipart/form-data
 // @Produce json
 // @Param video formData file true "Видеофайл"
+// @Param title formData string false "Название"
+// @Param description formData string false "Описание"
+// @Param category_id formData string true "ID категории"
+// @Param privacy formData string false "Видимость: public, unlisted, private или scheduled"
+// @Param publish_at formData string false "Время публикации в RFC 3339, обязательно для scheduled"
+// @Param tags formData []string false "Теги"
 // @Security BearerAuth
 // @Success 201 {object} entity.Video
 // @Failure 400 {object} responses.ErrorResponse
+// @Failure 413 {object} responses.ErrorResponse
+// @Failure 415 {object} responses.ErrorResponse
+// @Failure 429 {object} responses.ErrorResponse
 // @Failure 500 {object} responses.ErrorResponse
 // @Router /api/videos [post]
 func (h *VideoHandler) UploadVideo(c echo.Context) error {
 	userID := c.Get("userID").(uuid.UUID)
+	ctx := c.Request().Context()
+
+	var req requests.UploadVideoRequest
+	if err := c.Bind(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request data")
+	}
+	req.Tags = splitTags(req.Tags)
+	if err := h.validator.Validate(req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, err.Error())
+	}
+
+	publishAt, err := parsePublishAt(req.Privacy, req.PublishAt)
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, err.Error())
+	}
+
+	categoryID := uuid.MustParse(req.CategoryID)
+	if _, err := h.categoryService.GetCategoryByID(ctx, categoryID); err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return responses.Error(c, http.StatusBadRequest, "Category not found")
+		}
+		return responses.Error(c, http.StatusInternalServerError, "Failed to get category")
+	}
 
 	// Получаем файл из запроса
 	file, fileHeader, err := c.Request().FormFile("video")
@@ -48,41 +107,139 @@ func (h *VideoHandler) UploadVideo(c echo.Context) error {
 	}
 	defer file.Close()
 
-	// Извлекаем название и расширение файла
-	filename := fileHeader.Filename
-	ext := filepath.Ext(filename)
-	title := strings.TrimSuffix(filename, ext) // Название без расширения
-
-	// Описание оставляем пустым или используем то же имя файла
-	description := ""
-
-	ctx := c.Request().Context()
+	title := req.Title
+	if title == "" {
+		title = titleFromFilename(fileHeader.Filename)
+	}
 
 	// Вызываем сервис для загрузки видео
 	video, err := h.videoService.UploadVideo(
 		ctx,
 		userID,
-		uuid.MustParse("c6d76596-9407-6e93-d8ae-1f2103e3f33d"),
 		file,
-		filename,
-		title,
-		description,
+		fileHeader.Size,
+		fileHeader.Filename,
+		dto.VideoDetails{
+			CategoryID:  categoryID,
+			Title:       title,
+			Description: req.Description,
+			Visibility:  req.Privacy,
+			PublishAt:   publishAt,
+			Tags:        req.Tags,
+		},
 	)
 	if err != nil {
-		return responses.Error(c, http.StatusInternalServerError, "Failed to upload video"+err.Error())
+		if status := uploadLimitStatus(err); status != 0 {
+			return uploadLimitError(c, status, err)
+		}
+		if errors.Is(err, constants.ErrInvalidVisibility) {
+			return responses.Error(c, http.StatusBadRequest, err.Error())
+		}
+		// Внутренняя ошибка остается в логе, клиенту ее подробности не отдаем
+		fmt.Printf("Failed to upload video for user %s: %v\n", userID, err)
+		return responses.Error(c, http.StatusInternalServerError, "Failed to upload video")
 	}
 
 	return responses.JSON(c, http.StatusCreated, video)
 }
 
+// titleFromFilename возвращает название видео по имени файла: без расширения и не длиннее допустимого
+func titleFromFilename(filename string) string {
+	name := filepath.Base(filename)
+	title := strings.TrimSuffix(name, filepath.Ext(name))
+	if runes := []rune(title); len(runes) > 100 {
+		title = string(runes[:100])
+	}
+	return title
+}
+
+// splitTags разбирает теги, переданные повторяющимся полем или через запятую
+func splitTags(values []string) []string {
+	var tags []string
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// parsePublishAt разбирает время публикации в RFC 3339, оно нужно только запланированным видео
+func parsePublishAt(visibility, value string) (*time.Time, error) {
+	if visibility != constants.VideoVisibilityScheduled {
+		return nil, nil
+	}
+
+	publishAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, errors.New("publish_at must be a time in RFC 3339 format")
+	}
+	return &publishAt, nil
+}
+
+// viewerID возвращает ID пользователя, если запрос авторизован
+func viewerID(c echo.Context) *uuid.UUID {
+	if userID, ok := c.Get("userID").(uuid.UUID); ok {
+		return &userID
+	}
+	return nil
+}
+
+// videoViewer собирает зрителя из запроса: пользователя по токену авторизации и ссылку доступа с паролем
+func videoViewer(c echo.Context) dto.VideoViewer {
+	shareToken := c.QueryParam(shareTokenParam)
+	if shareToken == "" {
+		shareToken = c.Request().Header.Get(shareTokenHeader)
+	}
+
+	return dto.VideoViewer{
+		UserID:        viewerID(c),
+		ShareToken:    shareToken,
+		SharePassword: c.Request().Header.Get(sharePasswordHeader),
+		IP:            c.RealIP(),
+	}
+}
+
+// videoAccessStatus возвращает HTTP статус для ошибки проверки доступа к видео или 0, если ошибка другая
+func videoAccessStatus(err error) int {
+	switch {
+	case errors.Is(err, constants.ErrVideoPrivate), errors.Is(err, constants.ErrVideoBlocked),
+		errors.Is(err, constants.ErrInvalidShareLink), errors.Is(err, constants.ErrInvalidSharePassword):
+		return http.StatusForbidden
+	case errors.Is(err, constants.ErrSharePasswordRequired):
+		return http.StatusUnauthorized
+	case errors.Is(err, constants.ErrShareLinkExpired), errors.Is(err, constants.ErrShareViewLimitReached):
+		return http.StatusGone
+	case errors.Is(err, constants.ErrTooManyShareAttempts):
+		return http.StatusTooManyRequests
+	case errors.Is(err, constants.ErrVideoProcessing):
+		return http.StatusConflict
+	case errors.Is(err, constants.ErrInvalidStatus):
+		return http.StatusNotFound
+	}
+	return 0
+}
+
+// videoAccessError отвечает на ошибку проверки доступа к видео
+func videoAccessError(c echo.Context, err error) error {
+	if status := videoAccessStatus(err); status != 0 {
+		return responses.Error(c, status, err.Error())
+	}
+	return responses.Error(c, http.StatusInternalServerError, "Failed to check video access")
+}
+
 // GetVideoByCode возвращает информацию о видео по коду
 // @Summary Получение видео по коду
-// @Description Возвращает информацию о видео по его коду
+// @Description Возвращает информацию о видео по его коду. Неопубликованные и приватные видео доступны только владельцу и администратору
 // @Tags videos
 // @Produce json
 // @Param code path string true "Код видео"
 // @Success 200 {object} entity.Video
+// @Failure 403 {object} responses.ErrorResponse
 // @Failure 404 {object} responses.ErrorResponse
+// @Failure 409 {object} responses.ErrorResponse
 // @Failure 500 {object} responses.ErrorResponse
 // @Router /api/videos/{code} [get]
 func (h *VideoHandler) GetVideoByCode(c echo.Context) error {
@@ -94,25 +251,295 @@ func (h *VideoHandler) GetVideoByCode(c echo.Context) error {
 		return responses.Error(c, http.StatusNotFound, "Video not found")
 	}
 
-	// Получаем список файлов с разными качествами
-	files, err := h.videoService.GetVideoFiles(ctx, video)
+	// Получаем список файлов с разными качествами, сервис сначала проверяет доступ
+	files, err := h.videoService.GetVideoFiles(ctx, video, videoViewer(c))
 	if err != nil {
+		if videoAccessStatus(err) != 0 {
+			return videoAccessError(c, err)
+		}
 		return responses.Error(c, http.StatusInternalServerError, "Failed to get video files"+err.Error())
 	}
 
+	for _, file := range files {
+		if file.Format == constants.StreamingFormatDASH {
+			file.URL = streamURL(c, video.VideoCode, constants.StreamingFormatDASH+"/"+constants.DASHManifest)
+		}
+	}
+
 	video.Files = files
 
-	userID, ok := c.Get("userID").(uuid.UUID)
-	userIP := c.RealIP()
-	if ok {
-		go h.videoService.ViewVideo(ctx, video.ID, &userID, userIP)
-	} else {
-		go h.videoService.ViewVideo(ctx, video.ID, nil, userIP)
+	subtitles, err := h.subtitleService.GetSubtitles(ctx, video)
+	if err != nil {
+		return responses.Error(c, http.StatusInternalServerError, "Failed to get video subtitles")
 	}
+	video.Subtitles = subtitles
+
+	go h.videoService.ViewVideo(ctx, video.ID, viewerID(c), c.RealIP())
 
 	return responses.JSON(c, http.StatusOK, video)
 }
 
+// GetVideoStreaming возвращает ссылку на мастер-плейлист HLS
+// @Summary Ссылка на адаптивный стрим
+// @Description Возвращает ссылку на мастер-плейлист HLS, плеер сам переключает качество. Токен ссылки доступа передается в ссылке
+// @Tags videos
+// @Produce json
+// @Param code path string true "Код видео"
+// @Success 200 {object} dto.StreamingResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 409 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/hls [get]
+func (h *VideoHandler) GetVideoStreaming(c echo.Context) error {
+	videoCode := c.Param("code")
+	ctx := c.Request().Context()
+
+	video, err := h.videoService.GetVideoByCode(ctx, videoCode)
+	if err != nil {
+		return responses.Error(c, http.StatusNotFound, "Video not found")
+	}
+
+	viewer := videoViewer(c)
+	if err := h.videoService.CheckVideoAccess(ctx, video, viewer); err != nil {
+		return videoAccessError(c, err)
+	}
+
+	if _, err := h.videoService.GetHLSMasterPlaylist(ctx, video); err != nil {
+		if errors.Is(err, constants.ErrStreamNotFound) {
+			return responses.Error(c, http.StatusNotFound, "Stream is not ready")
+		}
+		return responses.Error(c, http.StatusInternalServerError, "Failed to get stream")
+	}
+
+	return responses.JSON(c, http.StatusOK, dto.StreamingResponse{
+		HLSURL: streamURL(c, video.VideoCode, constants.StreamingFormatHLS+"/"+constants.HLSMasterPlaylist) + shareQuery(viewer),
+	})
+}
+
+// GetHLSMasterPlaylist отдает мастер-плейлист HLS
+// @Summary Мастер-плейлист HLS
+// @Description Возвращает мастер-плейлист HLS со списком доступных качеств, к их ссылкам дописывается токен ссылки доступа
+// @Tags videos
+// @Produce application/vnd.apple.mpegurl
+// @Param code path string true "Код видео"
+// @Success 200 {string} string
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 409 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/hls/master.m3u8 [get]
+func (h *VideoHandler) GetHLSMasterPlaylist(c echo.Context) error {
+	videoCode := c.Param("code")
+	ctx := c.Request().Context()
+
+	video, err := h.videoService.GetVideoByCode(ctx, videoCode)
+	if err != nil {
+		return responses.Error(c, http.StatusNotFound, "Video not found")
+	}
+
+	viewer := videoViewer(c)
+	if err := h.videoService.CheckVideoPlayback(ctx, video, viewer); err != nil {
+		return videoAccessError(c, err)
+	}
+
+	playlist, err := h.videoService.GetHLSMasterPlaylist(ctx, video)
+	if err != nil {
+		if errors.Is(err, constants.ErrStreamNotFound) {
+			return responses.Error(c, http.StatusNotFound, "Stream is not ready")
+		}
+		return responses.Error(c, http.StatusInternalServerError, "Failed to get playlist")
+	}
+
+	return c.Blob(http.StatusOK, hlsContentType, withShareQuery(playlist, shareQuery(viewer)))
+}
+
+// GetHLSVariantPlaylist отдает плейлист HLS для одного качества
+// @Summary Плейлист качества HLS
+// @Description Возвращает плейлист качества с временными ссылками на сегменты
+// @Tags videos
+// @Produce application/vnd.apple.mpegurl
+// @Param code path string true "Код видео"
+// @Param quality path string true "Качество"
+// @Success 200 {string} string
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 409 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/hls/{quality}/index.m3u8 [get]
+func (h *VideoHandler) GetHLSVariantPlaylist(c echo.Context) error {
+	videoCode := c.Param("code")
+	quality := c.Param("quality")
+	ctx := c.Request().Context()
+
+	video, err := h.videoService.GetVideoByCode(ctx, videoCode)
+	if err != nil {
+		return responses.Error(c, http.StatusNotFound, "Video not found")
+	}
+
+	if err := h.videoService.CheckVideoPlayback(ctx, video, videoViewer(c)); err != nil {
+		return videoAccessError(c, err)
+	}
+
+	playlist, err := h.videoService.GetHLSVariantPlaylist(ctx, video, quality)
+	if err != nil {
+		if errors.Is(err, constants.ErrStreamNotFound) {
+			return responses.Error(c, http.StatusNotFound, "Stream is not ready")
+		}
+		return responses.Error(c, http.StatusInternalServerError, "Failed to get playlist")
+	}
+
+	return c.Blob(http.StatusOK, hlsContentType, playlist)
+}
+
+// GetDASHManifest отдает DASH манифест
+// @Summary DASH манифест
+// @Description Возвращает DASH манифест со всеми качествами и временными ссылками на сегменты
+// @Tags videos
+// @Produce application/dash+xml
+// @Param code path string true "Код видео"
+// @Success 200 {string} string
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 409 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/dash/manifest.mpd [get]
+func (h *VideoHandler) GetDASHManifest(c echo.Context) error {
+	videoCode := c.Param("code")
+	ctx := c.Request().Context()
+
+	video, err := h.videoService.GetVideoByCode(ctx, videoCode)
+	if err != nil {
+		return responses.Error(c, http.StatusNotFound, "Video not found")
+	}
+
+	if err := h.videoService.CheckVideoPlayback(ctx, video, videoViewer(c)); err != nil {
+		return videoAccessError(c, err)
+	}
+
+	manifest, err := h.videoService.GetDASHManifest(ctx, video)
+	if err != nil {
+		if errors.Is(err, constants.ErrStreamNotFound) {
+			return responses.Error(c, http.StatusNotFound, "Stream is not ready")
+		}
+		return responses.Error(c, http.StatusInternalServerError, "Failed to get manifest")
+	}
+
+	return c.Blob(http.StatusOK, dashContentType, manifest)
+}
+
+// streamURL формирует абсолютную ссылку на манифест стриминга, отдаваемый через API
+func streamURL(c echo.Context, videoCode, path string) string {
+	return fmt.Sprintf("%s://%s/api/v1/videos/%s/%s", c.Scheme(), c.Request().Host, videoCode, path)
+}
+
+// shareQuery возвращает параметр с токеном ссылки или токеном зрителя для ссылок на плейлисты.
+// Плеер не повторяет заголовки исходного запроса, поэтому токен передается в самих ссылках
+func shareQuery(viewer dto.VideoViewer) string {
+	if viewer.ShareToken == "" {
+		return ""
+	}
+	return "?" + shareTokenParam + "=" + url.QueryEscape(viewer.ShareToken)
+}
+
+// withShareQuery дописывает query к ссылкам мастер-плейлиста на плейлисты качеств
+func withShareQuery(playlist []byte, query string) []byte {
+	if query == "" {
+		return playlist
+	}
+
+	lines := strings.Split(string(playlist), "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines[i] = line + query
+	}
+
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// GetVideoProcessing возвращает прогресс обработки видео по качествам
+// @Summary Прогресс обработки видео
+// @Description Возвращает статус видео и процент готовности каждого качества (только для владельца)
+// @Tags videos
+// @Produce json
+// @Param code path string true "Код видео"
+// @Security BearerAuth
+// @Success 200 {object} dto.ProcessingResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/processing [get]
+func (h *VideoHandler) GetVideoProcessing(c echo.Context) error {
+	userID := c.Get("userID").(uuid.UUID)
+	ctx := c.Request().Context()
+
+	video, err := h.videoService.GetVideoByCode(ctx, c.Param("code"))
+	if err != nil {
+		return responses.Error(c, http.StatusNotFound, "Video not found")
+	}
+
+	if video.UserID != userID {
+		return responses.Error(c, http.StatusForbidden, "You don't have permission to view this video processing")
+	}
+
+	status, err := h.videoService.GetProcessingStatus(ctx, video)
+	if err != nil {
+		return responses.Error(c, http.StatusInternalServerError, "Failed to get processing status")
+	}
+
+	return responses.JSON(c, http.StatusOK, status)
+}
+
+// StreamVideoProcessing отправляет прогресс обработки видео как Server-Sent Events
+// @Summary Поток прогресса обработки видео
+// @Description Server-Sent Events с прогрессом обработки, поток закрывается, когда все качества готовы или обработка завершилась ошибкой (только для владельца)
+// @Tags videos
+// @Produce text/event-stream
+// @Param code path string true "Код видео"
+// @Security BearerAuth
+// @Success 200 {object} dto.ProcessingResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /api/videos/{code}/processing/stream [get]
+func (h *VideoHandler) StreamVideoProcessing(c echo.Context) error {
+	userID := c.Get("userID").(uuid.UUID)
+	ctx := c.Request().Context()
+
+	video, err := h.videoService.GetVideoByCode(ctx, c.Param("code"))
+	if err != nil {
+		return responses.Error(c, http.StatusNotFound, "Video not found")
+	}
+
+	if video.UserID != userID {
+		return responses.Error(c, http.StatusForbidden, "You don't have permission to view this video processing")
+	}
+
+	updates, err := h.videoService.WatchProcessing(ctx, video)
+	if err != nil {
+		return responses.Error(c, http.StatusInternalServerError, "Failed to watch processing status")
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.WriteHeader(http.StatusOK)
+
+	for status := range updates {
+		data, err := json.Marshal(status)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(res, "event: progress\ndata: %s\n\n", data); err != nil {
+			return nil
+		}
+		res.Flush()
+	}
+
+	return nil
+}
+
 // GetVideoUserByCode возвращает видео с информацией о реакциях текущего пользователя
 // @Summary Получение видео с реакциями пользователя
 // @Description Возвращает видео по коду с информацией о лайках/дислайках текущего пользователя
@@ -120,7 +547,7 @@ func (h *VideoHandler) GetVideoByCode(c echo.Context) error {
 // @Produce json
 // @Param code path string true "Код видео"
 // @Security ApiKeyAuth
-// @Success 200 {object} VideoUserResponse
+// @Success 200 {object} dto.VideoUserResponse
 // @Failure 401 {object} responses.ErrorResponse
 // @Failure 404 {object} responses.ErrorResponse
 // @Failure 500 {object} responses.ErrorResponse
@@ -132,6 +559,9 @@ func (h *VideoHandler) GetVideoUserByCode(c echo.Context) error {
 
 	video, err := h.videoService.GetVideoUserInfoByCode(ctx, code, userID)
 	if err != nil {
+		if videoAccessStatus(err) != 0 {
+			return videoAccessError(c, err)
+		}
 		return responses.Error(c, http.StatusNotFound, "Video not found")
 	}
 
@@ -240,7 +670,7 @@ func (h *VideoHandler) DislikeVideo(c echo.Context) error {
 
 // GetUserVideos возвращает список видео пользователя
 // @Summary Список видео пользователя
-// @Description Возвращает список видео конкретного пользователя с пагинацией
+// @Description Возвращает список видео пользователя с пагинацией. Владелец видит все свои видео, остальные - только опубликованные публичные
 // @Tags videos
 // @Produce json
 // @Param user_id path string true "ID пользователя"
@@ -254,7 +684,7 @@ func (h *VideoHandler) GetUserVideos(c echo.Context) error {
 	paginationParams := pagination.ExtractPaginationParams(c)
 	ctx := c.Request().Context()
 
-	videos, total, err := h.videoService.GetUserVideos(ctx, uuid.MustParse(userID), paginationParams.Page, paginationParams.Limit)
+	videos, total, err := h.videoService.GetUserVideos(ctx, uuid.MustParse(userID), viewerID(c), paginationParams.Page, paginationParams.Limit)
 	if err != nil {
 		return responses.Error(c, http.StatusInternalServerError, "Failed to get user videos")
 	}
@@ -296,28 +726,32 @@ func (h *VideoHandler) UpdateVideo(c echo.Context) error {
 		return responses.Error(c, http.StatusForbidden, "You don't have permission to update this video")
 	}
 
-	var request struct {
-		Title       string    `json:"title"`
-		Description string    `json:"description"`
-		CategoryID  uuid.UUID `json:"category_id"`
-	}
-
+	var request requests.UpdateVideoRequest
 	if err := c.Bind(&request); err != nil {
 		return responses.Error(c, http.StatusBadRequest, "Invalid request data")
 	}
+	if err := h.validator.Validate(request); err != nil {
+		return responses.Error(c, http.StatusBadRequest, err.Error())
+	}
 
-	if request.Title == "" {
-		return responses.Error(c, http.StatusBadRequest, "Title is required")
+	publishAt, err := parsePublishAt(request.Privacy, request.PublishAt)
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, err.Error())
 	}
 
 	updatedVideo, err := h.videoService.UpdateVideo(
 		ctx,
 		video.ID,
-		request.CategoryID,
+		uuid.MustParse(request.CategoryID),
 		request.Title,
 		request.Description,
+		request.Privacy,
+		publishAt,
 	)
 	if err != nil {
+		if errors.Is(err, constants.ErrInvalidVisibility) {
+			return responses.Error(c, http.StatusBadRequest, err.Error())
+		}
 		return responses.Error(c, http.StatusInternalServerError, "Failed to update video")
 	}
 
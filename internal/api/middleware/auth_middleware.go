@@ -40,3 +40,18 @@ func AuthMiddleware(jwtService *jwt.JWTService) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// OptionalAuthMiddleware создает middleware для маршрутов, доступных без авторизации.
+// Без заголовка Authorization запрос проходит анонимно, переданный токен проверяется как в AuthMiddleware
+func OptionalAuthMiddleware(jwtService *jwt.JWTService) echo.MiddlewareFunc {
+	auth := AuthMiddleware(jwtService)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withAuth := auth(next)
+		return func(c echo.Context) error {
+			if c.Request().Header.Get("Authorization") == "" {
+				return next(c)
+			}
+			return withAuth(c)
+		}
+	}
+}
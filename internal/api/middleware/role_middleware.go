@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mrkbwp/gotube/internal/domain/repositories"
+)
+
+// RoleMiddleware создает middleware, пропускающее только пользователей с одной из ролей.
+// Должно идти после AuthMiddleware
+func RoleMiddleware(userRepo repositories.UserRepository, roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, ok := c.Get("userID").(uuid.UUID)
+			if !ok {
+				return echo.NewHTTPError(401, "Unauthorized")
+			}
+
+			user, err := userRepo.GetByID(c.Request().Context(), userID.String())
+			if err != nil {
+				return echo.NewHTTPError(401, "User not found")
+			}
+
+			for _, role := range roles {
+				if user.Role == role {
+					return next(c)
+				}
+			}
+
+			return echo.NewHTTPError(403, "Insufficient permissions")
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package requests
+
+// CreateShareLinkRequest запрос на создание ссылки доступа к видео.
+// Без expires_at (RFC 3339) ссылка действует неделю, без max_views - без лимита просмотров
+type CreateShareLinkRequest struct {
+	ExpiresAt string `json:"expires_at"`
+	Password  string `json:"password" validate:"omitempty,min=4,max=72"`
+	MaxViews  *int   `json:"max_views" validate:"omitempty,min=1"`
+}
+
+// GrantAccessRequest запрос на выдачу пользователю доступа к видео
+type GrantAccessRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid"`
+}
@@ -0,0 +1,34 @@
+package requests
+
+// CreateDirectUploadRequest запрос на загрузку исходника прямо в хранилище
+type CreateDirectUploadRequest struct {
+	Filename    string `json:"filename" validate:"required,max=255"`
+	Size        int64  `json:"size" validate:"required,gt=0"`
+	ContentType string `json:"content_type" validate:"required"`
+	// Checksum - SHA-256 файла в hex, хранилище отклонит файл с другой суммой
+	Checksum    string `json:"checksum" validate:"omitempty,len=64,hexadecimal"`
+	CategoryID  string `json:"category_id" validate:"required,uuid"`
+	Title       string `json:"title" validate:"omitempty,min=3,max=100"`
+	Description string `json:"description"`
+}
+
+// CreateImportRequest запрос на импорт видео по ссылке
+type CreateImportRequest struct {
+	URL         string `json:"url" validate:"required,url,max=2048"`
+	CategoryID  string `json:"category_id" validate:"required,uuid"`
+	Title       string `json:"title" validate:"omitempty,min=3,max=100"`
+	Description string `json:"description"`
+}
+
+// BatchManifestItem сведения о видео одного файла в манифесте пакетной загрузки.
+// В CSV колонки называются так же, теги разделяются точкой с запятой
+type BatchManifestItem struct {
+	// File - имя файла в полях files или путь в архиве
+	File        string   `json:"file" validate:"required,max=255"`
+	Title       string   `json:"title" validate:"omitempty,min=3,max=100"`
+	Description string   `json:"description"`
+	CategoryID  string   `json:"category_id" validate:"required,uuid"`
+	Privacy     string   `json:"privacy" validate:"omitempty,oneof=public unlisted private scheduled"`
+	PublishAt   string   `json:"publish_at" validate:"required_if=Privacy scheduled"`
+	Tags        []string `json:"tags" validate:"max=20,dive,required,max=50"`
+}
@@ -1,13 +1,27 @@
 package requests
 
-// UpdateVideoRequest запрос на обновление информации о видео
+// UpdateVideoRequest запрос на обновление информации о видео.
+// Без privacy видимость остается прежней, для scheduled обязательно время публикации publish_at в RFC 3339
 type UpdateVideoRequest struct {
 	Title       string `json:"title" validate:"required,min=3,max=100"`
 	Description string `json:"description"`
 	CategoryID  string `json:"category_id" validate:"required,uuid"`
+	Privacy     string `json:"privacy" validate:"omitempty,oneof=public unlisted private scheduled"`
+	PublishAt   string `json:"publish_at" validate:"required_if=Privacy scheduled"`
 }
 
 // VideoIDRequest запрос с ID видео
 type VideoIDRequest struct {
 	ID string `param:"id" validate:"required,uuid"`
-}
\ No newline at end of file
+}
+
+// UploadVideoRequest поля формы загрузки видео, название по умолчанию - имя файла.
+// Теги передаются повторяющимся полем tags или через запятую
+type UploadVideoRequest struct {
+	Title       string   `json:"title" form:"title" validate:"omitempty,min=3,max=100"`
+	Description string   `json:"description" form:"description"`
+	CategoryID  string   `json:"category_id" form:"category_id" validate:"required,uuid"`
+	Privacy     string   `json:"privacy" form:"privacy" validate:"omitempty,oneof=public unlisted private scheduled"`
+	PublishAt   string   `json:"publish_at" form:"publish_at" validate:"required_if=Privacy scheduled"`
+	Tags        []string `json:"tags" form:"tags" validate:"max=20,dive,required,max=50"`
+}
@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// ConversionJob задание конвертации видео или отдельного качества.
+// Задание видео арендуется воркером, аренда продлевается heartbeat
+type ConversionJob struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	VideoID        uuid.UUID  `json:"video_id" db:"video_id"`
+	QualityID      *uuid.UUID `json:"quality_id" db:"quality_id"`
+	Status         string     `json:"status" db:"status"`
+	Attempts       int        `json:"attempts" db:"attempts"`
+	LeaseOwner     *string    `json:"lease_owner" db:"lease_owner"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at" db:"lease_expires_at"`
+	HeartbeatAt    *time.Time `json:"heartbeat_at" db:"heartbeat_at"`
+	LastError      *string    `json:"last_error" db:"last_error"`
+	FailureClass   *string    `json:"failure_class" db:"failure_class"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at" db:"next_attempt_at"`
+	DispatchedAt   *time.Time `json:"dispatched_at" db:"dispatched_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ConversionDeadLetter задание конвертации, исчерпавшее попытки
+type ConversionDeadLetter struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	JobID        uuid.UUID  `json:"job_id" db:"job_id"`
+	VideoID      uuid.UUID  `json:"video_id" db:"video_id"`
+	FailureClass string     `json:"failure_class" db:"failure_class"`
+	Error        string     `json:"error" db:"error"`
+	Attempts     int        `json:"attempts" db:"attempts"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	RedrivenAt   *time.Time `json:"redriven_at" db:"redriven_at"`
+}
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// TranscodeProfile профиль транскодирования: кодек, контейнер и настройки энкодера
+type TranscodeProfile struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	VideoCodec  string    `json:"video_codec" db:"video_codec"`
+	Container   string    `json:"container" db:"container"`
+	RateControl string    `json:"rate_control" db:"rate_control"`
+	CRF         *int      `json:"crf" db:"crf"`
+	Preset      *string   `json:"preset" db:"preset"`
+	// MaxRateRatio и BufSizeRatio - множители целевого битрейта качества
+	MaxRateRatio *float64  `json:"max_rate_ratio" db:"max_rate_ratio"`
+	BufSizeRatio *float64  `json:"buf_size_ratio" db:"buf_size_ratio"`
+	GOPSeconds   int       `json:"gop_seconds" db:"gop_seconds"`
+	AudioCodec   string    `json:"audio_codec" db:"audio_codec"`
+	AudioBitrate int       `json:"audio_bitrate" db:"audio_bitrate"`
+	IsDefault    bool      `json:"is_default" db:"is_default"`
+	IsActive     bool      `json:"is_active" db:"is_active"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"time"
+)
+
+// UploadBatch пакетная загрузка видео по манифесту
+type UploadBatch struct {
+	ID     uuid.UUID `json:"id" db:"id"`
+	UserID uuid.UUID `json:"user_id" db:"user_id"`
+	Status string    `json:"status" db:"status"`
+	Total  int       `json:"total_items" db:"total_items"`
+
+	Items []*UploadBatchItem `json:"items" db:"-"`
+
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at" db:"completed_at"`
+}
+
+// UploadBatchItem файл пакетной загрузки и сведения о видео из манифеста
+type UploadBatchItem struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	BatchID  uuid.UUID `json:"batch_id" db:"batch_id"`
+	Position int       `json:"position" db:"position"`
+	Filename string    `json:"filename" db:"filename"`
+
+	Title       string         `json:"title" db:"title"`
+	Description string         `json:"description" db:"description"`
+	CategoryID  uuid.UUID      `json:"category_id" db:"category_id"`
+	Privacy     string         `json:"privacy" db:"privacy"`
+	PublishAt   *time.Time     `json:"publish_at" db:"publish_at"`
+	Tags        pq.StringArray `json:"tags" db:"tags" swaggertype:"array,string"`
+
+	Status  string     `json:"status" db:"status"`
+	VideoID *uuid.UUID `json:"video_id" db:"video_id"`
+	// VideoCode - код созданного видео, заполняется при чтении пакета
+	VideoCode *string `json:"video_code" db:"video_code"`
+	Error     *string `json:"error" db:"error"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// UploadQuota квота загрузки роли или пользователя. nil в лимите - без ограничения
+type UploadQuota struct {
+	ID     uuid.UUID  `json:"id" db:"id"`
+	Role   *string    `json:"role,omitempty" db:"role"`
+	UserID *uuid.UUID `json:"user_id,omitempty" db:"user_id"`
+
+	MaxStorageBytes *int64 `json:"max_storage_bytes" db:"max_storage_bytes"`
+	MaxDailyUploads *int   `json:"max_daily_uploads" db:"max_daily_uploads"`
+	MaxDailyBytes   *int64 `json:"max_daily_bytes" db:"max_daily_bytes"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
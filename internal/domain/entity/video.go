@@ -6,6 +6,10 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -34,6 +38,30 @@ func (m *Metadata) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, &m)
 }
 
+// Int возвращает целое значение метаданных, после чтения из JSON числа приходят как float64
+func (m Metadata) Int(key string) int {
+	switch value := m[key].(type) {
+	case int:
+		return value
+	case float64:
+		return int(value)
+	default:
+		return 0
+	}
+}
+
+// Float возвращает дробное значение метаданных и признак его наличия
+func (m Metadata) Float(key string) (float64, bool) {
+	switch value := m[key].(type) {
+	case int:
+		return float64(value), true
+	case float64:
+		return value, true
+	default:
+		return 0, false
+	}
+}
+
 type Video struct {
 	ID          uuid.UUID `json:"id" db:"id"`
 	VideoCode   string    `json:"video_code" db:"video_code"`
@@ -41,6 +69,8 @@ type Video struct {
 	Title       string    `json:"title" db:"title"`
 	Description string    `json:"description" db:"description"`
 	CategoryID  uuid.UUID `json:"category_id" db:"category_id"`
+	// Tags - теги в нижнем регистре без повторов
+	Tags pq.StringArray `json:"tags" db:"tags" swaggertype:"array,string"`
 
 	BucketID     string `json:"bucket_id" db:"bucket_id"`
 	ShardID      string `json:"shard_id" db:"shard_id"`
@@ -55,21 +85,47 @@ type Video struct {
 	Dislikes     int    `json:"dislikes" db:"dislikes"`
 	Status       string `json:"status" db:"status"`
 
-	IsBlocked    bool       `json:"is_blocked" db:"is_blocked"`
+	// PreviewsVTTURL - WebVTT с превью кадров для полосы перемотки
+	PreviewsVTTURL *string `json:"previews_vtt_url" db:"previews_vtt_url"`
+	// PreviewClipURL - короткий ролик без звука для карточки видео
+	PreviewClipURL *string `json:"preview_clip_url" db:"preview_clip_url"`
+
+	IsBlocked bool `json:"is_blocked" db:"is_blocked"`
+	// IsPrivate выводится из Visibility при сохранении, см. IsPrivateVisibility
 	IsPrivate    bool       `json:"is_private" db:"is_private"`
+	Visibility   string     `json:"visibility" db:"visibility"`
+	PublishAt    *time.Time `json:"publish_at" db:"publish_at"`
 	ProcessedAt  *time.Time `json:"processed_at" db:"processed_at"`
 	ErrorMessage *string    `json:"error_message" db:"error_message"`
 
 	Metadata         Metadata `json:"metadata" db:"metadata"`
 	OriginalFilename string   `json:"original_filename" db:"original_filename"`
 
+	// Сведения о загруженном исходнике: размер, MIME тип и SHA-256
+	OriginalSize        int64  `json:"original_size" db:"original_size"`
+	OriginalContentType string `json:"original_content_type" db:"original_content_type"`
+	OriginalChecksum    string `json:"original_checksum" db:"original_checksum"`
+
 	Files []*VideoFile `json:"video_files" db:"-"`
+	// Subtitles - дорожки субтитров, заполняются только для страницы видео
+	Subtitles []*VideoSubtitle `json:"subtitles,omitempty" db:"-"`
 
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
 	DeletedAt *time.Time `json:"deleted_at" db:"deleted_at,noi"`
 }
 
+// IsPrivateVisibility сообщает, что видео с такой видимостью закрыто для посторонних:
+// private и scheduled до публикации
+func IsPrivateVisibility(visibility string) bool {
+	return visibility == constants.VideoVisibilityPrivate || visibility == constants.VideoVisibilityScheduled
+}
+
+// SourceSize возвращает размеры кадра исходника из метаданных, 0, 0 если видео еще не анализировалось
+func (v *Video) SourceSize() (width, height int) {
+	return v.Metadata.Int(constants.MetadataWidth), v.Metadata.Int(constants.MetadataHeight)
+}
+
 // GetStoragePath возвращает полный путь к папке в хранилище
 func (v *Video) GetStoragePath(quality string) string {
 	return v.ShardID + "/" +
@@ -82,3 +138,68 @@ func (v *Video) GetStoragePath(quality string) string {
 func (v *Video) GetStorageFilePath(quality string) string {
 	return v.GetStoragePath(quality) + "/" + v.Filename
 }
+
+// GetRenditionFilePath возвращает путь к файлу качества в профиле транскодирования. Расширение всегда
+// берется из контейнера профиля, а не из исходника, иначе тип файла в хранилище определился бы по исходнику.
+// Имя файла профиля по умолчанию без суффикса профиля, его нарезают в HLS и DASH
+func (v *Video) GetRenditionFilePath(quality string, profile *TranscodeProfile) string {
+	name := strings.TrimSuffix(v.Filename, filepath.Ext(v.Filename))
+	if !profile.IsDefault {
+		name += "_" + profile.Name
+	}
+
+	return v.GetStoragePath(quality) + "/" + name + "." + profile.Container
+}
+
+// GetAudioFilePath возвращает путь к звуковой дорожке без видео на языке language в контейнере container.
+// Дорожка без указанного языка лежит без суффикса языка
+func (v *Video) GetAudioFilePath(language, container string) string {
+	name := strings.TrimSuffix(v.Filename, filepath.Ext(v.Filename))
+	if language != "" {
+		name += "_" + language
+	}
+
+	return v.GetStoragePath(constants.VideoQualityAudio) + "/" + name + "." + container
+}
+
+// GetSubtitlePath возвращает путь к WebVTT субтитров языка в бакете видео
+func (v *Video) GetSubtitlePath(language string) string {
+	return v.GetStoragePath(constants.SubtitlesStoragePath) + "/" +
+		strings.TrimSuffix(v.Filename, filepath.Ext(v.Filename)) + "_" + language + ".vtt"
+}
+
+// GetThumbnailsPath возвращает путь к папке с вариантами обложки в бакете миниатюр
+func (v *Video) GetThumbnailsPath() string {
+	return v.ShardID + "/" +
+		v.PathSegment1 + "/" +
+		v.PathSegment2 + "/" +
+		strings.TrimSuffix(v.Filename, filepath.Ext(v.Filename)) + "_thumbnails"
+}
+
+// GetPreviewClipPath возвращает путь к превью-ролику в бакете миниатюр, рядом с обложкой
+func (v *Video) GetPreviewClipPath() string {
+	return v.ShardID + "/" +
+		v.PathSegment1 + "/" +
+		v.PathSegment2 + "/" +
+		strings.TrimSuffix(v.Filename, filepath.Ext(v.Filename)) + constants.PreviewClipSuffix
+}
+
+// GetPreviewsPath возвращает путь к папке со спрайтами превью в бакете миниатюр
+func (v *Video) GetPreviewsPath() string {
+	return v.ShardID + "/" +
+		v.PathSegment1 + "/" +
+		v.PathSegment2 + "/" +
+		strings.TrimSuffix(v.Filename, filepath.Ext(v.Filename)) + "_previews"
+}
+
+// GetHLSPath возвращает путь к папке с HLS плейлистами и сегментами видео
+func (v *Video) GetHLSPath() string {
+	return v.GetStoragePath(constants.StreamingFormatHLS) + "/" +
+		strings.TrimSuffix(v.Filename, filepath.Ext(v.Filename))
+}
+
+// GetDASHPath возвращает путь к папке с DASH манифестом и сегментами видео
+func (v *Video) GetDASHPath() string {
+	return v.GetStoragePath(constants.StreamingFormatDASH) + "/" +
+		strings.TrimSuffix(v.Filename, filepath.Ext(v.Filename))
+}
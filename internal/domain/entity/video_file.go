@@ -6,13 +6,20 @@ import (
 )
 
 type VideoFile struct {
-	ID          uuid.UUID `json:"id" db:"id,noi"`
-	VideoID     uuid.UUID `json:"video_id" db:"video_id"`
-	QualityID   uuid.UUID `json:"quality_id" db:"quality_id"`
-	QualityName string    `json:"quality" db:"quality_name"`
-	Format      string    `json:"format" db:"file_format"`
+	ID          uuid.UUID  `json:"id" db:"id,noi"`
+	VideoID     uuid.UUID  `json:"video_id" db:"video_id"`
+	QualityID   uuid.UUID  `json:"quality_id" db:"quality_id"`
+	QualityName string     `json:"quality" db:"quality_name"`
+	Format      string     `json:"format" db:"file_format"`
+	Codec       string     `json:"codec" db:"codec"`
+	ProfileID   *uuid.UUID `json:"profile_id" db:"profile_id"`
+	// Language - язык звуковой дорожки, пустой если не указан
+	Language string `json:"language,omitempty" db:"language"`
+	// StoragePath - путь в хранилище, у файлов до появления профилей пустой
+	StoragePath *string   `json:"-" db:"storage_path"`
 	URL         string    `json:"url" db:"-"`
 	FileSize    int64     `json:"file_size" db:"file_size"`
+	Checksum    string    `json:"checksum,omitempty" db:"checksum"`
 	Width       int       `json:"width" db:"width"`
 	Height      int       `json:"height" db:"height"`
 	Bitrate     int       `json:"bitrate" db:"bitrate"`
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// VideoImport импорт исходника видео по ссылке
+type VideoImport struct {
+	ID      uuid.UUID `json:"id" db:"id"`
+	VideoID uuid.UUID `json:"video_id" db:"video_id"`
+	UserID  uuid.UUID `json:"user_id" db:"user_id"`
+	// VideoCode - код созданного видео, заполняется при создании импорта
+	VideoCode string `json:"video_code,omitempty" db:"-"`
+	SourceURL string `json:"source_url" db:"source_url"`
+
+	Status   string  `json:"status" db:"status"`
+	Attempts int     `json:"attempts" db:"attempts"`
+	Error    *string `json:"error,omitempty" db:"error"`
+	Size     int64   `json:"size" db:"size"`
+
+	LeaseExpiresAt *time.Time `json:"-" db:"lease_expires_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"github.com/google/uuid"
+	"math"
 	"time"
 )
 
@@ -15,3 +16,54 @@ type VideoQuality struct {
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
 }
+
+// SelectRenditions возвращает качества не выше разрешения исходника с размерами, сохраняющими его пропорции.
+// Разрешение сравнивается по короткой стороне, чтобы вертикальное 1080x1920 получило 1080p.
+// Если исходник меньше всех качеств, остается самое низкое в размере исходника.
+// Без размеров исходника качества возвращаются как есть
+func SelectRenditions(qualities []*VideoQuality, sourceWidth, sourceHeight int) []*VideoQuality {
+	if sourceWidth <= 0 || sourceHeight <= 0 || len(qualities) == 0 {
+		return qualities
+	}
+
+	shortSide := min(sourceWidth, sourceHeight)
+
+	var renditions []*VideoQuality
+	for _, quality := range qualities {
+		if quality.Height > shortSide {
+			continue
+		}
+		renditions = append(renditions, quality.ScaleToSource(sourceWidth, sourceHeight))
+	}
+
+	if len(renditions) == 0 {
+		renditions = append(renditions, qualities[0].ScaleToSource(sourceWidth, sourceHeight))
+	}
+
+	return renditions
+}
+
+// ScaleToSource возвращает копию качества, у которой короткая сторона равна высоте качества
+// (но не больше исходника), а длинная посчитана по пропорциям исходника
+func (q *VideoQuality) ScaleToSource(sourceWidth, sourceHeight int) *VideoQuality {
+	rendition := *q
+	if sourceWidth <= 0 || sourceHeight <= 0 {
+		return &rendition
+	}
+
+	shortSide := min(q.Height, sourceWidth, sourceHeight)
+	if sourceWidth >= sourceHeight {
+		rendition.Height = evenSize(float64(shortSide))
+		rendition.Width = evenSize(float64(sourceWidth) * float64(shortSide) / float64(sourceHeight))
+	} else {
+		rendition.Width = evenSize(float64(shortSide))
+		rendition.Height = evenSize(float64(sourceHeight) * float64(shortSide) / float64(sourceWidth))
+	}
+
+	return &rendition
+}
+
+// evenSize округляет размер до четного, libx264 с yuv420p не принимает нечетные размеры
+func evenSize(size float64) int {
+	return max(int(math.Round(size/2))*2, 2)
+}
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// VideoShareLink ссылка доступа к неопубликованному видео
+type VideoShareLink struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	VideoID   uuid.UUID `json:"video_id" db:"video_id"`
+	CreatedBy uuid.UUID `json:"created_by" db:"created_by"`
+	// Token и HasPassword заполняются сервисом
+	Token       string `json:"token" db:"-"`
+	HasPassword bool   `json:"has_password" db:"-"`
+
+	PasswordHash *string `json:"-" db:"password_hash"`
+	MaxViews     *int    `json:"max_views,omitempty" db:"max_views"`
+	Views        int     `json:"views" db:"views"`
+
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// VideoAccessGrant доступ к неопубликованному видео, выданный пользователю
+type VideoAccessGrant struct {
+	VideoID   uuid.UUID `json:"video_id" db:"video_id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	GrantedBy uuid.UUID `json:"granted_by" db:"granted_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// VideoSubtitle дорожка субтитров видео в WebVTT
+type VideoSubtitle struct {
+	ID      uuid.UUID `json:"id" db:"id"`
+	VideoID uuid.UUID `json:"video_id" db:"video_id"`
+	// Language - язык в формате BCP 47
+	Language string `json:"language" db:"language"`
+	Label    string `json:"label" db:"label"`
+	// Source - upload или embedded
+	Source      string    `json:"source" db:"source"`
+	StoragePath string    `json:"-" db:"storage_path"`
+	URL         string    `json:"url" db:"-"`
+	CuesCount   int       `json:"cues_count" db:"cues_count"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
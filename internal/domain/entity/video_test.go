@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/mrkbwp/gotube/pkg/constants"
+)
+
+func TestVideoGetRenditionFilePath(t *testing.T) {
+	video := &Video{ShardID: "7", PathSegment1: "17", PathSegment2: "00", Filename: "1700000000_abcd.mov"}
+
+	tests := []struct {
+		name    string
+		profile TranscodeProfile
+		want    string
+	}{
+		{name: "default profile", profile: TranscodeProfile{Name: "h264", Container: constants.ContainerMP4, IsDefault: true}, want: "7/17/00/720p/1700000000_abcd.mp4"},
+		{name: "additional profile", profile: TranscodeProfile{Name: "vp9", Container: constants.ContainerWebM}, want: "7/17/00/720p/1700000000_abcd_vp9.webm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := video.GetRenditionFilePath("720p", &tt.profile); got != tt.want {
+				t.Errorf("GetRenditionFilePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPrivateVisibility(t *testing.T) {
+	tests := []struct {
+		visibility string
+		want       bool
+	}{
+		{visibility: constants.VideoVisibilityPublic, want: false},
+		{visibility: constants.VideoVisibilityUnlisted, want: false},
+		{visibility: constants.VideoVisibilityPrivate, want: true},
+		{visibility: constants.VideoVisibilityScheduled, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.visibility, func(t *testing.T) {
+			if got := IsPrivateVisibility(tt.visibility); got != tt.want {
+				t.Errorf("IsPrivateVisibility(%q) = %v, want %v", tt.visibility, got, tt.want)
+			}
+		})
+	}
+}
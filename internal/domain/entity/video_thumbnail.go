@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// VideoThumbnail вариант обложки видео
+type VideoThumbnail struct {
+	ID      uuid.UUID `json:"id" db:"id"`
+	VideoID uuid.UUID `json:"video_id" db:"video_id"`
+	// Source - frame, scene или custom
+	Source string `json:"source" db:"source"`
+	// Position - секунда кадра в видео, для загруженных обложек пустая
+	Position   *float64  `json:"position" db:"position_seconds"`
+	URL        string    `json:"url" db:"url"`
+	Sizes      Metadata  `json:"sizes,omitempty" db:"sizes"`
+	IsSelected bool      `json:"is_selected" db:"is_selected"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
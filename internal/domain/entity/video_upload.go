@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"time"
+)
+
+// VideoUpload загрузка исходника видео: возобновляемая (tus) или формой прямо в хранилище
+type VideoUpload struct {
+	ID      uuid.UUID `json:"id" db:"id"`
+	UserID  uuid.UUID `json:"user_id" db:"user_id"`
+	VideoID uuid.UUID `json:"video_id" db:"video_id"`
+	Kind    string    `json:"kind" db:"kind"`
+	// VideoCode - код созданного видео, заполняется при создании загрузки
+	VideoCode string `json:"video_code,omitempty" db:"-"`
+
+	Length   int64    `json:"upload_length" db:"upload_length"`
+	Offset   int64    `json:"upload_offset" db:"upload_offset"`
+	Metadata Metadata `json:"metadata" db:"metadata"`
+
+	StorageUploadID string      `json:"-" db:"storage_upload_id"`
+	Parts           UploadParts `json:"-" db:"parts"`
+	TailSize        int64       `json:"-" db:"tail_size"`
+	HashState       []byte      `json:"-" db:"hash_state"`
+
+	Status    string    `json:"status" db:"status"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UploadPart загруженная часть составной загрузки
+type UploadPart struct {
+	Number int    `json:"number"`
+	ETag   string `json:"etag"`
+	Size   int64  `json:"size"`
+}
+
+type UploadParts []UploadPart
+
+// Value implements the driver.Valuer interface
+func (p UploadParts) Value() (driver.Value, error) {
+	if p == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(p)
+}
+
+// Scan implements the sql.Scanner interface
+func (p *UploadParts) Scan(value interface{}) error {
+	if value == nil {
+		*p = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(bytes, p)
+}
+
+// IsExpired проверяет, истек ли срок незавершенной загрузки
+func (u *VideoUpload) IsExpired() bool {
+	return u.Status == constants.UploadStatusUploading && time.Now().After(u.ExpiresAt)
+}
+
+// GetTailPath возвращает путь к хвосту загрузки в бакете незавершенных загрузок
+func (u *VideoUpload) GetTailPath() string {
+	return constants.UploadsStoragePath + "/" + u.ID.String() + ".tail"
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+)
+
+// ConversionJobRepository определяет интерфейс для работы с заданиями конвертации
+type ConversionJobRepository interface {
+	// EnqueueUploadedVideos создает задания для загруженных видео, у которых их еще нет
+	EnqueueUploadedVideos(ctx context.Context) (int64, error)
+
+	// EnqueueVideo создает задание для загруженного видео, если его еще нет
+	EnqueueVideo(ctx context.Context, videoID uuid.UUID) error
+
+	// AcquireVideoJob арендует ожидающее задание конкретного видео, ErrNotFound если его нет
+	AcquireVideoJob(ctx context.Context, videoID uuid.UUID, owner string, lease time.Duration) (*entity.ConversionJob, error)
+
+	// AcquireVideoJobs арендует ожидающие задания видео для воркера
+	AcquireVideoJobs(ctx context.Context, owner string, lease time.Duration, limit int) ([]*entity.ConversionJob, error)
+
+	// Heartbeat продлевает аренду задания, ErrNotFound если аренда потеряна
+	Heartbeat(ctx context.Context, jobID uuid.UUID, owner string, lease time.Duration) error
+
+	// ReleaseJob досрочно возвращает арендованное задание в очередь
+	ReleaseJob(ctx context.Context, jobID uuid.UUID, owner string) error
+
+	// RequeueExpired возвращает в очередь задания с просроченной арендой,
+	// задания с исчерпанными попытками не трогает и возвращает для отправки в dead letter
+	RequeueExpired(ctx context.Context, maxAttempts int) (int64, []*entity.ConversionJob, error)
+
+	// DispatchDueJobs отмечает отправленными ожидающие задания видео, которым пора выполняться
+	// и которые не отправлялись или отправлены раньше redispatchAfter назад
+	DispatchDueJobs(ctx context.Context, redispatchAfter time.Duration, limit int) ([]*entity.ConversionJob, error)
+
+	// RetryJob возвращает арендованное задание в очередь с паузой до nextAttemptAt, ErrNotFound если аренда потеряна
+	RetryJob(ctx context.Context, jobID uuid.UUID, owner, failureClass, lastError string, nextAttemptAt time.Time) error
+
+	// DeadLetterJob помечает выполняемое задание ошибкой и сохраняет его в dead letter,
+	// ErrNotFound если задание уже не выполняется
+	DeadLetterJob(ctx context.Context, jobID uuid.UUID, failureClass, lastError string) (*entity.ConversionDeadLetter, error)
+
+	// GetDeadLetters возвращает не перезапущенные dead letter с пагинацией
+	GetDeadLetters(ctx context.Context, page, limit int) ([]*entity.ConversionDeadLetter, int64, error)
+
+	// RedriveDeadLetter сбрасывает попытки задания и возвращает его в очередь
+	RedriveDeadLetter(ctx context.Context, id uuid.UUID) (*entity.ConversionDeadLetter, error)
+
+	// GetQualityJobs возвращает задания качеств видео
+	GetQualityJobs(ctx context.Context, videoID uuid.UUID) ([]*entity.ConversionJob, error)
+
+	// StartQualityJob создает или перезапускает задание качества и берет его в аренду на lease
+	StartQualityJob(ctx context.Context, videoID, qualityID uuid.UUID, owner string, lease time.Duration) (*entity.ConversionJob, error)
+
+	// CompleteJob помечает арендованное задание выполненным, ErrNotFound если аренда потеряна
+	CompleteJob(ctx context.Context, jobID uuid.UUID, owner string) error
+
+	// FailJob помечает арендованное задание ошибкой, ErrNotFound если аренда потеряна
+	FailJob(ctx context.Context, jobID uuid.UUID, owner, lastError string) error
+}
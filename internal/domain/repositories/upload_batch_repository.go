@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+)
+
+// UploadBatchRepository определяет интерфейс для работы с пакетными загрузками
+type UploadBatchRepository interface {
+	// Create создает пакет вместе с файлами
+	Create(ctx context.Context, batch *entity.UploadBatch) error
+
+	// GetByID возвращает пакет с файлами
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.UploadBatch, error)
+
+	// UpdateItem сохраняет статус, видео и ошибку файла и отмечает изменение пакета
+	UpdateItem(ctx context.Context, item *entity.UploadBatchItem) error
+
+	// Complete отмечает пакет завершенным
+	Complete(ctx context.Context, id uuid.UUID) error
+
+	// FailStale завершает пакеты, не менявшиеся с before, их необработанные файлы получают ошибку message.
+	// Возвращает число таких файлов
+	FailStale(ctx context.Context, before time.Time, message string) (int64, error)
+}
@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+)
+
+// UploadQuotaRepository определяет интерфейс для работы с квотами загрузки
+type UploadQuotaRepository interface {
+	// GetQuota возвращает индивидуальную квоту пользователя, а если ее нет - квоту роли.
+	// ErrNotFound, если не задана ни та, ни другая
+	GetQuota(ctx context.Context, userID uuid.UUID, role string) (*entity.UploadQuota, error)
+
+	// GetStorageUsage возвращает суммарный размер исходников видео пользователя и незавершенных загрузок
+	GetStorageUsage(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// ReserveDailyUpload учитывает загрузку size байт в текущих сутках UTC, если она укладывается в лимиты.
+	// ErrDailyUploadQuotaExceeded, если не укладывается
+	ReserveDailyUpload(ctx context.Context, userID uuid.UUID, size int64, maxUploads *int, maxBytes *int64) error
+
+	// ReserveDailyBytes добавляет size байт к уже учтенной в текущих сутках UTC загрузке, если они укладываются в лимит.
+	// ErrDailyUploadQuotaExceeded, если не укладываются
+	ReserveDailyBytes(ctx context.Context, userID uuid.UUID, size int64, maxBytes *int64) error
+}
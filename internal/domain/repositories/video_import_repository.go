@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+)
+
+// VideoImportRepository определяет интерфейс для работы с импортом видео по ссылке
+type VideoImportRepository interface {
+	// Create создает импорт в статусе pending
+	Create(ctx context.Context, videoImport *entity.VideoImport) error
+
+	// GetByVideoID возвращает импорт видео
+	GetByVideoID(ctx context.Context, videoID uuid.UUID) (*entity.VideoImport, error)
+
+	// Acquire арендует до limit ожидающих импортов или импортов с истекшей арендой, которые брались меньше maxAttempts раз
+	Acquire(ctx context.Context, lease time.Duration, maxAttempts, limit int) ([]*entity.VideoImport, error)
+
+	// FailAbandoned переводит в failed импорты с истекшей арендой, исчерпавшие попытки, и возвращает их
+	FailAbandoned(ctx context.Context, maxAttempts int, message string) ([]*entity.VideoImport, error)
+
+	// Complete отмечает импорт завершенным
+	Complete(ctx context.Context, id uuid.UUID, size int64) error
+
+	// Fail отмечает импорт неудавшимся
+	Fail(ctx context.Context, id uuid.UUID, message string) error
+}
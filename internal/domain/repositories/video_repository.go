@@ -23,12 +23,19 @@ type VideoRepository interface {
 	// GetPopularVideos возвращает список популярных видео с пагинацией
 	GetPopularVideos(ctx context.Context, page, limit int) ([]*entity.Video, int64, error)
 
-	// GetUserVideos возвращает видео пользователя с пагинацией
-	GetUserVideos(ctx context.Context, userID uuid.UUID, page, limit int) ([]*entity.Video, int64, error)
+	// GetUserVideos возвращает видео пользователя с пагинацией, listedOnly - только публичные
+	GetUserVideos(ctx context.Context, userID uuid.UUID, listedOnly bool, page, limit int) ([]*entity.Video, int64, error)
 
-	// Update обновляет информацию о видео
+	// Update обновляет название, описание, категорию и видимость видео
 	Update(ctx context.Context, video *entity.Video) error
 
+	// PublishScheduled делает публичными запланированные видео, время публикации которых наступило, и возвращает их число
+	PublishScheduled(ctx context.Context) (int64, error)
+
+	// CompleteUpload сохраняет сведения об исходнике и переводит видео из uploading или importing в uploaded,
+	// ErrNotFound если видео не ожидает загрузки
+	CompleteUpload(ctx context.Context, video *entity.Video) error
+
 	// Delete помечает видео как удаленное
 	Delete(ctx context.Context, id uuid.UUID) error
 
@@ -56,12 +63,30 @@ type VideoRepository interface {
 	// GetVideoQualities получение списка качеств видео
 	GetVideoQualities(ctx context.Context) ([]*entity.VideoQuality, error)
 
+	// GetVideoQualityByName получение качества по имени, включая неактивные служебные
+	GetVideoQualityByName(ctx context.Context, name string) (*entity.VideoQuality, error)
+
 	// UpdateStatus обновление статуса видео
 	UpdateStatus(ctx context.Context, videoID uuid.UUID, status string) error
 
+	// UpdateErrorMessage сохранение текста ошибки обработки, nil очищает ошибку
+	UpdateErrorMessage(ctx context.Context, videoID uuid.UUID, message *string) error
+
+	// GetTranscodeProfiles получение активных профилей транскодирования, профиль по умолчанию первый
+	GetTranscodeProfiles(ctx context.Context) ([]*entity.TranscodeProfile, error)
+
 	// CreateVideoFile добавление ссылки на видео в качестве
 	CreateVideoFile(ctx context.Context, file *entity.VideoFile) error
 
+	// UpdateMetadata дополняет метаданные видео, существующие ключи перезаписываются
+	UpdateMetadata(ctx context.Context, videoID uuid.UUID, metadata entity.Metadata) error
+
+	// UpdatePreviewsVTT сохранение ссылки на WebVTT с превью для перемотки
+	UpdatePreviewsVTT(ctx context.Context, videoID uuid.UUID, url string) error
+
+	// UpdatePreviewClip сохранение ссылки на превью-ролик для карточки видео
+	UpdatePreviewClip(ctx context.Context, videoID uuid.UUID, url string) error
+
 	// UpdateThumbnailAndDuration обновляем картинку и длительность
 	UpdateThumbnailAndDuration(ctx context.Context, videoID uuid.UUID, thumbnailURL string, duration int) error
 
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+)
+
+// VideoShareRepository определяет интерфейс для работы со ссылками доступа и выданным доступом к видео
+type VideoShareRepository interface {
+	// CreateLink создает ссылку доступа
+	CreateLink(ctx context.Context, link *entity.VideoShareLink) error
+
+	// GetLink возвращает ссылку доступа по ID
+	GetLink(ctx context.Context, id uuid.UUID) (*entity.VideoShareLink, error)
+
+	// GetVideoLinks возвращает ссылки доступа видео, новые первыми
+	GetVideoLinks(ctx context.Context, videoID uuid.UUID) ([]*entity.VideoShareLink, error)
+
+	// RevokeLink отзывает ссылку доступа видео, ErrNotFound - если ссылки нет или она уже отозвана
+	RevokeLink(ctx context.Context, videoID, id uuid.UUID) error
+
+	// CountLinkView увеличивает счетчик просмотров ссылки, ErrNotFound - если лимит просмотров исчерпан
+	CountLinkView(ctx context.Context, id uuid.UUID) error
+
+	// CreateGrant выдает пользователю доступ к видео, повторная выдача не меняет запись
+	CreateGrant(ctx context.Context, grant *entity.VideoAccessGrant) error
+
+	// HasGrant проверяет, что пользователю выдан доступ к видео
+	HasGrant(ctx context.Context, videoID, userID uuid.UUID) (bool, error)
+
+	// GetVideoGrants возвращает доступы, выданные к видео
+	GetVideoGrants(ctx context.Context, videoID uuid.UUID) ([]*entity.VideoAccessGrant, error)
+
+	// DeleteGrant отзывает доступ пользователя к видео, ErrNotFound - если доступ не выдавался
+	DeleteGrant(ctx context.Context, videoID, userID uuid.UUID) error
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+)
+
+// VideoSubtitleRepository определяет интерфейс для работы с субтитрами видео
+type VideoSubtitleRepository interface {
+	// GetByVideoID возвращает субтитры видео
+	GetByVideoID(ctx context.Context, videoID uuid.UUID) ([]*entity.VideoSubtitle, error)
+
+	// Upsert сохраняет субтитры, дорожка того же языка заменяется. Извлеченные из контейнера субтитры
+	// не заменяют загруженные владельцем, в этом случае возвращается ErrAlreadyExists
+	Upsert(ctx context.Context, subtitle *entity.VideoSubtitle) error
+
+	// Delete удаляет субтитры языка и возвращает удаленную запись
+	Delete(ctx context.Context, videoID uuid.UUID, language string) (*entity.VideoSubtitle, error)
+}
@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+)
+
+// VideoThumbnailRepository определяет интерфейс для работы с вариантами обложки
+type VideoThumbnailRepository interface {
+	// GetByVideoID возвращает варианты обложки видео
+	GetByVideoID(ctx context.Context, videoID uuid.UUID) ([]*entity.VideoThumbnail, error)
+
+	// ReplaceGenerated заменяет кадры-кандидаты видео. Выбранный кандидат становится обложкой,
+	// только если владелец не выбрал свою. Возвращает ссылку на текущую обложку
+	ReplaceGenerated(ctx context.Context, videoID uuid.UUID, thumbnails []*entity.VideoThumbnail) (string, error)
+
+	// Create добавляет вариант обложки, выбранный вариант становится обложкой видео
+	Create(ctx context.Context, thumbnail *entity.VideoThumbnail) error
+
+	// Select делает вариант обложкой видео, ErrNotFound если у видео нет такого варианта
+	Select(ctx context.Context, videoID, thumbnailID uuid.UUID) (*entity.VideoThumbnail, error)
+}
@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+)
+
+// VideoUploadRepository определяет интерфейс для работы с возобновляемыми загрузками
+type VideoUploadRepository interface {
+	// Create создает загрузку
+	Create(ctx context.Context, upload *entity.VideoUpload) error
+
+	// GetByID возвращает загрузку по ID
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.VideoUpload, error)
+
+	// UpdateProgress сохраняет смещение, части, хвост и состояние контрольной суммы незавершенной загрузки
+	UpdateProgress(ctx context.Context, upload *entity.VideoUpload) error
+
+	// UpdateStatus меняет статус незавершенной загрузки, ErrNotFound если она уже завершена
+	UpdateStatus(ctx context.Context, id uuid.UUID, status string) error
+
+	// GetExpired возвращает незавершенные загрузки с истекшим сроком
+	GetExpired(ctx context.Context, limit int) ([]*entity.VideoUpload, error)
+
+	// Lock берет блокировку загрузки на время записи куска, ErrUploadLocked если она занята.
+	// Блокировка снимается вызовом unlock или при обрыве соединения с базой
+	Lock(ctx context.Context, id uuid.UUID) (unlock func(), err error)
+}
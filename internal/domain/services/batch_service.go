@@ -0,0 +1,25 @@
+package services
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"mime/multipart"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+)
+
+// BatchService определяет интерфейс пакетной загрузки видео
+type BatchService interface {
+	// CreateBatch принимает файлы пакета из оставшихся частей files: один ZIP в поле archive или файлы в полях files.
+	// Каждому элементу items должен соответствовать файл с тем же именем. Видео загружаются по очереди в фоне
+	CreateBatch(ctx context.Context, userID uuid.UUID, items []*entity.UploadBatchItem, files *multipart.Reader) (*entity.UploadBatch, error)
+
+	// GetBatch возвращает пакет пользователя с состоянием файлов
+	GetBatch(ctx context.Context, id, userID uuid.UUID) (*entity.UploadBatch, error)
+
+	// StartBatchProcessing запускает периодическое завершение прерванных пакетов
+	StartBatchProcessing()
+
+	// StopBatchProcessing прерывает обрабатываемые пакеты после текущего файла и ждет их
+	StopBatchProcessing()
+}
@@ -2,11 +2,19 @@ package services
 
 import (
 	"context"
+	"github.com/google/uuid"
 	"github.com/mrkbwp/gotube/internal/domain/entity"
 )
 
 type ConversionService interface {
 	StartConversionQueue()
 	StopConversionQueue()
+	// StartLeaseReaper возвращает в очередь задания упавших воркеров и отправляет их через Kafka,
+	// сами задания не выполняет
+	StartLeaseReaper()
+	StopLeaseReaper()
 	ConvertVideo(ctx context.Context, video *entity.Video, quality *entity.VideoQuality) error
+	ProcessVideo(ctx context.Context, videoID uuid.UUID) error
+	GetDeadLetters(ctx context.Context, page, limit int) ([]*entity.ConversionDeadLetter, int64, error)
+	RedriveDeadLetter(ctx context.Context, id uuid.UUID) error
 }
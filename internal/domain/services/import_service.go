@@ -0,0 +1,23 @@
+package services
+
+import (
+	"context"
+	"github.com/google/uuid"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+)
+
+// ImportService определяет интерфейс импорта видео по ссылке
+type ImportService interface {
+	// CreateImport проверяет ссылку и квоты, создает видео в статусе importing и ставит скачивание в очередь
+	CreateImport(ctx context.Context, userID, categoryID uuid.UUID, sourceURL, title, description string) (*entity.VideoImport, error)
+
+	// GetImport возвращает импорт видео
+	GetImport(ctx context.Context, video *entity.Video) (*entity.VideoImport, error)
+
+	// StartImportQueue запускает фоновое скачивание
+	StartImportQueue()
+
+	// StopImportQueue останавливает фоновое скачивание
+	StopImportQueue()
+}
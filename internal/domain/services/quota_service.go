@@ -0,0 +1,21 @@
+package services
+
+import (
+	"context"
+	"github.com/google/uuid"
+)
+
+// QuotaService определяет интерфейс ограничений загрузки видео
+type QuotaService interface {
+	// CheckUpload проверяет размер исходника и квоты пользователя и учитывает загрузку в суточной квоте.
+	// size - размер исходника или 0, если он неизвестен и будет проверен при загрузке.
+	// Ошибки: ErrFileTooLarge, ErrStorageQuotaExceeded, ErrDailyUploadQuotaExceeded
+	CheckUpload(ctx context.Context, userID uuid.UUID, size int64) error
+
+	// CheckUploadSize проверяет размер исходника, который стал известен после CheckUpload с нулевым размером,
+	// по лимиту файла и квотам хранилища и суточного объема. Загрузка уже учтена, добавляются только байты
+	CheckUploadSize(ctx context.Context, userID uuid.UUID, size int64) error
+
+	// MaxFileSize возвращает максимальный размер исходника в байтах
+	MaxFileSize() int64
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"github.com/google/uuid"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/dto"
+)
+
+// ShareService определяет интерфейс ссылок доступа и выдачи доступа к неопубликованным видео
+type ShareService interface {
+	// CreateShareLink создает подписанную ссылку доступа к видео. Без срока ссылка действует DefaultShareLinkTTL,
+	// срок в прошлом или дальше MaxShareLinkTTL - ErrInvalidShareExpiry
+	CreateShareLink(ctx context.Context, video *entity.Video, userID uuid.UUID, options dto.ShareLinkOptions) (*entity.VideoShareLink, error)
+
+	// GetShareLinks возвращает ссылки доступа видео с токенами
+	GetShareLinks(ctx context.Context, video *entity.Video) ([]*entity.VideoShareLink, error)
+
+	// RevokeShareLink отзывает ссылку доступа, ErrShareLinkNotFound - если ссылки нет или она уже отозвана
+	RevokeShareLink(ctx context.Context, video *entity.Video, linkID uuid.UUID) error
+
+	// GrantAccess выдает пользователю доступ к видео, ErrNotFound - если пользователя нет
+	GrantAccess(ctx context.Context, video *entity.Video, userID, grantedBy uuid.UUID) (*entity.VideoAccessGrant, error)
+
+	// GetAccessGrants возвращает доступы, выданные к видео
+	GetAccessGrants(ctx context.Context, video *entity.Video) ([]*entity.VideoAccessGrant, error)
+
+	// RevokeAccess отзывает доступ пользователя к видео, ErrAccessGrantNotFound - если доступ не выдавался
+	RevokeAccess(ctx context.Context, video *entity.Video, userID uuid.UUID) error
+
+	// CheckAccess проверяет выданный зрителю доступ или его ссылку. Без них возвращает ErrVideoPrivate,
+	// у исчерпанной ссылки - ErrShareViewLimitReached. countView списывает просмотр ссылки с лимитом,
+	// если для IP зрителя он еще не списан в пределах ShareViewWindow
+	CheckAccess(ctx context.Context, video *entity.Video, viewer dto.VideoViewer, countView bool) error
+
+	// UnlockShareLink обменивает пароль ссылки на короткоживущий токен зрителя. Неверные пароли ограничены
+	// по ссылке и IP, после исчерпания попыток - ErrTooManyShareAttempts
+	UnlockShareLink(ctx context.Context, video *entity.Video, viewer dto.VideoViewer) (*dto.ShareViewerToken, error)
+}
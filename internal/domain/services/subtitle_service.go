@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+	"io"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+)
+
+// SubtitleService определяет интерфейс для работы с субтитрами видео
+type SubtitleService interface {
+	// GetSubtitles возвращает субтитры видео с временными ссылками на WebVTT
+	GetSubtitles(ctx context.Context, video *entity.Video) ([]*entity.VideoSubtitle, error)
+
+	// UploadSubtitle проверяет SRT или WebVTT, сохраняет его в WebVTT и заменяет дорожку того же языка
+	UploadSubtitle(ctx context.Context, video *entity.Video, language, label string, file io.Reader) (*entity.VideoSubtitle, error)
+
+	// DeleteSubtitle удаляет дорожку субтитров языка
+	DeleteSubtitle(ctx context.Context, video *entity.Video, language string) error
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"io"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+)
+
+// ThumbnailService определяет интерфейс для работы с обложками видео
+type ThumbnailService interface {
+	// GetThumbnails возвращает варианты обложки видео
+	GetThumbnails(ctx context.Context, video *entity.Video) ([]*entity.VideoThumbnail, error)
+
+	// SelectThumbnail делает вариант обложкой видео
+	SelectThumbnail(ctx context.Context, video *entity.Video, thumbnailID uuid.UUID) (*entity.VideoThumbnail, error)
+
+	// UploadCustomThumbnail проверяет изображение, сохраняет его в стандартных размерах и делает обложкой видео
+	UploadCustomThumbnail(ctx context.Context, video *entity.Video, file io.Reader) (*entity.VideoThumbnail, error)
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/mrkbwp/gotube/internal/dto"
+	"io"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+)
+
+// UploadService определяет интерфейс загрузки исходников: возобновляемой (tus) и прямой в хранилище
+type UploadService interface {
+	// CreateUpload создает видео в статусе uploading и загрузку его исходника длиной length байт.
+	// metadata - Upload-Metadata клиента: filename, title, description, category_id
+	CreateUpload(ctx context.Context, userID uuid.UUID, length int64, metadata map[string]string) (*entity.VideoUpload, error)
+
+	// GetUpload возвращает загрузку пользователя
+	GetUpload(ctx context.Context, id, userID uuid.UUID) (*entity.VideoUpload, error)
+
+	// WriteChunk дописывает кусок, начинающийся с offset, и возвращает загрузку с новым смещением.
+	// Последний кусок завершает загрузку и отправляет видео на обработку
+	WriteChunk(ctx context.Context, upload *entity.VideoUpload, offset int64, chunk io.Reader) (*entity.VideoUpload, error)
+
+	// CreateDirectUpload создает видео в статусе uploading и подписанную форму загрузки исходника
+	// длиной length байт прямо в хранилище. metadata: filename, title, description, category_id,
+	// content_type (video/*) и необязательный checksum (SHA-256 в hex)
+	CreateDirectUpload(ctx context.Context, userID uuid.UUID, length int64, metadata map[string]string) (*dto.DirectUploadResponse, error)
+
+	// CompleteDirectUpload проверяет, что исходник загружен в хранилище, и отправляет видео на обработку.
+	// Файл без сигнатуры видео удаляется вместе с загрузкой, ErrUnsupportedMediaType
+	CompleteDirectUpload(ctx context.Context, upload *entity.VideoUpload) (*entity.VideoUpload, error)
+
+	// TerminateUpload прерывает загрузку и удаляет видео
+	TerminateUpload(ctx context.Context, upload *entity.VideoUpload) error
+
+	// ExpireUploads удаляет просроченные незавершенные загрузки
+	ExpireUploads(ctx context.Context) (int, error)
+
+	// StartCleanup запускает периодическое удаление просроченных загрузок
+	StartCleanup()
+
+	// StopCleanup останавливает удаление просроченных загрузок
+	StopCleanup()
+}
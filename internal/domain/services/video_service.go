@@ -4,15 +4,29 @@ import (
 	"context"
 	"github.com/google/uuid"
 	"github.com/mrkbwp/gotube/internal/dto"
+	"github.com/mrkbwp/gotube/pkg/constants"
 	"io"
+	"time"
 
 	"github.com/mrkbwp/gotube/internal/domain/entity"
 )
 
 // VideoService определяет интерфейс для бизнес-логики видео
 type VideoService interface {
-	// UploadVideo загружает новое видео
-	UploadVideo(ctx context.Context, userID, categoryID uuid.UUID, file io.Reader, filename, title, description string) (*entity.Video, error)
+	// UploadVideo проверяет контейнер и квоты и загружает новое видео, size - размер файла или 0, если он неизвестен
+	UploadVideo(ctx context.Context, userID uuid.UUID, file io.Reader, size int64, filename string, details dto.VideoDetails) (*entity.Video, error)
+
+	// CreatePendingVideo проверяет квоты и создает видео в статусе status (uploading или importing), исходник которого
+	// размером size (0 - неизвестен) загружается отдельно по пути GetStorageFilePath(original)
+	CreatePendingVideo(ctx context.Context, userID, categoryID uuid.UUID, status constants.VideoStatus, size int64, filename, title, description string) (*entity.Video, error)
+
+	// UploadVideoSource проверяет контейнер, размер и квоты, сохраняет исходник видео, созданного CreatePendingVideo
+	// без размера, и отправляет видео на обработку. size - размер исходника или 0, тогда квоты проверяются после загрузки
+	UploadVideoSource(ctx context.Context, video *entity.Video, file io.Reader, size int64) error
+
+	// CompleteVideoUpload сохраняет размер, тип и контрольную сумму загруженного исходника,
+	// переводит видео в uploaded и отправляет на обработку
+	CompleteVideoUpload(ctx context.Context, video *entity.Video) error
 
 	// GetVideoByCode возвращает информацию о видео по коду
 	GetVideoByCode(ctx context.Context, code string) (*entity.Video, error)
@@ -26,11 +40,11 @@ type VideoService interface {
 	// GetPopularVideos возвращает список популярных видео с пагинацией
 	GetPopularVideos(ctx context.Context, page, limit int) ([]*entity.Video, int64, error)
 
-	// GetUserVideos возвращает видео пользователя с пагинацией
-	GetUserVideos(ctx context.Context, userID uuid.UUID, page, limit int) ([]*entity.Video, int64, error)
+	// GetUserVideos возвращает видео пользователя с пагинацией. Владельцу (viewerID) возвращаются и непубличные видео
+	GetUserVideos(ctx context.Context, userID uuid.UUID, viewerID *uuid.UUID, page, limit int) ([]*entity.Video, int64, error)
 
-	// UpdateVideo обновляет информацию о видео
-	UpdateVideo(ctx context.Context, id, categoryID uuid.UUID, title, description string) (*entity.Video, error)
+	// UpdateVideo обновляет информацию о видео, пустая visibility сохраняет текущую видимость
+	UpdateVideo(ctx context.Context, id, categoryID uuid.UUID, title, description, visibility string, publishAt *time.Time) (*entity.Video, error)
 
 	// DeleteVideo удаляет видео
 	DeleteVideo(ctx context.Context, id uuid.UUID) error
@@ -44,9 +58,39 @@ type VideoService interface {
 	// ViewVideo регистрирует просмотр видео
 	ViewVideo(ctx context.Context, videoId uuid.UUID, userID *uuid.UUID, userIp string) error
 
-	// GetVideoFiles получение видео файлов
-	GetVideoFiles(ctx context.Context, video *entity.Video) ([]*entity.VideoFile, error)
+	// CheckVideoAccess проверяет, что зритель может смотреть видео: ErrVideoPrivate, ErrVideoBlocked,
+	// ErrVideoProcessing, ErrInvalidStatus или ошибки ссылки доступа, если нет. Владельцу и администраторам доступны любые видео,
+	// неопубликованные - еще пользователям с выданным доступом и по ссылке. Просмотр ссылки не списывается
+	CheckVideoAccess(ctx context.Context, video *entity.Video, viewer dto.VideoViewer) error
+
+	// CheckVideoPlayback проверяет доступ как CheckVideoAccess и списывает просмотр ссылки доступа
+	// на первом плейлисте или манифесте, который запрашивает плеер
+	CheckVideoPlayback(ctx context.Context, video *entity.Video, viewer dto.VideoViewer) error
+
+	// GetVideoFiles проверяет доступ зрителя, списывает просмотр ссылки доступа и возвращает файлы видео с временными ссылками
+	GetVideoFiles(ctx context.Context, video *entity.Video, viewer dto.VideoViewer) ([]*entity.VideoFile, error)
+
+	// GetHLSMasterPlaylist получение мастер-плейлиста HLS
+	GetHLSMasterPlaylist(ctx context.Context, video *entity.Video) ([]byte, error)
+
+	// GetHLSVariantPlaylist получение плейлиста качества с временными ссылками на сегменты
+	GetHLSVariantPlaylist(ctx context.Context, video *entity.Video, quality string) ([]byte, error)
+
+	// GetDASHManifest получение DASH манифеста с временными ссылками на сегменты
+	GetDASHManifest(ctx context.Context, video *entity.Video) ([]byte, error)
+
+	// GetProcessingStatus получение прогресса обработки видео по качествам
+	GetProcessingStatus(ctx context.Context, video *entity.Video) (*dto.ProcessingResponse, error)
+
+	// WatchProcessing поток прогресса обработки, закрывается по завершении обработки или отмене контекста
+	WatchProcessing(ctx context.Context, video *entity.Video) (<-chan *dto.ProcessingResponse, error)
 
 	// GetVideoUserInfoByCode получение информации для залогиненного юзера
 	GetVideoUserInfoByCode(ctx context.Context, code string, userID uuid.UUID) (*dto.VideoUserResponse, error)
+
+	// StartPublishScheduler запускает периодическую публикацию запланированных видео
+	StartPublishScheduler()
+
+	// StopPublishScheduler останавливает публикацию запланированных видео
+	StopPublishScheduler()
 }
@@ -0,0 +1,18 @@
+package dto
+
+// ProcessingResponse прогресс обработки видео по качествам
+type ProcessingResponse struct {
+	VideoCode    string            `json:"video_code"`
+	Status       string            `json:"status"`
+	ErrorMessage *string           `json:"error_message,omitempty"`
+	Progress     int               `json:"progress"`
+	Finished     bool              `json:"finished"`
+	Qualities    []QualityProgress `json:"qualities"`
+}
+
+// QualityProgress прогресс конвертации одного качества
+type QualityProgress struct {
+	Quality  string `json:"quality"`
+	Status   string `json:"status"`
+	Progress int    `json:"progress"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+// StreamingResponse ссылки на манифесты адаптивного стриминга
+type StreamingResponse struct {
+	HLSURL string `json:"hls_url"`
+}
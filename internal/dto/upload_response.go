@@ -0,0 +1,16 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+)
+
+// DirectUploadResponse загрузка исходника и подписанная форма, которой клиент отправляет файл прямо в хранилище.
+// Поля FormData передаются multipart POST на URL, файл - последним полем file
+type DirectUploadResponse struct {
+	Upload    *entity.VideoUpload `json:"upload"`
+	URL       string              `json:"url"`
+	FormData  map[string]string   `json:"form_data"`
+	ExpiresAt time.Time           `json:"expires_at"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// VideoDetails сведения о видео, которые автор задает при загрузке.
+// Visibility - constants.VideoVisibility*, пустая означает public, PublishAt нужен только для scheduled
+type VideoDetails struct {
+	CategoryID  uuid.UUID
+	Title       string
+	Description string
+	Visibility  string
+	PublishAt   *time.Time
+	Tags        []string
+}
@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// VideoViewer зритель видео: пользователь, если запрос авторизован, и ссылка доступа с паролем, если они переданы
+type VideoViewer struct {
+	UserID        *uuid.UUID
+	ShareToken    string
+	SharePassword string
+	// IP - адрес зрителя, по нему ограничиваются попытки ввести пароль ссылки
+	IP string
+}
+
+// ShareLinkOptions параметры новой ссылки доступа. Пустой пароль - ссылка без пароля, MaxViews nil - без лимита просмотров
+type ShareLinkOptions struct {
+	ExpiresAt time.Time
+	Password  string
+	MaxViews  *int
+}
+
+// ShareViewerToken токен зрителя, выданный в обмен на пароль ссылки. Передается вместо токена ссылки
+type ShareViewerToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
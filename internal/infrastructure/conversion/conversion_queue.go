@@ -1,26 +1,51 @@
 package conversion
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/mrkbwp/gotube/internal/domain/entity"
 	"github.com/mrkbwp/gotube/internal/domain/repositories"
 	"github.com/mrkbwp/gotube/internal/infrastructure/storage"
+	"github.com/mrkbwp/gotube/pkg/config"
 	"github.com/mrkbwp/gotube/pkg/constants"
+	"github.com/mrkbwp/gotube/pkg/kafka"
+	"github.com/mrkbwp/gotube/pkg/subtitles"
 )
 
 type ConversionQueue struct {
 	videoRepo     repositories.VideoRepository
-	storageClient *storage.MinioClient
+	jobRepo       repositories.ConversionJobRepository
+	thumbnailRepo repositories.VideoThumbnailRepository
+	subtitleRepo  repositories.VideoSubtitleRepository
+	storageClient storage.ObjectStorage
 	ffmpeg        *FFmpegService
+	progress      *ProgressTracker
 
+	// retryPolicies политики повторов по классу сбоя
+	retryPolicies map[string]config.RetryPolicy
+	// maxDuration - максимальная длительность исходника, 0 - без ограничения
+	maxDuration time.Duration
+	// deadLetterProducer публикует исчерпавшие попытки задания, может быть nil
+	deadLetterProducer *kafka.Producer
+	// processingProducer отправляет воркерам задания, вернувшиеся в очередь, нужен только сборщику аренд
+	processingProducer *kafka.Producer
+
+	// workerID идентифицирует процесс как арендатора заданий
+	workerID string
+
+	// activeConversions - задания, выполняемые этим процессом
 	activeConversions sync.Map
 	ticker            *time.Ticker
 	stopChan          chan struct{}
@@ -28,16 +53,36 @@ type ConversionQueue struct {
 
 func NewConversionQueue(
 	videoRepo repositories.VideoRepository,
-	storageClient *storage.MinioClient,
+	jobRepo repositories.ConversionJobRepository,
+	thumbnailRepo repositories.VideoThumbnailRepository,
+	subtitleRepo repositories.VideoSubtitleRepository,
+	storageClient storage.ObjectStorage,
 	ffmpeg *FFmpegService,
+	progress *ProgressTracker,
+	retryPolicies map[string]config.RetryPolicy,
+	maxDuration time.Duration,
+	deadLetterProducer *kafka.Producer,
+	processingProducer *kafka.Producer,
 ) *ConversionQueue {
-	log.Println("Initializing conversion queue")
+	hostname, _ := os.Hostname()
+	workerID := fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8])
+
+	log.Printf("Initializing conversion queue, worker %s", workerID)
 	return &ConversionQueue{
-		videoRepo:     videoRepo,
-		storageClient: storageClient,
-		ffmpeg:        ffmpeg,
-		ticker:        time.NewTicker(constants.ConversionCheckInterval),
-		stopChan:      make(chan struct{}),
+		videoRepo:          videoRepo,
+		jobRepo:            jobRepo,
+		thumbnailRepo:      thumbnailRepo,
+		subtitleRepo:       subtitleRepo,
+		storageClient:      storageClient,
+		ffmpeg:             ffmpeg,
+		progress:           progress,
+		retryPolicies:      retryPolicies,
+		maxDuration:        maxDuration,
+		deadLetterProducer: deadLetterProducer,
+		processingProducer: processingProducer,
+		workerID:           workerID,
+		ticker:             time.NewTicker(constants.ConversionCheckInterval),
+		stopChan:           make(chan struct{}),
 	}
 }
 
@@ -46,6 +91,14 @@ func (q *ConversionQueue) Start() {
 	go q.processQueue()
 }
 
+// StartLeaseReaper запускает только сборщик аренд, без выбора заданий из базы. Используется воркерами,
+// которые получают задания из Kafka: задания упавших воркеров и запланированные повторы снова
+// отправляются в топик обработки и достаются воркерам через consumer
+func (q *ConversionQueue) StartLeaseReaper() {
+	log.Println("Starting conversion lease reaper")
+	go q.reapLeases()
+}
+
 func (q *ConversionQueue) Stop() {
 	log.Println("Stopping conversion queue")
 	q.ticker.Stop()
@@ -57,6 +110,15 @@ func (q *ConversionQueue) processQueue() {
 	for {
 		select {
 		case <-q.ticker.C:
+			ctx := context.Background()
+			q.reapExpiredJobs(ctx)
+
+			if created, err := q.jobRepo.EnqueueUploadedVideos(ctx); err != nil {
+				log.Printf("Failed to enqueue uploaded videos: %v", err)
+			} else if created > 0 {
+				log.Printf("Enqueued %d uploaded videos", created)
+			}
+
 			count := 0
 			q.activeConversions.Range(func(k, _ interface{}) bool {
 				count++
@@ -70,37 +132,18 @@ func (q *ConversionQueue) processQueue() {
 				continue
 			}
 
-			ctx := context.Background()
-			videos, err := q.videoRepo.GetVideosForConversion(ctx, constants.MaxConcurrentConversions-count)
+			jobs, err := q.jobRepo.AcquireVideoJobs(ctx, q.workerID, constants.ConversionLeaseDuration, constants.MaxConcurrentConversions-count)
 			if err != nil {
-				log.Printf("Failed to get videos for conversion: %v", err)
+				log.Printf("Failed to acquire conversion jobs: %v", err)
 				continue
 			}
-			log.Printf("Found %d videos for conversion", len(videos))
-
-			for _, video := range videos {
-				if _, exists := q.activeConversions.Load(video.ID); exists {
-					log.Printf("Video %s is already being converted, skipping", video.ID)
-					continue
-				}
-
-				log.Printf("Starting conversion for video %s", video.ID)
-				if err := q.videoRepo.UpdateStatus(ctx, video.ID, string(constants.VideoStatusProcessing)); err != nil {
-					log.Printf("Failed to update video status: %v", err)
-					continue
-				}
-
-				q.activeConversions.Store(video.ID, true)
-				go func(v *entity.Video) {
-					defer q.activeConversions.Delete(v.ID)
-					log.Printf("Starting conversion goroutine for video %s", v.ID)
-
-					if err := q.convertVideo(v); err != nil {
-						log.Printf("Failed to convert video %s: %v", v.ID, err)
-						_ = q.videoRepo.UpdateStatus(ctx, v.ID, string(constants.VideoStatusError))
-					}
-					log.Printf("Finished conversion goroutine for video %s", v.ID)
-				}(video)
+			log.Printf("Acquired %d conversion jobs", len(jobs))
+
+			for _, job := range jobs {
+				q.activeConversions.Store(job.VideoID, true)
+				go func(job *entity.ConversionJob) {
+					_ = q.runJob(context.Background(), job)
+				}(job)
 			}
 
 		case <-q.stopChan:
@@ -110,8 +153,287 @@ func (q *ConversionQueue) processQueue() {
 	}
 }
 
-func (q *ConversionQueue) convertVideo(video *entity.Video) error {
-	ctx := context.Background()
+func (q *ConversionQueue) reapLeases() {
+	for {
+		select {
+		case <-q.ticker.C:
+			ctx := context.Background()
+			q.reapExpiredJobs(ctx)
+
+			// Видео, сообщение о которых не дошло до Kafka при загрузке
+			if created, err := q.jobRepo.EnqueueUploadedVideos(ctx); err != nil {
+				log.Printf("Failed to enqueue uploaded videos: %v", err)
+			} else if created > 0 {
+				log.Printf("Enqueued %d uploaded videos", created)
+			}
+
+			q.dispatchDueJobs(ctx)
+
+		case <-q.stopChan:
+			log.Println("Received stop signal, stopping lease reaper")
+			return
+		}
+	}
+}
+
+// dispatchDueJobs отправляет в топик обработки ожидающие задания, которым пора выполняться
+func (q *ConversionQueue) dispatchDueJobs(ctx context.Context) {
+	if q.processingProducer == nil {
+		return
+	}
+
+	jobs, err := q.jobRepo.DispatchDueJobs(ctx, constants.ConversionRedispatchInterval, constants.ConversionDispatchBatchSize)
+	if err != nil {
+		log.Printf("Failed to dispatch conversion jobs: %v", err)
+		return
+	}
+
+	dispatched := 0
+	for _, job := range jobs {
+		video, err := q.videoRepo.GetByID(ctx, job.VideoID)
+		if err != nil {
+			log.Printf("Failed to get video %s for job %s: %v", job.VideoID, job.ID, err)
+			continue
+		}
+
+		// Неотправленное задание уйдет повторно через ConversionRedispatchInterval
+		err = q.processingProducer.SendVideoProcessingMessage(ctx, kafka.VideoProcessingMessage{
+			VideoID:      video.ID,
+			BucketID:     video.BucketID,
+			ShardID:      video.ShardID,
+			PathSegment1: video.PathSegment1,
+			PathSegment2: video.PathSegment2,
+			Filename:     video.Filename,
+		})
+		if err != nil {
+			log.Printf("Failed to dispatch job %s: %v", job.ID, err)
+			continue
+		}
+		dispatched++
+	}
+
+	if dispatched > 0 {
+		log.Printf("Dispatched %d conversion jobs", dispatched)
+	}
+}
+
+// ProcessVideo синхронно выполняет задание конвертации видео, например по сообщению из Kafka.
+// Возвращает nil, если задание уже выполнено, завершилось ошибкой или арендовано другим воркером
+func (q *ConversionQueue) ProcessVideo(ctx context.Context, videoID uuid.UUID) error {
+	if err := q.jobRepo.EnqueueVideo(ctx, videoID); err != nil {
+		return fmt.Errorf("failed to enqueue video: %w", err)
+	}
+
+	job, err := q.jobRepo.AcquireVideoJob(ctx, videoID, q.workerID, constants.ConversionLeaseDuration)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			log.Printf("No pending conversion job for video %s, skipping", videoID)
+			return nil
+		}
+		return fmt.Errorf("failed to acquire job: %w", err)
+	}
+
+	q.activeConversions.Store(job.VideoID, true)
+	return q.runJob(ctx, job)
+}
+
+// runJob выполняет арендованное задание видео, продлевая аренду до завершения
+func (q *ConversionQueue) runJob(parent context.Context, job *entity.ConversionJob) error {
+	defer q.activeConversions.Delete(job.VideoID)
+	log.Printf("Starting conversion goroutine for video %s, attempt %d", job.VideoID, job.Attempts)
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	var leaseLost atomic.Bool
+	go q.keepLease(ctx, cancel, job, &leaseLost)
+
+	video, err := q.videoRepo.GetByID(ctx, job.VideoID)
+	if err != nil {
+		log.Printf("Failed to get video %s for job %s: %v", job.VideoID, job.ID, err)
+		_ = q.jobRepo.FailJob(context.Background(), job.ID, q.workerID, err.Error())
+		return fmt.Errorf("failed to get video: %w", err)
+	}
+
+	if err := q.videoRepo.UpdateStatus(ctx, video.ID, string(constants.VideoStatusProcessing)); err != nil {
+		log.Printf("Failed to update video status: %v", err)
+	}
+	q.progress.Notify(ctx, video.ID)
+
+	err = q.convertVideo(ctx, video)
+
+	if ctx.Err() != nil {
+		// Аренду забрал другой воркер, результат задания нас больше не касается
+		if leaseLost.Load() {
+			log.Printf("Lease lost for video %s, abandoning job %s", video.ID, job.ID)
+			return nil
+		}
+
+		// Остановка или ребаланс: сразу отдаем задание другим воркерам, не дожидаясь истечения аренды
+		log.Printf("Conversion of video %s interrupted, releasing job %s", video.ID, job.ID)
+		if err := q.jobRepo.ReleaseJob(context.Background(), job.ID, q.workerID); err != nil {
+			log.Printf("Failed to release job %s: %v", job.ID, err)
+		}
+		return ctx.Err()
+	}
+
+	if err != nil {
+		log.Printf("Failed to convert video %s: %v", video.ID, err)
+		// Сбой записан в задание: повтор запланирован или задание ушло в dead letter
+		return q.handleJobFailure(ctx, job, err)
+	}
+
+	if err := q.videoRepo.UpdateErrorMessage(ctx, video.ID, nil); err != nil {
+		log.Printf("Failed to clear video error message: %v", err)
+	}
+
+	if err := q.jobRepo.CompleteJob(ctx, job.ID, q.workerID); err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			// Аренда истекла до завершения, задание выполняет другой воркер
+			log.Printf("Lease lost for video %s, job %s was not completed", video.ID, job.ID)
+			return nil
+		}
+		log.Printf("Failed to mark job %s as completed: %v", job.ID, err)
+	}
+	q.progress.Notify(ctx, video.ID)
+	log.Printf("Finished conversion goroutine for video %s", video.ID)
+	return nil
+}
+
+// keepLease продлевает аренду задания и отменяет контекст, если аренда потеряна
+func (q *ConversionQueue) keepLease(ctx context.Context, cancel context.CancelFunc, job *entity.ConversionJob, leaseLost *atomic.Bool) {
+	ticker := time.NewTicker(constants.ConversionHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := q.jobRepo.Heartbeat(ctx, job.ID, q.workerID, constants.ConversionLeaseDuration)
+			if errors.Is(err, constants.ErrNotFound) {
+				log.Printf("Lease for job %s was taken over", job.ID)
+				leaseLost.Store(true)
+				cancel()
+				return
+			}
+			if err != nil {
+				log.Printf("Failed to extend lease for job %s: %v", job.ID, err)
+			}
+		}
+	}
+}
+
+// reapExpiredJobs возвращает в очередь задания упавших воркеров,
+// число попыток ограничено политикой повторов класса lease
+func (q *ConversionQueue) reapExpiredJobs(ctx context.Context) {
+	requeued, exhausted, err := q.jobRepo.RequeueExpired(ctx, q.retryPolicy(constants.FailureClassLease).MaxAttempts)
+	if err != nil {
+		log.Printf("Failed to requeue expired jobs: %v", err)
+		return
+	}
+
+	if requeued > 0 {
+		log.Printf("Requeued %d expired conversion jobs", requeued)
+	}
+
+	for _, job := range exhausted {
+		log.Printf("Conversion attempts exhausted for video %s", job.VideoID)
+		if err := q.deadLetterJob(ctx, job, constants.FailureClassLease, "lease expired"); err != nil {
+			log.Printf("Failed to dead letter job %s: %v", job.ID, err)
+		}
+	}
+}
+
+// handleJobFailure планирует повтор задания по политике класса сбоя
+// или отправляет задание в dead letter, если попытки исчерпаны
+func (q *ConversionQueue) handleJobFailure(ctx context.Context, job *entity.ConversionJob, jobErr error) error {
+	class := failureClass(jobErr)
+	policy := q.retryPolicy(class)
+	message := jobErr.Error()
+
+	if job.Attempts >= policy.MaxAttempts {
+		log.Printf("Conversion attempts exhausted for video %s (%s, %d attempts)", job.VideoID, class, job.Attempts)
+		return q.deadLetterJob(ctx, job, class, message)
+	}
+
+	// Видео остается в processing, ошибку показываем владельцу до следующей попытки
+	if err := q.videoRepo.UpdateErrorMessage(ctx, job.VideoID, &message); err != nil {
+		log.Printf("Failed to update video error message: %v", err)
+	}
+
+	backoff := policy.Backoff(job.Attempts)
+	if err := q.jobRepo.RetryJob(ctx, job.ID, q.workerID, class, message, time.Now().Add(backoff)); err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			// Аренда истекла, повтором распоряжается сборщик аренд или новый арендатор
+			log.Printf("Lease lost for job %s, retry not scheduled", job.ID)
+			return nil
+		}
+		return fmt.Errorf("failed to schedule retry: %w", err)
+	}
+
+	log.Printf("Scheduled retry of job %s (%s) in %s", job.ID, class, backoff)
+	q.progress.Notify(ctx, job.VideoID)
+	return nil
+}
+
+// deadLetterJob помечает задание и видео ошибкой, сохраняет задание в dead letter и публикует его в Kafka
+func (q *ConversionQueue) deadLetterJob(ctx context.Context, job *entity.ConversionJob, class, message string) error {
+	deadLetter, err := q.jobRepo.DeadLetterJob(ctx, job.ID, class, message)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			// Задание уже обработано другим воркером
+			return nil
+		}
+		return fmt.Errorf("failed to dead letter job: %w", err)
+	}
+
+	if err := q.videoRepo.UpdateErrorMessage(ctx, job.VideoID, &message); err != nil {
+		log.Printf("Failed to update video error message: %v", err)
+	}
+	if err := q.videoRepo.UpdateStatus(ctx, job.VideoID, string(constants.VideoStatusError)); err != nil {
+		log.Printf("Failed to update video status: %v", err)
+	}
+	q.progress.Notify(ctx, job.VideoID)
+
+	if q.deadLetterProducer == nil {
+		return nil
+	}
+
+	// Запись в таблице уже есть, сбой публикации не должен повторять задание
+	err = q.deadLetterProducer.SendConversionDeadLetterMessage(ctx, kafka.ConversionDeadLetterMessage{
+		DeadLetterID: deadLetter.ID,
+		JobID:        deadLetter.JobID,
+		VideoID:      deadLetter.VideoID,
+		FailureClass: deadLetter.FailureClass,
+		Error:        deadLetter.Error,
+		Attempts:     deadLetter.Attempts,
+		FailedAt:     deadLetter.CreatedAt,
+	})
+	if err != nil {
+		log.Printf("Failed to publish dead letter %s: %v", deadLetter.ID, err)
+	}
+
+	return nil
+}
+
+// retryPolicy возвращает политику повторов класса сбоя, по умолчанию политику внутренних ошибок
+func (q *ConversionQueue) retryPolicy(class string) config.RetryPolicy {
+	if policy, ok := q.retryPolicies[class]; ok {
+		return policy
+	}
+	if policy, ok := q.retryPolicies[constants.FailureClassInternal]; ok {
+		return policy
+	}
+
+	return config.RetryPolicy{
+		MaxAttempts:    constants.MaxConversionAttempts,
+		InitialBackoff: time.Minute,
+		MaxBackoff:     15 * time.Minute,
+	}
+}
+
+func (q *ConversionQueue) convertVideo(ctx context.Context, video *entity.Video) error {
 	log.Printf("Starting video conversion for %s", video.ID)
 
 	qualities, err := q.videoRepo.GetVideoQualities(ctx)
@@ -121,63 +443,79 @@ func (q *ConversionQueue) convertVideo(video *entity.Video) error {
 	}
 	log.Printf("Got %d qualities for conversion", len(qualities))
 
+	profiles, err := q.transcodeProfiles(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Качества, готовые с прошлой попытки, повторно не конвертируем
+	qualityJobs, err := q.jobRepo.GetQualityJobs(ctx, video.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get quality jobs: %w", err)
+	}
+	completed := make(map[uuid.UUID]bool, len(qualityJobs))
+	for _, job := range qualityJobs {
+		if job.QualityID != nil && job.Status == string(constants.ConversionJobStatusCompleted) {
+			completed[*job.QualityID] = true
+		}
+	}
+
 	originalFilePath := video.GetStorageFilePath(constants.VideoQualityOriginal)
 	inputFile := filepath.Join(q.ffmpeg.tempDir, video.Filename)
 	log.Printf("Downloading original file from %s to %s", originalFilePath, inputFile)
 
 	if err := q.storageClient.DownloadFile(ctx, video.BucketID, originalFilePath, inputFile); err != nil {
 		log.Printf("Failed to download original file for video %s: %v", video.ID, err)
-		return fmt.Errorf("failed to download original file: %w", err)
+		return classify(constants.FailureClassDownload, fmt.Errorf("failed to download original file: %w", err))
 	}
 	defer q.cleanupTempFile(inputFile)
 
-	// Получаем длительность видео
-	duration, err := q.ffmpeg.GetVideoInfo(inputFile)
+	// Параметры исходника сохраняем до проверок, чтобы отклоненное видео было видно в метаданных
+	mediaInfo, err := q.ffmpeg.ProbeMedia(ctx, inputFile)
 	if err != nil {
-		log.Printf("Failed to get video duration: %v", err)
-		return fmt.Errorf("failed to get video duration: %w", err)
+		log.Printf("Failed to probe video %s: %v", video.ID, err)
+		return classify(constants.FailureClassProbe, fmt.Errorf("failed to probe video: %w", err))
 	}
-	log.Printf("Video duration: %d seconds", duration)
 
-	// Генерируем имя файла для thumbnail с правильным расширением
-	thumbnailFilename := strings.TrimSuffix(video.Filename, filepath.Ext(video.Filename)) + ".jpg"
-	thumbnailPath := filepath.Join(q.ffmpeg.tempDir, thumbnailFilename)
-	if err := q.ffmpeg.GenerateThumbnail(inputFile, thumbnailPath); err != nil {
-		log.Printf("Failed to generate thumbnail: %v", err)
-		return fmt.Errorf("failed to generate thumbnail: %w", err)
+	metadata := mediaInfo.Metadata()
+	if err := q.videoRepo.UpdateMetadata(ctx, video.ID, metadata); err != nil {
+		return fmt.Errorf("failed to update video metadata: %w", err)
 	}
-	defer q.cleanupTempFile(thumbnailPath)
-
-	// Открываем thumbnail для загрузки
-	thumbFile, err := os.Open(thumbnailPath)
-	if err != nil {
-		log.Printf("Failed to open thumbnail file: %v", err)
-		return fmt.Errorf("failed to open thumbnail: %w", err)
+	if video.Metadata == nil {
+		video.Metadata = entity.Metadata{}
+	}
+	for key, value := range metadata {
+		video.Metadata[key] = value
 	}
-	defer thumbFile.Close()
 
-	// Генерируем путь для thumbnail без домена
-	thumbnailStoragePath := fmt.Sprintf("%s/%s/%s/%s.jpg",
-		video.ShardID,
-		video.PathSegment1,
-		video.PathSegment2,
-		strings.TrimSuffix(video.Filename, filepath.Ext(video.Filename)),
-	)
+	// Без видеопотока конвертировать нечего, повторы не помогут
+	if mediaInfo.Video == nil {
+		log.Printf("Video %s has no video stream, rejecting", video.ID)
+		return classify(constants.FailureClassInvalidMedia, constants.ErrNoVideoStream)
+	}
 
-	log.Printf("Thumbnail storage path: %s", thumbnailStoragePath)
+	if q.maxDuration > 0 && mediaInfo.Duration > q.maxDuration.Seconds() {
+		log.Printf("Video %s is %.0f seconds long, limit is %s, rejecting", video.ID, mediaInfo.Duration, q.maxDuration)
+		return classify(constants.FailureClassInvalidMedia, fmt.Errorf("%w: limit is %s", constants.ErrVideoTooLong, q.maxDuration))
+	}
 
-	// Загружаем thumbnail в отдельный бакет
-	if err := q.storageClient.UploadFile(ctx, constants.ThumbnailsBucket, thumbnailStoragePath, thumbFile); err != nil {
-		log.Printf("Failed to upload thumbnail: %v", err)
-		return fmt.Errorf("failed to upload thumbnail: %w", err)
+	// Громкость замеряем один раз, второй проход loudnorm выполняется при конвертации каждого файла
+	if mediaInfo.Audio != nil {
+		q.measureLoudness(ctx, video, inputFile, mediaInfo.Audio.Index)
 	}
 
-	// Формируем прямой URL для thumbnail
-	thumbnailURL := fmt.Sprintf("%s/%s",
-		q.storageClient.GetPublicURL(constants.ThumbnailsBucket, ""), // base URL
-		thumbnailStoragePath,
-	)
-	log.Printf("Storing thumbnail path: %s", thumbnailURL)
+	// Размеры исходника определяют лестницу качеств: без апскейла и с сохранением пропорций
+	sourceWidth, sourceHeight := mediaInfo.DisplaySize()
+	qualities = entity.SelectRenditions(qualities, sourceWidth, sourceHeight)
+	log.Printf("Selected %d renditions for %dx%d source", len(qualities), sourceWidth, sourceHeight)
+
+	duration := mediaInfo.DurationSeconds()
+	log.Printf("Video duration: %d seconds", duration)
+
+	thumbnailURL, err := q.generateThumbnails(ctx, video, inputFile, duration)
+	if err != nil {
+		return err
+	}
 
 	// Обновляем thumbnail и duration
 	if err := q.videoRepo.UpdateThumbnailAndDuration(ctx, video.ID, thumbnailURL, duration); err != nil {
@@ -185,89 +523,762 @@ func (q *ConversionQueue) convertVideo(video *entity.Video) error {
 		return fmt.Errorf("failed to update video info: %w", err)
 	}
 	log.Printf("Updated video info with thumbnail path: %s and duration: %d", thumbnailURL, duration)
+	video.Duration = duration
 
 	lowQuality := qualities[0]
-	if err := q.convertToQuality(ctx, video, lowQuality, inputFile); err != nil {
+	lowFile, err := q.processQuality(ctx, video, lowQuality, profiles, inputFile, completed[lowQuality.ID])
+	if err != nil {
+		return err
+	}
+	defer q.cleanupTempFile(lowFile)
+
+	lowVariant, err := q.hlsVariant(ctx, video, lowQuality, lowFile)
+	if err != nil {
+		return err
+	}
+
+	// Мастер-плейлист обновляется после каждого готового качества,
+	// чтобы HLS был доступен сразу после перевода видео в ready
+	converted := []*entity.VideoQuality{lowQuality}
+	variants := []*HLSVariant{lowVariant}
+	if err := q.uploadHLSMasterPlaylist(ctx, video, variants); err != nil {
 		return err
 	}
 
 	if err := q.videoRepo.UpdateStatus(ctx, video.ID, string(constants.VideoStatusReady)); err != nil {
 		return fmt.Errorf("failed to update status: %w", err)
 	}
+	q.progress.Notify(ctx, video.ID)
 
+	// Сконвертированные файлы храним до конца, DASH упаковывается из всех качеств разом
+	convertedFiles := []string{lowFile}
 	for _, quality := range qualities[1:] {
-		if err := q.convertToQuality(ctx, video, quality, inputFile); err != nil {
+		outputFile, err := q.processQuality(ctx, video, quality, profiles, inputFile, completed[quality.ID])
+		if err != nil {
 			log.Printf("Failed to convert to quality %s: %v", quality.Name, err)
+			q.progress.MarkQualityFailed(ctx, video.ID, quality.Name)
+			continue
+		}
+		defer q.cleanupTempFile(outputFile)
+
+		converted = append(converted, quality)
+		convertedFiles = append(convertedFiles, outputFile)
+
+		variant, err := q.hlsVariant(ctx, video, quality, outputFile)
+		if err != nil {
+			log.Printf("Failed to measure hls rendition %s for video %s: %v", quality.Name, video.ID, err)
 			continue
 		}
+		variants = append(variants, variant)
+		if err := q.uploadHLSMasterPlaylist(ctx, video, variants); err != nil {
+			log.Printf("Failed to update hls master playlist for video %s: %v", video.ID, err)
+		}
+	}
+
+	if err := q.uploadDASH(ctx, video, profiles[0], converted, convertedFiles); err != nil {
+		log.Printf("Failed to build dash for video %s: %v", video.ID, err)
+	}
+
+	// Звук без видео для фонового прослушивания, ошибка не проваливает задание
+	if len(mediaInfo.AudioTracks) > 0 {
+		if err := q.uploadAudioRenditions(ctx, video, profiles, mediaInfo, inputFile); err != nil {
+			log.Printf("Failed to build audio renditions for video %s: %v", video.ID, err)
+		}
+	}
+
+	// Текстовые субтитры из контейнера, ошибка не проваливает задание
+	q.uploadEmbeddedSubtitles(ctx, video, mediaInfo.SubtitleTracks, inputFile)
+
+	// Превью для перемотки не обязательны для просмотра, ошибка не проваливает задание
+	if err := q.uploadPreviews(ctx, video, inputFile); err != nil {
+		log.Printf("Failed to build seek previews for video %s: %v", video.ID, err)
+	}
+
+	if err := q.uploadPreviewClip(ctx, video, inputFile); err != nil {
+		log.Printf("Failed to build preview clip for video %s: %v", video.ID, err)
 	}
 
 	return nil
 }
 
-func (q *ConversionQueue) convertToQuality(ctx context.Context, video *entity.Video, quality *entity.VideoQuality, inputFile string) error {
-	log.Printf("Starting conversion to quality %s for video %s", quality.Name, video.ID)
+// transcodeProfiles возвращает активные профили, первым идет профиль по умолчанию
+func (q *ConversionQueue) transcodeProfiles(ctx context.Context) ([]*entity.TranscodeProfile, error) {
+	profiles, err := q.videoRepo.GetTranscodeProfiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transcode profiles: %w", err)
+	}
+
+	if len(profiles) == 0 || !profiles[0].IsDefault {
+		return nil, fmt.Errorf("default transcode profile is not configured")
+	}
+
+	return profiles, nil
+}
+
+// processQuality выполняет задание качества всеми профилями и возвращает файл профиля по умолчанию.
+// Готовое качество не конвертируется заново, а скачивается из хранилища, так как файл нужен для упаковки DASH.
+// Сбой дополнительного профиля не проваливает качество
+func (q *ConversionQueue) processQuality(ctx context.Context, video *entity.Video, quality *entity.VideoQuality, profiles []*entity.TranscodeProfile, inputFile string, completed bool) (string, error) {
+	if completed {
+		log.Printf("Quality %s for video %s was converted earlier, reusing", quality.Name, video.ID)
+		return q.downloadConverted(ctx, video, quality, profiles[0])
+	}
+
+	job, err := q.jobRepo.StartQualityJob(ctx, video.ID, quality.ID, q.workerID, constants.ConversionLeaseDuration)
+	if err != nil {
+		return "", fmt.Errorf("failed to start quality job: %w", err)
+	}
+
+	// Аренда задания качества продлевается, пока идет конвертация, как и аренда задания видео
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var leaseLost atomic.Bool
+	go q.keepLease(ctx, cancel, job, &leaseLost)
+
+	outputFile, err := q.convertToQuality(ctx, video, quality, profiles[0], inputFile)
+	if err != nil {
+		if err := q.jobRepo.FailJob(ctx, job.ID, q.workerID, err.Error()); err != nil {
+			log.Printf("Failed to mark quality job %s as failed: %v", job.ID, err)
+		}
+		return "", err
+	}
+
+	for _, profile := range profiles[1:] {
+		profileFile, err := q.convertToQuality(ctx, video, quality, profile, inputFile)
+		if err != nil {
+			log.Printf("Failed to convert video %s quality %s with profile %s: %v", video.ID, quality.Name, profile.Name, err)
+			continue
+		}
+		q.cleanupTempFile(profileFile)
+	}
+
+	if err := q.jobRepo.CompleteJob(ctx, job.ID, q.workerID); err != nil {
+		log.Printf("Failed to mark quality job %s as completed: %v", job.ID, err)
+	}
+
+	return outputFile, nil
+}
 
-	// Генерируем имя выходного файла с качеством
-	outputFilename := fmt.Sprintf("%s_%s%s",
+// downloadConverted скачивает ранее сконвертированный профилем по умолчанию файл качества во временную папку.
+// Путь берется из записи о файле, у файлов до появления профилей его нет, они лежат под именем исходника
+func (q *ConversionQueue) downloadConverted(ctx context.Context, video *entity.Video, quality *entity.VideoQuality, profile *entity.TranscodeProfile) (string, error) {
+	files, err := q.videoRepo.GetVideoFiles(ctx, video.ID)
+	if err != nil {
+		return "", classify(constants.FailureClassInternal, fmt.Errorf("failed to get video files: %w", err))
+	}
+
+	storagePath := video.GetStorageFilePath(quality.Name)
+	for _, file := range files {
+		if file.QualityID == quality.ID && file.Format == profile.Container &&
+			file.ProfileID != nil && *file.ProfileID == profile.ID && file.StoragePath != nil {
+			storagePath = *file.StoragePath
+			break
+		}
+	}
+
+	outputFile := filepath.Join(q.ffmpeg.tempDir, fmt.Sprintf("%s_%s%s",
+		strings.TrimSuffix(video.Filename, filepath.Ext(video.Filename)),
+		quality.Name,
+		path.Ext(storagePath),
+	))
+
+	if err := q.storageClient.DownloadFile(ctx, video.BucketID, storagePath, outputFile); err != nil {
+		return "", classify(constants.FailureClassDownload, fmt.Errorf("failed to download converted file: %w", err))
+	}
+
+	return outputFile, nil
+}
+
+// convertToQuality конвертирует видео в качество профилем и возвращает путь к временному файлу,
+// удалить который должен вызывающий код. HLS нарезается только из профиля по умолчанию
+func (q *ConversionQueue) convertToQuality(ctx context.Context, video *entity.Video, quality *entity.VideoQuality, profile *entity.TranscodeProfile, inputFile string) (string, error) {
+	log.Printf("Starting conversion to quality %s profile %s for video %s", quality.Name, profile.Name, video.ID)
+
+	// Генерируем имя выходного файла с качеством и профилем
+	outputFilename := fmt.Sprintf("%s_%s_%s.%s",
 		strings.TrimSuffix(video.Filename, filepath.Ext(video.Filename)),
 		quality.Name,
-		filepath.Ext(video.Filename),
+		profile.Name,
+		profile.Container,
 	)
 	outputFile := filepath.Join(q.ffmpeg.tempDir, outputFilename)
 	log.Printf("Output file will be: %s", outputFile)
 
 	log.Printf("Starting FFmpeg conversion for video %s to quality %s", video.ID, quality.Name)
-	if err := q.ffmpeg.ConvertVideo(inputFile, outputFile, quality); err != nil {
+	// Прогресс качества показываем по профилю по умолчанию
+	var onProgress func(percent int)
+	if profile.IsDefault {
+		onProgress = func(percent int) {
+			q.progress.SetQualityProgress(ctx, video.ID, quality.Name, percent)
+		}
+	}
+	if err := q.ffmpeg.ConvertVideo(ctx, inputFile, outputFile, quality, profile, StreamMapFromMetadata(video.Metadata), LoudnessFromMetadata(video.Metadata), video.Duration, onProgress); err != nil {
 		log.Printf("FFmpeg conversion failed for video %s quality %s: %v", video.ID, quality.Name, err)
-		return fmt.Errorf("failed to convert video: %w", err)
+		return "", classify(constants.FailureClassFFmpeg, fmt.Errorf("failed to convert video: %w", err))
+	}
+	// При ошибке на следующих шагах файл больше никому не нужен
+	success := false
+	defer func() {
+		if !success {
+			q.cleanupTempFile(outputFile)
+		}
+	}()
+
+	storageFilePath := video.GetRenditionFilePath(quality.Name, profile)
+	log.Printf("Uploading converted file for video %s quality %s to %s", video.ID, quality.Name, storageFilePath)
+	object, err := q.uploadLocalFile(ctx, video.BucketID, storageFilePath, outputFile)
+	if err != nil {
+		log.Printf("Failed to upload converted file for video %s quality %s: %v", video.ID, quality.Name, err)
+		return "", classify(constants.FailureClassUpload, fmt.Errorf("failed to upload converted file: %w", err))
+	}
+	log.Printf("Converted file size: %d bytes, sha256 %s", object.Size, object.Checksum)
+
+	if profile.IsDefault {
+		if err := q.uploadHLSRendition(ctx, video, quality, outputFile); err != nil {
+			log.Printf("Failed to build hls rendition for video %s quality %s: %v", video.ID, quality.Name, err)
+			return "", err
+		}
+	}
+
+	videoFile := &entity.VideoFile{
+		VideoID:     video.ID,
+		QualityID:   quality.ID,
+		Format:      profile.Container,
+		Codec:       profile.VideoCodec,
+		ProfileID:   &profile.ID,
+		StoragePath: &storageFilePath,
+		FileSize:    object.Size,
+		Checksum:    object.Checksum,
+		Width:       quality.Width,
+		Height:      quality.Height,
+		Bitrate:     quality.Bitrate,
+		Status:      "completed",
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	log.Printf("Creating video file record for video %s quality %s", video.ID, quality.Name)
+	if err := q.videoRepo.CreateVideoFile(ctx, videoFile); err != nil {
+		log.Printf("Failed to create video file record for video %s quality %s: %v", video.ID, quality.Name, err)
+		return "", fmt.Errorf("failed to create video file record: %w", err)
+	}
+
+	log.Printf("Successfully completed conversion to quality %s for video %s", quality.Name, video.ID)
+	q.progress.Notify(ctx, video.ID)
+	success = true
+	return outputFile, nil
+}
+
+// measureLoudness замеряет громкость первым проходом loudnorm и сохраняет замеры в метаданные.
+// Без замеров видео конвертируется без нормализации
+func (q *ConversionQueue) measureLoudness(ctx context.Context, video *entity.Video, inputFile string, audioIndex int) {
+	loudness, err := q.ffmpeg.MeasureLoudness(ctx, inputFile, audioIndex)
+	if err != nil {
+		log.Printf("Failed to measure loudness for video %s, skipping normalization: %v", video.ID, err)
+		return
+	}
+	log.Printf("Measured loudness for video %s: %.2f LUFS", video.ID, loudness.Integrated)
+
+	metadata := loudness.Metadata()
+	if err := q.videoRepo.UpdateMetadata(ctx, video.ID, metadata); err != nil {
+		log.Printf("Failed to save loudness for video %s: %v", video.ID, err)
+	}
+	for key, value := range metadata {
+		video.Metadata[key] = value
+	}
+}
+
+// uploadAudioRenditions сохраняет звук без видео для каждого языка исходника и каждого звукового кодека
+// активных профилей. Из нескольких дорожек одного языка берется основная или первая
+func (q *ConversionQueue) uploadAudioRenditions(ctx context.Context, video *entity.Video, profiles []*entity.TranscodeProfile, mediaInfo *MediaInfo, inputFile string) error {
+	audioQuality, err := q.videoRepo.GetVideoQualityByName(ctx, constants.VideoQualityAudio)
+	if err != nil {
+		return fmt.Errorf("failed to get audio quality: %w", err)
+	}
+
+	tracks := []*AudioStreamInfo{mediaInfo.Audio}
+	languages := map[string]bool{mediaInfo.Audio.Language: true}
+	for _, track := range mediaInfo.AudioTracks {
+		if !languages[track.Language] {
+			languages[track.Language] = true
+			tracks = append(tracks, track)
+		}
+	}
+
+	for _, track := range tracks {
+		// Основная дорожка уже замерена, остальные замеряем отдельно
+		loudness := LoudnessFromMetadata(video.Metadata)
+		if track != mediaInfo.Audio {
+			loudness, err = q.ffmpeg.MeasureLoudness(ctx, inputFile, track.Index)
+			if err != nil {
+				log.Printf("Failed to measure loudness of audio track %d for video %s: %v", track.Index, video.ID, err)
+			}
+		}
+
+		codecs := map[string]bool{}
+		for _, profile := range profiles {
+			if codecs[profile.AudioCodec] {
+				continue
+			}
+			codecs[profile.AudioCodec] = true
+
+			if err := q.uploadAudioRendition(ctx, video, audioQuality, profile, track, inputFile, loudness); err != nil {
+				log.Printf("Failed to build %s audio rendition of track %d for video %s: %v", profile.AudioCodec, track.Index, video.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// uploadAudioRendition конвертирует звук кодеком профиля, загружает его и записывает как файл служебного качества audio
+func (q *ConversionQueue) uploadAudioRendition(
+	ctx context.Context,
+	video *entity.Video,
+	audioQuality *entity.VideoQuality,
+	profile *entity.TranscodeProfile,
+	track *AudioStreamInfo,
+	inputFile string,
+	loudness *LoudnessInfo,
+) error {
+	container := AudioContainer(profile)
+	outputFile := filepath.Join(q.ffmpeg.tempDir, fmt.Sprintf("%s_%s_%d_%s.%s",
+		strings.TrimSuffix(video.Filename, filepath.Ext(video.Filename)),
+		constants.VideoQualityAudio,
+		track.Index,
+		profile.AudioCodec,
+		container,
+	))
+
+	if err := q.ffmpeg.ConvertAudio(ctx, inputFile, outputFile, profile, track.Index, loudness); err != nil {
+		return err
 	}
 	defer q.cleanupTempFile(outputFile)
 
-	file, err := os.Open(outputFile)
+	storagePath := video.GetAudioFilePath(track.Language, container)
+	object, err := q.uploadLocalFile(ctx, video.BucketID, storagePath, outputFile)
 	if err != nil {
-		log.Printf("Failed to open converted file %s: %v", outputFile, err)
-		return fmt.Errorf("failed to open converted file: %w", err)
+		return fmt.Errorf("failed to upload audio rendition: %w", err)
 	}
-	defer file.Close()
 
-	storageFilePath := video.GetStorageFilePath(quality.Name)
-	log.Printf("Uploading converted file for video %s quality %s to %s", video.ID, quality.Name, storageFilePath)
-	if err := q.storageClient.UploadFile(ctx, video.BucketID, storageFilePath, file); err != nil {
-		log.Printf("Failed to upload converted file for video %s quality %s: %v", video.ID, quality.Name, err)
-		return fmt.Errorf("failed to upload converted file: %w", err)
+	videoFile := &entity.VideoFile{
+		VideoID:     video.ID,
+		QualityID:   audioQuality.ID,
+		Format:      container,
+		Codec:       profile.AudioCodec,
+		ProfileID:   &profile.ID,
+		Language:    track.Language,
+		StoragePath: &storagePath,
+		FileSize:    object.Size,
+		Checksum:    object.Checksum,
+		Bitrate:     profile.AudioBitrate,
+		Status:      "completed",
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := q.videoRepo.CreateVideoFile(ctx, videoFile); err != nil {
+		return fmt.Errorf("failed to create audio file record: %w", err)
+	}
+	log.Printf("Uploaded %s audio rendition of track %d (%s) for video %s", profile.AudioCodec, track.Index, track.Language, video.ID)
+
+	return nil
+}
+
+// uploadEmbeddedSubtitles извлекает текстовые субтитры из контейнера в WebVTT, по одной дорожке на язык.
+// Полные субтитры предпочитаются принудительным (forced), субтитры без языка пропускаются
+func (q *ConversionQueue) uploadEmbeddedSubtitles(ctx context.Context, video *entity.Video, tracks []*SubtitleStreamInfo, inputFile string) {
+	var candidates []*SubtitleStreamInfo
+	for _, forced := range []bool{false, true} {
+		for _, track := range tracks {
+			if track.IsForced == forced && track.IsText() && track.Language != "" {
+				candidates = append(candidates, track)
+			}
+		}
+	}
+
+	languages := map[string]bool{}
+	for _, track := range candidates {
+		if languages[track.Language] {
+			continue
+		}
+
+		err := q.uploadEmbeddedSubtitle(ctx, video, track, inputFile)
+		if errors.Is(err, constants.ErrAlreadyExists) {
+			log.Printf("Subtitles %s for video %s were uploaded by owner, skipping embedded track %d", track.Language, video.ID, track.Index)
+			languages[track.Language] = true
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to extract subtitle track %d for video %s: %v", track.Index, video.ID, err)
+			continue
+		}
+		languages[track.Language] = true
+	}
+}
+
+// uploadEmbeddedSubtitle извлекает поток субтитров, проверяет его и сохраняет рядом с видео
+func (q *ConversionQueue) uploadEmbeddedSubtitle(ctx context.Context, video *entity.Video, track *SubtitleStreamInfo, inputFile string) error {
+	// Загруженные владельцем субтитры не перезаписываем, в том числе файл в хранилище
+	existing, err := q.subtitleRepo.GetByVideoID(ctx, video.ID)
+	if err != nil {
+		return err
+	}
+	for _, subtitle := range existing {
+		if subtitle.Language == track.Language && subtitle.Source == constants.SubtitleSourceUpload {
+			return constants.ErrAlreadyExists
+		}
+	}
+
+	outputFile := filepath.Join(q.ffmpeg.tempDir, fmt.Sprintf("%s_subtitles_%d.vtt",
+		strings.TrimSuffix(video.Filename, filepath.Ext(video.Filename)),
+		track.Index,
+	))
+	if err := q.ffmpeg.ExtractSubtitles(ctx, inputFile, outputFile, track.Index); err != nil {
+		return err
+	}
+	defer q.cleanupTempFile(outputFile)
+
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read subtitles: %w", err)
+	}
+
+	cues, err := subtitles.Parse(data)
+	if err != nil {
+		return err
+	}
+	if err := subtitles.Validate(cues, time.Duration(video.Duration)*time.Second); err != nil {
+		return err
+	}
+
+	label := track.Title
+	if label == "" {
+		label = track.Language
+	}
+
+	subtitle := &entity.VideoSubtitle{
+		VideoID:     video.ID,
+		Language:    track.Language,
+		Label:       label,
+		Source:      constants.SubtitleSourceEmbedded,
+		StoragePath: video.GetSubtitlePath(track.Language),
+		CuesCount:   len(cues),
+	}
+
+	vtt := subtitles.WriteVTT(cues)
+	if _, err := q.storageClient.UploadFile(ctx, video.BucketID, subtitle.StoragePath, bytes.NewReader(vtt), storage.UploadOptions{Size: int64(len(vtt))}); err != nil {
+		return fmt.Errorf("failed to upload subtitles: %w", err)
+	}
+
+	if err := q.subtitleRepo.Upsert(ctx, subtitle); err != nil {
+		return err
+	}
+	log.Printf("Extracted %s subtitles with %d cues for video %s", track.Language, len(cues), video.ID)
+
+	return nil
+}
+
+// uploadHLSRendition нарезает файл качества на HLS сегменты и загружает их в хранилище
+func (q *ConversionQueue) uploadHLSRendition(ctx context.Context, video *entity.Video, quality *entity.VideoQuality, convertedFile string) error {
+	hlsDir := strings.TrimSuffix(convertedFile, filepath.Ext(convertedFile)) + "_hls"
+	defer q.cleanupTempDir(hlsDir)
+
+	log.Printf("Segmenting %s into hls at %s", convertedFile, hlsDir)
+	if err := q.ffmpeg.SegmentHLS(ctx, convertedFile, hlsDir); err != nil {
+		return classify(constants.FailureClassFFmpeg, fmt.Errorf("failed to segment hls: %w", err))
+	}
+
+	count, _, err := q.uploadDirectory(ctx, video.BucketID, video.GetHLSPath()+"/"+quality.Name, hlsDir)
+	if err != nil {
+		return classify(constants.FailureClassUpload, fmt.Errorf("failed to upload hls: %w", err))
+	}
+
+	log.Printf("Uploaded %d hls files for video %s quality %s", count, video.ID, quality.Name)
+	return nil
+}
+
+// uploadDASH упаковывает готовые качества в DASH, загружает его и записывает как отдельный формат файла
+func (q *ConversionQueue) uploadDASH(ctx context.Context, video *entity.Video, profile *entity.TranscodeProfile, qualities []*entity.VideoQuality, convertedFiles []string) error {
+	dashDir := filepath.Join(q.ffmpeg.tempDir, strings.TrimSuffix(video.Filename, filepath.Ext(video.Filename))+"_dash")
+	defer q.cleanupTempDir(dashDir)
+
+	log.Printf("Packaging %d qualities into dash at %s", len(convertedFiles), dashDir)
+	if err := q.ffmpeg.PackageDASH(ctx, convertedFiles, dashDir); err != nil {
+		return classify(constants.FailureClassFFmpeg, fmt.Errorf("failed to package dash: %w", err))
 	}
 
-	fileInfo, err := os.Stat(outputFile)
+	count, size, err := q.uploadDirectory(ctx, video.BucketID, video.GetDASHPath(), dashDir)
 	if err != nil {
-		log.Printf("Failed to get file info for %s: %v", outputFile, err)
-		return fmt.Errorf("failed to get file info: %w", err)
+		return classify(constants.FailureClassUpload, fmt.Errorf("failed to upload dash: %w", err))
 	}
-	log.Printf("Converted file size: %d bytes", fileInfo.Size())
+	log.Printf("Uploaded %d dash files (%d bytes) for video %s", count, size, video.ID)
 
+	// Манифест покрывает все качества, запись привязываем к самому высокому
+	topQuality := qualities[len(qualities)-1]
 	videoFile := &entity.VideoFile{
 		VideoID:   video.ID,
-		QualityID: quality.ID,
-		Format:    filepath.Ext(video.Filename),
-		FileSize:  fileInfo.Size(),
-		Width:     quality.Width,
-		Height:    quality.Height,
-		Bitrate:   quality.Bitrate,
+		QualityID: topQuality.ID,
+		Format:    constants.StreamingFormatDASH,
+		Codec:     profile.VideoCodec,
+		ProfileID: &profile.ID,
+		FileSize:  size,
+		Width:     topQuality.Width,
+		Height:    topQuality.Height,
+		Bitrate:   topQuality.Bitrate,
 		Status:    "completed",
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
-	log.Printf("Creating video file record for video %s quality %s", video.ID, quality.Name)
 	if err := q.videoRepo.CreateVideoFile(ctx, videoFile); err != nil {
-		log.Printf("Failed to create video file record for video %s quality %s: %v", video.ID, quality.Name, err)
-		return fmt.Errorf("failed to create video file record: %w", err)
+		return fmt.Errorf("failed to create dash file record: %w", err)
 	}
 
-	log.Printf("Successfully completed conversion to quality %s for video %s", quality.Name, video.ID)
 	return nil
 }
 
+// uploadPreviews генерирует спрайты и WebVTT для превью на полосе перемотки
+// и загружает их в бакет миниатюр
+func (q *ConversionQueue) uploadPreviews(ctx context.Context, video *entity.Video, inputFile string) error {
+	if video.Duration <= 0 {
+		return nil
+	}
+
+	sourceWidth, sourceHeight := video.SourceSize()
+	layout := NewSpriteLayout(video.Duration, sourceWidth, sourceHeight)
+
+	previewsDir := filepath.Join(q.ffmpeg.tempDir, strings.TrimSuffix(video.Filename, filepath.Ext(video.Filename))+"_previews")
+	defer q.cleanupTempDir(previewsDir)
+
+	log.Printf("Generating seek previews every %d seconds at %s", layout.Interval, previewsDir)
+	if err := q.ffmpeg.GenerateSprites(ctx, inputFile, previewsDir, layout); err != nil {
+		return err
+	}
+
+	vttPath := filepath.Join(previewsDir, constants.SpriteVTTFile)
+	if err := os.WriteFile(vttPath, BuildSpriteVTT(video.Duration, layout), 0644); err != nil {
+		return fmt.Errorf("failed to write previews vtt: %w", err)
+	}
+
+	count, _, err := q.uploadDirectory(ctx, constants.ThumbnailsBucket, video.GetPreviewsPath(), previewsDir)
+	if err != nil {
+		return fmt.Errorf("failed to upload previews: %w", err)
+	}
+	log.Printf("Uploaded %d preview files for video %s", count, video.ID)
+
+	vttURL := q.storageClient.GetPublicURL(constants.ThumbnailsBucket, video.GetPreviewsPath()+"/"+constants.SpriteVTTFile)
+	if err := q.videoRepo.UpdatePreviewsVTT(ctx, video.ID, vttURL); err != nil {
+		return fmt.Errorf("failed to update previews url: %w", err)
+	}
+
+	return nil
+}
+
+// generateThumbnails сохраняет кадры-кандидаты обложки и кадр смены сцены,
+// возвращает ссылку на выбранную обложку. Без кадра из середины видео конвертация прерывается
+func (q *ConversionQueue) generateThumbnails(ctx context.Context, video *entity.Video, inputFile string, duration int) (string, error) {
+	thumbnailsDir := filepath.Join(q.ffmpeg.tempDir, strings.TrimSuffix(video.Filename, filepath.Ext(video.Filename))+"_thumbnails")
+	if err := os.MkdirAll(thumbnailsDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create thumbnails directory: %w", err)
+	}
+	defer q.cleanupTempDir(thumbnailsDir)
+
+	var thumbnails []*entity.VideoThumbnail
+	for _, percent := range constants.ThumbnailCandidatePercents {
+		position := float64(duration) * float64(percent) / 100
+		name := fmt.Sprintf("frame_%d.jpg", percent)
+		selected := percent == constants.ThumbnailSelectedPercent
+
+		thumbnail, err := q.uploadThumbnail(ctx, video, filepath.Join(thumbnailsDir, name), name, func(localPath string) (float64, error) {
+			return position, q.ffmpeg.GenerateThumbnailAt(ctx, inputFile, localPath, position)
+		})
+		if err != nil {
+			if selected {
+				return "", err
+			}
+			log.Printf("Failed to generate thumbnail at %d%%: %v", percent, err)
+			continue
+		}
+
+		thumbnail.Source = constants.ThumbnailSourceFrame
+		thumbnail.IsSelected = selected
+		thumbnails = append(thumbnails, thumbnail)
+	}
+
+	thumbnail, err := q.uploadThumbnail(ctx, video, filepath.Join(thumbnailsDir, "scene.jpg"), "scene.jpg", func(localPath string) (float64, error) {
+		return q.ffmpeg.GenerateSceneThumbnail(ctx, inputFile, localPath)
+	})
+	if err != nil {
+		log.Printf("Failed to generate scene thumbnail: %v", err)
+	} else {
+		thumbnail.Source = constants.ThumbnailSourceScene
+		thumbnails = append(thumbnails, thumbnail)
+	}
+
+	thumbnailURL, err := q.thumbnailRepo.ReplaceGenerated(ctx, video.ID, thumbnails)
+	if err != nil {
+		return "", fmt.Errorf("failed to save thumbnails: %w", err)
+	}
+	log.Printf("Saved %d thumbnail candidates for video %s", len(thumbnails), video.ID)
+
+	return thumbnailURL, nil
+}
+
+// uploadThumbnail генерирует кадр через generate и загружает его в бакет миниатюр
+func (q *ConversionQueue) uploadThumbnail(
+	ctx context.Context,
+	video *entity.Video,
+	localPath string,
+	name string,
+	generate func(localPath string) (float64, error),
+) (*entity.VideoThumbnail, error) {
+	position, err := generate(localPath)
+	if err != nil {
+		return nil, classify(constants.FailureClassFFmpeg, fmt.Errorf("failed to generate thumbnail: %w", err))
+	}
+
+	storagePath := video.GetThumbnailsPath() + "/" + name
+	if _, err := q.uploadLocalFile(ctx, constants.ThumbnailsBucket, storagePath, localPath); err != nil {
+		return nil, classify(constants.FailureClassUpload, fmt.Errorf("failed to upload thumbnail: %w", err))
+	}
+
+	return &entity.VideoThumbnail{
+		VideoID:  video.ID,
+		Position: &position,
+		URL:      q.storageClient.GetPublicURL(constants.ThumbnailsBucket, storagePath),
+	}, nil
+}
+
+// uploadPreviewClip генерирует превью-ролик для карточки видео и загружает его рядом с обложкой
+func (q *ConversionQueue) uploadPreviewClip(ctx context.Context, video *entity.Video, inputFile string) error {
+	if video.Duration <= 0 {
+		return nil
+	}
+
+	clipPath := filepath.Join(q.ffmpeg.tempDir, strings.TrimSuffix(video.Filename, filepath.Ext(video.Filename))+constants.PreviewClipSuffix)
+	if err := q.ffmpeg.GeneratePreviewClip(ctx, inputFile, clipPath, video.Duration); err != nil {
+		return err
+	}
+	defer q.cleanupTempFile(clipPath)
+
+	if _, err := q.uploadLocalFile(ctx, constants.ThumbnailsBucket, video.GetPreviewClipPath(), clipPath); err != nil {
+		return fmt.Errorf("failed to upload preview clip: %w", err)
+	}
+
+	clipURL := q.storageClient.GetPublicURL(constants.ThumbnailsBucket, video.GetPreviewClipPath())
+	if err := q.videoRepo.UpdatePreviewClip(ctx, video.ID, clipURL); err != nil {
+		return fmt.Errorf("failed to update preview clip url: %w", err)
+	}
+	log.Printf("Uploaded preview clip for video %s", video.ID)
+
+	return nil
+}
+
+// uploadDirectory загружает все файлы локальной папки в storageDir,
+// возвращает количество и суммарный размер файлов
+func (q *ConversionQueue) uploadDirectory(ctx context.Context, bucketName, storageDir, localDir string) (int, int64, error) {
+	entries, err := os.ReadDir(localDir)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	count := 0
+	var size int64
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to get file info %s: %w", entry.Name(), err)
+		}
+
+		if _, err := q.uploadLocalFile(ctx, bucketName, storageDir+"/"+entry.Name(), filepath.Join(localDir, entry.Name())); err != nil {
+			return 0, 0, fmt.Errorf("failed to upload file %s: %w", entry.Name(), err)
+		}
+
+		count++
+		size += info.Size()
+	}
+
+	return count, size, nil
+}
+
+// hlsVariant измеряет битрейт нарезанного качества по его сегментам в хранилище, а кодеки - по файлу качества.
+// Сегменты читаются из хранилища, так как качество могло быть нарезано в прошлой попытке
+func (q *ConversionQueue) hlsVariant(ctx context.Context, video *entity.Video, quality *entity.VideoQuality, convertedFile string) (*HLSVariant, error) {
+	qualityPath := video.GetHLSPath() + "/" + quality.Name
+
+	playlist, err := q.storageClient.ReadFile(ctx, video.BucketID, qualityPath+"/"+constants.HLSVariantPlaylist)
+	if err != nil {
+		return nil, classify(constants.FailureClassDownload, fmt.Errorf("failed to read hls playlist: %w", err))
+	}
+
+	objects, err := q.storageClient.ListFiles(ctx, video.BucketID, qualityPath+"/")
+	if err != nil {
+		return nil, classify(constants.FailureClassDownload, fmt.Errorf("failed to list hls segments: %w", err))
+	}
+	sizes := make(map[string]int64, len(objects))
+	for _, object := range objects {
+		sizes[path.Base(object.Key)] = object.Size
+	}
+
+	peak, average, err := MeasureHLSBitrate(playlist, sizes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure hls bitrate: %w", err)
+	}
+
+	mediaInfo, err := q.ffmpeg.ProbeMedia(ctx, convertedFile)
+	if err != nil {
+		return nil, classify(constants.FailureClassProbe, fmt.Errorf("failed to probe converted file: %w", err))
+	}
+
+	return &HLSVariant{
+		Quality:        quality,
+		PeakBitrate:    peak,
+		AverageBitrate: average,
+		Codecs:         HLSCodecs(mediaInfo),
+	}, nil
+}
+
+// uploadHLSMasterPlaylist перезаписывает мастер-плейлист списком готовых качеств
+func (q *ConversionQueue) uploadHLSMasterPlaylist(ctx context.Context, video *entity.Video, variants []*HLSVariant) error {
+	playlist := BuildHLSMasterPlaylist(variants)
+	objectName := video.GetHLSPath() + "/" + constants.HLSMasterPlaylist
+
+	log.Printf("Uploading hls master playlist with %d qualities to %s", len(variants), objectName)
+	if _, err := q.storageClient.UploadFile(ctx, video.BucketID, objectName, bytes.NewReader(playlist), storage.UploadOptions{Size: int64(len(playlist))}); err != nil {
+		return classify(constants.FailureClassUpload, fmt.Errorf("failed to upload hls master playlist: %w", err))
+	}
+
+	return nil
+}
+
+// uploadLocalFile загружает локальный файл с известным размером, тип содержимого определяется по имени объекта
+func (q *ConversionQueue) uploadLocalFile(ctx context.Context, bucketName, objectName, filePath string) (*storage.ObjectInfo, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	return q.storageClient.UploadFile(ctx, bucketName, objectName, file, storage.UploadOptions{Size: info.Size()})
+}
+
+func (q *ConversionQueue) cleanupTempDir(dir string) {
+	log.Printf("Cleaning up temp dir: %s", dir)
+	if err := os.RemoveAll(dir); err != nil {
+		log.Printf("Failed to remove temp dir %s: %v", dir, err)
+	}
+}
+
 func (q *ConversionQueue) cleanupTempFile(filename string) {
 	log.Printf("Cleaning up temp file: %s", filename)
 	if err := os.Remove(filename); err != nil {
@@ -286,6 +1297,8 @@ func (q *ConversionQueue) ConvertVideo(ctx context.Context, video *entity.Video,
 	q.activeConversions.Store(video.ID, true)
 	defer q.activeConversions.Delete(video.ID)
 
+	quality = quality.ScaleToSource(video.SourceSize())
+
 	originalFilePath := video.GetStorageFilePath(constants.VideoQualityOriginal)
 	inputFile := filepath.Join(q.ffmpeg.tempDir, video.Filename)
 
@@ -296,5 +1309,16 @@ func (q *ConversionQueue) ConvertVideo(ctx context.Context, video *entity.Video,
 	}
 	defer q.cleanupTempFile(inputFile)
 
-	return q.convertToQuality(ctx, video, quality, inputFile)
+	profiles, err := q.transcodeProfiles(ctx)
+	if err != nil {
+		return err
+	}
+
+	outputFile, err := q.convertToQuality(ctx, video, quality, profiles[0], inputFile)
+	if err != nil {
+		return err
+	}
+	q.cleanupTempFile(outputFile)
+
+	return nil
 }
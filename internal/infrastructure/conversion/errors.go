@@ -0,0 +1,45 @@
+package conversion
+
+import (
+	"errors"
+
+	"github.com/mrkbwp/gotube/pkg/constants"
+)
+
+// ConversionError ошибка конвертации с классом сбоя, по которому выбирается политика повторов
+type ConversionError struct {
+	Class string
+	Err   error
+}
+
+func (e *ConversionError) Error() string {
+	return e.Class + ": " + e.Err.Error()
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// classify помечает ошибку классом сбоя, уже классифицированные ошибки не меняются
+func classify(class string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var convErr *ConversionError
+	if errors.As(err, &convErr) {
+		return err
+	}
+
+	return &ConversionError{Class: class, Err: err}
+}
+
+// failureClass возвращает класс сбоя ошибки, неклассифицированные считаются внутренними
+func failureClass(err error) string {
+	var convErr *ConversionError
+	if errors.As(err, &convErr) {
+		return convErr.Class
+	}
+
+	return constants.FailureClassInternal
+}
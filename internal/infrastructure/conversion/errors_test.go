@@ -0,0 +1,45 @@
+package conversion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mrkbwp/gotube/pkg/constants"
+)
+
+func TestFailureClass(t *testing.T) {
+	base := errors.New("boom")
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "unclassified", err: base, want: constants.FailureClassInternal},
+		{name: "classified", err: classify(constants.FailureClassFFmpeg, base), want: constants.FailureClassFFmpeg},
+		{name: "wrapped", err: fmt.Errorf("failed to convert: %w", classify(constants.FailureClassUpload, base)), want: constants.FailureClassUpload},
+		{name: "first class wins", err: classify(constants.FailureClassDownload, classify(constants.FailureClassProbe, base)), want: constants.FailureClassProbe},
+		{name: "context canceled", err: context.Canceled, want: constants.FailureClassInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failureClass(tt.err); got != tt.want {
+				t.Errorf("failureClass() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyKeepsCause(t *testing.T) {
+	if classify(constants.FailureClassFFmpeg, nil) != nil {
+		t.Fatal("classify(nil) must stay nil")
+	}
+
+	err := classify(constants.FailureClassFFmpeg, context.DeadlineExceeded)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("classify() lost the cause: %v", err)
+	}
+}
@@ -1,14 +1,20 @@
 package conversion
 
 import (
+	"context"
 	"fmt"
 	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/pkg/constants"
 	"log"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
 
+// FFmpegService запускает ffmpeg и ffprobe. Процессы привязаны к контексту задания
+// и завершаются, когда воркер теряет аренду или останавливается
 type FFmpegService struct {
 	tempDir string
 }
@@ -19,71 +25,257 @@ func NewFFmpegService(tempDir string) *FFmpegService {
 	}
 }
 
-func (s *FFmpegService) ConvertVideo(inputPath string, outputPath string, quality *entity.VideoQuality) error {
-	cmd := exec.Command("ffmpeg",
+// ConvertVideo конвертирует видео в качество профилем транскодирования, в файл попадают потоки streams.
+// Если переданы замеры loudness,
+// звук нормализуется вторым проходом loudnorm. Если передан onProgress,
+// он вызывается при каждом изменении процента готовности, посчитанного от длительности в секундах
+func (s *FFmpegService) ConvertVideo(ctx context.Context, inputPath string, outputPath string, quality *entity.VideoQuality, profile *entity.TranscodeProfile, streams StreamMap, loudness *LoudnessInfo, duration int, onProgress func(percent int)) error {
+	encoder, err := encoderArgs(profile, quality)
+	if err != nil {
+		return err
+	}
+
+	args := []string{
 		"-i", inputPath,
-		"-c:v", "libx264",
-		"-b:v", fmt.Sprintf("%dk", quality.Bitrate),
-		"-vf", fmt.Sprintf("scale=%d:%d", quality.Width, quality.Height),
-		"-c:a", "aac",
+		// Размеры качества уже посчитаны по пропорциям исходника (entity.SelectRenditions)
+		"-vf", fmt.Sprintf("scale=%d:%d,setsar=1", quality.Width, quality.Height),
+	}
+	args = append(args, streams.args()...)
+	args = append(args, encoder...)
+	args = append(args, loudnessArgs(loudness)...)
+	args = append(args,
+		"-progress", "pipe:1",
+		"-nostats",
 		"-y",
 		outputPath,
 	)
 
-	return cmd.Run()
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+
+	if onProgress == nil {
+		return cmd.Run()
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to get ffmpeg output: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	parseProgress(stdout, duration, onProgress)
+
+	return cmd.Wait()
 }
 
-func (s *FFmpegService) GetVideoInfo(inputPath string) (duration int, err error) {
-	log.Printf("Getting video info for: %s", inputPath)
+// ConvertAudio сохраняет звуковой поток audioIndex без видео звуковым кодеком профиля
+func (s *FFmpegService) ConvertAudio(ctx context.Context, inputPath string, outputPath string, profile *entity.TranscodeProfile, audioIndex int, loudness *LoudnessInfo) error {
+	encoder, err := audioEncoderArgs(profile)
+	if err != nil {
+		return err
+	}
+
+	args := []string{
+		"-i", inputPath,
+		"-map", fmt.Sprintf("0:%d", audioIndex),
+		"-vn",
+	}
+	args = append(args, encoder...)
+	args = append(args, loudnessArgs(loudness)...)
+	args = append(args, "-y", outputPath)
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		log.Printf("ffmpeg audio output: %s", string(output))
+		return fmt.Errorf("failed to convert audio: %w", err)
+	}
+
+	return nil
+}
+
+// ExtractSubtitles конвертирует текстовый поток субтитров subtitleIndex в WebVTT
+func (s *FFmpegService) ExtractSubtitles(ctx context.Context, inputPath string, outputPath string, subtitleIndex int) error {
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-i", inputPath,
+		"-map", fmt.Sprintf("0:%d", subtitleIndex),
+		"-c:s", "webvtt",
+		"-f", "webvtt",
+		"-y",
+		outputPath,
+	)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		log.Printf("ffmpeg subtitles output: %s", string(output))
+		return fmt.Errorf("failed to extract subtitles: %w", err)
+	}
+
+	return nil
+}
+
+// SegmentHLS нарезает сконвертированный файл на HLS сегменты без перекодирования.
+// Сегменты fMP4, а не MPEG-TS, чтобы в них помещался любой кодек профиля по умолчанию
+func (s *FFmpegService) SegmentHLS(ctx context.Context, inputPath string, outputDir string) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create hls directory: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-i", inputPath,
+		"-c", "copy",
+		"-f", "hls",
+		"-hls_time", strconv.Itoa(constants.HLSSegmentDuration),
+		"-hls_playlist_type", "vod",
+		"-hls_segment_type", "fmp4",
+		"-hls_fmp4_init_filename", constants.HLSInitSegment,
+		"-hls_segment_filename", filepath.Join(outputDir, constants.HLSSegmentPattern),
+		"-y",
+		filepath.Join(outputDir, constants.HLSVariantPlaylist),
+	)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		log.Printf("ffmpeg hls output: %s", string(output))
+		return fmt.Errorf("failed to segment hls: %w", err)
+	}
+
+	return nil
+}
+
+// PackageDASH упаковывает готовые качества в один DASH манифест с CMAF сегментами.
+// Видео берется из каждого файла, звук только из первого
+func (s *FFmpegService) PackageDASH(ctx context.Context, inputPaths []string, outputDir string) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create dash directory: %w", err)
+	}
+
+	hasAudio, err := s.HasAudioStream(ctx, inputPaths[0])
+	if err != nil {
+		return err
+	}
+
+	args := []string{}
+	for _, inputPath := range inputPaths {
+		args = append(args, "-i", inputPath)
+	}
+	for i := range inputPaths {
+		args = append(args, "-map", fmt.Sprintf("%d:v:0", i))
+	}
+
+	adaptationSets := "id=0,streams=v"
+	if hasAudio {
+		args = append(args, "-map", "0:a:0")
+		adaptationSets += " id=1,streams=a"
+	}
+
+	args = append(args,
+		"-c", "copy",
+		"-f", "dash",
+		"-seg_duration", strconv.Itoa(constants.DASHSegmentDuration),
+		// Явный список сегментов, чтобы при отдаче манифеста подставить временные ссылки
+		"-use_template", "0",
+		"-use_timeline", "0",
+		"-dash_segment_type", "mp4",
+		"-adaptation_sets", adaptationSets,
+		"-y",
+		filepath.Join(outputDir, constants.DASHManifest),
+	)
 
-	cmd := exec.Command("ffprobe",
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		log.Printf("ffmpeg dash output: %s", string(output))
+		return fmt.Errorf("failed to package dash: %w", err)
+	}
+
+	return nil
+}
+
+// HasAudioStream проверяет наличие звуковой дорожки в файле
+func (s *FFmpegService) HasAudioStream(ctx context.Context, inputPath string) (bool, error) {
+	cmd := exec.CommandContext(ctx, "ffprobe",
 		"-v", "error",
-		"-select_streams", "v:0",
-		"-show_entries", "format=duration",
-		"-of", "default=noprint_wrappers=1:nokey=1",
+		"-select_streams", "a",
+		"-show_entries", "stream=index",
+		"-of", "csv=p=0",
 		inputPath,
 	)
 
 	output, err := cmd.Output()
 	if err != nil {
-		log.Printf("ffprobe command failed: %v", err)
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			log.Printf("ffprobe stderr: %s", string(exitErr.Stderr))
-		}
-		return 0, fmt.Errorf("ffprobe failed: %w", err)
+		return false, fmt.Errorf("ffprobe failed: %w", err)
 	}
 
-	durationStr := strings.TrimSpace(string(output))
-	log.Printf("Raw duration output: '%s'", durationStr)
+	return strings.TrimSpace(string(output)) != "", nil
+}
 
-	durationFloat, err := strconv.ParseFloat(durationStr, 64)
-	if err != nil {
-		log.Printf("Failed to parse duration string: %v", err)
-		return 0, fmt.Errorf("failed to parse duration '%s': %w", durationStr, err)
+// GenerateSprites сохраняет кадры через layout.Interval секунд, сложенные в спрайты
+// layout.Columns x layout.Rows, в файлы SpriteImagePattern начиная с 1
+func (s *FFmpegService) GenerateSprites(ctx context.Context, inputPath string, outputDir string, layout SpriteLayout) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create sprites directory: %w", err)
 	}
 
-	duration = int(durationFloat)
-	log.Printf("Parsed duration: %d seconds", duration)
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-i", inputPath,
+		"-vf", fmt.Sprintf("fps=1/%d,scale=%d:%d,tile=%dx%d",
+			layout.Interval,
+			layout.FrameWidth,
+			layout.FrameHeight,
+			layout.Columns,
+			layout.Rows,
+		),
+		"-an",
+		"-q:v", "5",
+		"-y",
+		filepath.Join(outputDir, constants.SpriteImagePattern),
+	)
 
-	return duration, nil
+	if output, err := cmd.CombinedOutput(); err != nil {
+		log.Printf("ffmpeg sprites output: %s", string(output))
+		return fmt.Errorf("failed to generate sprites: %w", err)
+	}
+
+	return nil
 }
 
-func (s *FFmpegService) GenerateThumbnail(inputPath string, outputPath string) error {
-	// Сначала получаем длительность видео
-	duration, err := s.GetVideoInfo(inputPath)
-	if err != nil {
-		return fmt.Errorf("failed to get video duration: %w", err)
+// GeneratePreviewClip сохраняет короткий ролик без звука из фрагментов видео
+func (s *FFmpegService) GeneratePreviewClip(ctx context.Context, inputPath string, outputPath string, duration int) error {
+	segmentSeconds := constants.PreviewClipSegmentSeconds
+	starts := PreviewClipSegments(duration)
+	if len(starts) == 1 {
+		segmentSeconds = float64(duration)
 	}
 
-	// Берем кадр из середины видео
-	middleTime := duration / 2
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-i", inputPath,
+		"-vf", previewClipFilter(starts, segmentSeconds),
+		"-an",
+		"-c:v", "libx264",
+		"-preset", "veryfast",
+		"-crf", strconv.Itoa(constants.PreviewClipCRF),
+		"-pix_fmt", "yuv420p",
+		"-movflags", "+faststart",
+		"-y",
+		outputPath,
+	)
 
-	cmd := exec.Command("ffmpeg",
-		"-ss", fmt.Sprintf("%d", middleTime),
+	if output, err := cmd.CombinedOutput(); err != nil {
+		log.Printf("ffmpeg preview clip output: %s", string(output))
+		return fmt.Errorf("failed to generate preview clip: %w", err)
+	}
+
+	return nil
+}
+
+// GenerateThumbnailAt сохраняет кадр на указанной секунде видео
+func (s *FFmpegService) GenerateThumbnailAt(ctx context.Context, inputPath string, outputPath string, seconds float64) error {
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-ss", strconv.FormatFloat(seconds, 'f', 3, 64),
 		"-i", inputPath,
 		"-vframes", "1",
 		"-q:v", "2",
 		"-f", "image2",
+		"-y",
 		outputPath,
 	)
 
@@ -93,3 +285,45 @@ func (s *FFmpegService) GenerateThumbnail(inputPath string, outputPath string) e
 
 	return nil
 }
+
+// GenerateSceneThumbnail сохраняет первый кадр смены сцены и возвращает его секунду
+func (s *FFmpegService) GenerateSceneThumbnail(ctx context.Context, inputPath string, outputPath string) (float64, error) {
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-i", inputPath,
+		"-vf", fmt.Sprintf("select='gt(scene,%g)',showinfo", constants.ThumbnailSceneThreshold),
+		"-frames:v", "1",
+		"-vsync", "vfr",
+		"-an",
+		"-q:v", "2",
+		"-y",
+		outputPath,
+	)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		log.Printf("ffmpeg scene thumbnail output: %s", string(output))
+		return 0, fmt.Errorf("failed to generate scene thumbnail: %w", err)
+	}
+
+	// Кадр не сохраняется, если смены сцены не нашлось
+	if _, err := os.Stat(outputPath); err != nil {
+		return 0, fmt.Errorf("scene change not found: %w", err)
+	}
+
+	return parseShowinfoTime(string(output)), nil
+}
+
+// parseShowinfoTime возвращает pts_time первого кадра из вывода фильтра showinfo
+func parseShowinfoTime(output string) float64 {
+	idx := strings.Index(output, "pts_time:")
+	if idx < 0 {
+		return 0
+	}
+
+	fields := strings.Fields(output[idx+len("pts_time:"):])
+	if len(fields) == 0 {
+		return 0
+	}
+
+	return parseFloat(fields[0])
+}
@@ -0,0 +1,176 @@
+package conversion
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/pkg/constants"
+)
+
+// HLSVariant качество мастер-плейлиста с параметрами, измеренными по готовым сегментам
+type HLSVariant struct {
+	Quality *entity.VideoQuality
+	// PeakBitrate - наибольший битрейт сегмента в бит/с, AverageBitrate - средний по всем сегментам
+	PeakBitrate    int64
+	AverageBitrate int64
+	// Codecs - кодеки в формате RFC 6381 (avc1.64001f,mp4a.40.2), пустая строка если их не удалось определить
+	Codecs string
+}
+
+// h264Profiles - profile_idc и флаги ограничений avc1 по имени профиля ffprobe
+var h264Profiles = map[string]string{
+	"Constrained Baseline": "42E0",
+	"Baseline":             "4200",
+	"Main":                 "4D40",
+	"High":                 "6400",
+	"High 10":              "6E00",
+	"High 4:2:2":           "7A00",
+}
+
+// h265Profiles - профиль и флаги совместимости hvc1 по имени профиля ffprobe
+var h265Profiles = map[string]string{
+	"Main":    "1.6",
+	"Main 10": "2.4",
+}
+
+// BuildHLSMasterPlaylist формирует мастер-плейлист по списку готовых качеств.
+// Плейлисты качеств указываются относительными путями: <quality>/index.m3u8,
+// токен ссылки доступа зрителя к ним дописывается при отдаче плейлиста
+func BuildHLSMasterPlaylist(variants []*HLSVariant) []byte {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	// Сегменты fMP4 поддерживаются с седьмой версии
+	b.WriteString("#EXT-X-VERSION:7\n")
+
+	for _, variant := range variants {
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:BANDWIDTH=%d,AVERAGE-BANDWIDTH=%d", variant.PeakBitrate, variant.AverageBitrate)
+		if variant.Codecs != "" {
+			fmt.Fprintf(&b, ",CODECS=\"%s\"", variant.Codecs)
+		}
+		fmt.Fprintf(&b, ",RESOLUTION=%dx%d,NAME=\"%s\"\n",
+			variant.Quality.Width,
+			variant.Quality.Height,
+			variant.Quality.Name,
+		)
+		fmt.Fprintf(&b, "%s/%s\n", variant.Quality.Name, constants.HLSVariantPlaylist)
+	}
+
+	return []byte(b.String())
+}
+
+// MeasureHLSBitrate считает пиковый и средний битрейт плейлиста качества по длительностям сегментов
+// из EXTINF и их размерам sizes по имени в плейлисте. Инициализирующий сегмент не учитывается
+func MeasureHLSBitrate(playlist []byte, sizes map[string]int64) (peak, average int64, err error) {
+	var totalDuration float64
+	var totalBytes int64
+	duration := 0.0
+
+	for _, line := range strings.Split(string(playlist), "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(line, "#EXTINF:"):
+			value, _, _ := strings.Cut(strings.TrimPrefix(line, "#EXTINF:"), ",")
+			duration, err = strconv.ParseFloat(value, 64)
+			if err != nil || duration <= 0 {
+				return 0, 0, fmt.Errorf("invalid segment duration %q", value)
+			}
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		default:
+			size, ok := sizes[line]
+			if !ok {
+				return 0, 0, fmt.Errorf("segment %s not found", line)
+			}
+			if duration <= 0 {
+				return 0, 0, fmt.Errorf("segment %s has no duration", line)
+			}
+
+			peak = max(peak, int64(math.Ceil(float64(size*8)/duration)))
+			totalDuration += duration
+			totalBytes += size
+			duration = 0
+		}
+	}
+
+	if totalDuration == 0 {
+		return 0, 0, fmt.Errorf("playlist has no segments")
+	}
+
+	return peak, int64(math.Ceil(float64(totalBytes*8) / totalDuration)), nil
+}
+
+// HLSCodecs возвращает кодеки файла качества в формате RFC 6381 для атрибута CODECS.
+// Пустая строка, если профиль или уровень какого-то потока неизвестен
+func HLSCodecs(info *MediaInfo) string {
+	if info.Video == nil {
+		return ""
+	}
+
+	videoCodec := videoCodecString(info.Video)
+	if videoCodec == "" {
+		return ""
+	}
+	if info.Audio == nil {
+		return videoCodec
+	}
+
+	audioCodec := audioCodecString(info.Audio)
+	if audioCodec == "" {
+		return ""
+	}
+
+	return videoCodec + "," + audioCodec
+}
+
+func videoCodecString(video *VideoStreamInfo) string {
+	if video.Level <= 0 {
+		return ""
+	}
+
+	switch video.Codec {
+	case "h264":
+		if profile, ok := h264Profiles[video.Profile]; ok {
+			return fmt.Sprintf("avc1.%s%02X", profile, video.Level)
+		}
+	case "hevc":
+		if profile, ok := h265Profiles[video.Profile]; ok {
+			return fmt.Sprintf("hvc1.%s.L%d.B0", profile, video.Level)
+		}
+	case "vp9":
+		profile, err := strconv.Atoi(strings.TrimPrefix(video.Profile, "Profile "))
+		if err == nil {
+			return fmt.Sprintf("vp09.%02d.%02d.%02d", profile, video.Level, video.BitDepth())
+		}
+	case "av1":
+		switch video.Profile {
+		case "Main":
+			return fmt.Sprintf("av01.0.%02dM.%02d", video.Level, video.BitDepth())
+		case "High":
+			return fmt.Sprintf("av01.1.%02dM.%02d", video.Level, video.BitDepth())
+		}
+	}
+
+	return ""
+}
+
+func audioCodecString(audio *AudioStreamInfo) string {
+	switch audio.Codec {
+	case "aac":
+		switch audio.Profile {
+		case "HE-AAC":
+			return "mp4a.40.5"
+		case "HE-AACv2":
+			return "mp4a.40.29"
+		default:
+			return "mp4a.40.2"
+		}
+	case "opus":
+		return "Opus"
+	}
+
+	return ""
+}
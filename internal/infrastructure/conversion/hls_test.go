@@ -0,0 +1,115 @@
+package conversion
+
+import (
+	"testing"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+)
+
+func TestMeasureHLSBitrate(t *testing.T) {
+	playlist := []byte(`#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-TARGETDURATION:6
+#EXT-X-PLAYLIST-TYPE:VOD
+#EXT-X-MAP:URI="init.mp4"
+#EXTINF:6.000000,
+segment_000.m4s
+#EXTINF:6.000000,
+segment_001.m4s
+#EXTINF:2.000000,
+segment_002.m4s
+#EXT-X-ENDLIST
+`)
+
+	tests := []struct {
+		name        string
+		playlist    []byte
+		sizes       map[string]int64
+		wantPeak    int64
+		wantAverage int64
+		wantErr     bool
+	}{
+		{
+			name:        "peak segment",
+			playlist:    playlist,
+			sizes:       map[string]int64{"init.mp4": 900, "segment_000.m4s": 750000, "segment_001.m4s": 1500000, "segment_002.m4s": 250000},
+			wantPeak:    2000000,
+			wantAverage: 1428572,
+		},
+		{
+			name:     "missing segment",
+			playlist: playlist,
+			sizes:    map[string]int64{"segment_000.m4s": 750000, "segment_001.m4s": 1500000},
+			wantErr:  true,
+		},
+		{name: "no segments", playlist: []byte("#EXTM3U\n#EXT-X-ENDLIST\n"), wantErr: true},
+		{name: "bad duration", playlist: []byte("#EXTM3U\n#EXTINF:abc,\nsegment_000.m4s\n"), sizes: map[string]int64{"segment_000.m4s": 1}, wantErr: true},
+		{name: "segment without duration", playlist: []byte("#EXTM3U\nsegment_000.m4s\n"), sizes: map[string]int64{"segment_000.m4s": 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peak, average, err := MeasureHLSBitrate(tt.playlist, tt.sizes)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("MeasureHLSBitrate() = %d, %d, want error", peak, average)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MeasureHLSBitrate(): %v", err)
+			}
+			if peak != tt.wantPeak || average != tt.wantAverage {
+				t.Errorf("MeasureHLSBitrate() = %d, %d, want %d, %d", peak, average, tt.wantPeak, tt.wantAverage)
+			}
+		})
+	}
+}
+
+func TestHLSCodecs(t *testing.T) {
+	aac := &AudioStreamInfo{Codec: "aac", Profile: "LC"}
+
+	tests := []struct {
+		name string
+		info *MediaInfo
+		want string
+	}{
+		{name: "h264 high aac", info: &MediaInfo{Video: &VideoStreamInfo{Codec: "h264", Profile: "High", Level: 31}, Audio: aac}, want: "avc1.64001F,mp4a.40.2"},
+		{name: "h264 main without audio", info: &MediaInfo{Video: &VideoStreamInfo{Codec: "h264", Profile: "Main", Level: 40}}, want: "avc1.4D4028"},
+		{name: "h264 constrained baseline", info: &MediaInfo{Video: &VideoStreamInfo{Codec: "h264", Profile: "Constrained Baseline", Level: 30}, Audio: aac}, want: "avc1.42E01E,mp4a.40.2"},
+		{name: "h265 main 10 he-aac", info: &MediaInfo{Video: &VideoStreamInfo{Codec: "hevc", Profile: "Main 10", Level: 120}, Audio: &AudioStreamInfo{Codec: "aac", Profile: "HE-AAC"}}, want: "hvc1.2.4.L120.B0,mp4a.40.5"},
+		{name: "vp9 opus", info: &MediaInfo{Video: &VideoStreamInfo{Codec: "vp9", Profile: "Profile 0", Level: 31, PixelFormat: "yuv420p"}, Audio: &AudioStreamInfo{Codec: "opus"}}, want: "vp09.00.31.08,Opus"},
+		{name: "av1 10 bit", info: &MediaInfo{Video: &VideoStreamInfo{Codec: "av1", Profile: "Main", Level: 8, PixelFormat: "yuv420p10le"}, Audio: &AudioStreamInfo{Codec: "opus"}}, want: "av01.0.08M.10,Opus"},
+		{name: "unknown level", info: &MediaInfo{Video: &VideoStreamInfo{Codec: "vp9", Profile: "Profile 0", Level: -99}, Audio: aac}, want: ""},
+		{name: "unknown profile", info: &MediaInfo{Video: &VideoStreamInfo{Codec: "h264", Profile: "High 4:4:4 Predictive", Level: 31}, Audio: aac}, want: ""},
+		{name: "unknown audio", info: &MediaInfo{Video: &VideoStreamInfo{Codec: "h264", Profile: "High", Level: 31}, Audio: &AudioStreamInfo{Codec: "mp3"}}, want: ""},
+		{name: "no video", info: &MediaInfo{Audio: aac}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HLSCodecs(tt.info); got != tt.want {
+				t.Errorf("HLSCodecs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildHLSMasterPlaylist(t *testing.T) {
+	variants := []*HLSVariant{
+		{Quality: &entity.VideoQuality{Name: "360p", Width: 640, Height: 360}, PeakBitrate: 900000, AverageBitrate: 700000, Codecs: "avc1.64001E,mp4a.40.2"},
+		{Quality: &entity.VideoQuality{Name: "720p", Width: 1280, Height: 720}, PeakBitrate: 3100000, AverageBitrate: 2400000},
+	}
+
+	want := `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-STREAM-INF:BANDWIDTH=900000,AVERAGE-BANDWIDTH=700000,CODECS="avc1.64001E,mp4a.40.2",RESOLUTION=640x360,NAME="360p"
+360p/index.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=3100000,AVERAGE-BANDWIDTH=2400000,RESOLUTION=1280x720,NAME="720p"
+720p/index.m3u8
+`
+
+	if got := string(BuildHLSMasterPlaylist(variants)); got != want {
+		t.Errorf("BuildHLSMasterPlaylist() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,137 @@
+package conversion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/pkg/constants"
+)
+
+// LoudnessInfo замеры громкости первого прохода loudnorm
+type LoudnessInfo struct {
+	Integrated float64
+	TruePeak   float64
+	Range      float64
+	Threshold  float64
+	Offset     float64
+}
+
+// loudnormOutput JSON, который loudnorm печатает в конце вывода, числа приходят строками
+type loudnormOutput struct {
+	InputI       string `json:"input_i"`
+	InputTP      string `json:"input_tp"`
+	InputLRA     string `json:"input_lra"`
+	InputThresh  string `json:"input_thresh"`
+	TargetOffset string `json:"target_offset"`
+}
+
+// MeasureLoudness первым проходом loudnorm замеряет громкость звукового потока audioIndex
+func (s *FFmpegService) MeasureLoudness(ctx context.Context, inputPath string, audioIndex int) (*LoudnessInfo, error) {
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-i", inputPath,
+		"-map", fmt.Sprintf("0:%d", audioIndex),
+		"-af", loudnormTarget()+":print_format=json",
+		"-f", "null",
+		"-",
+	)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		log.Printf("ffmpeg loudness output: %s", string(output))
+		return nil, fmt.Errorf("failed to measure loudness: %w", err)
+	}
+
+	return parseLoudnorm(string(output))
+}
+
+// parseLoudnorm находит в выводе ffmpeg последний JSON-объект с замерами
+func parseLoudnorm(output string) (*LoudnessInfo, error) {
+	start := strings.LastIndex(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("loudnorm measurements not found")
+	}
+
+	var parsed loudnormOutput
+	if err := json.Unmarshal([]byte(output[start:end+1]), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse loudnorm measurements: %w", err)
+	}
+
+	// У тишины громкость -inf, нормализовать нечего
+	if strings.Contains(parsed.InputI, "inf") {
+		return nil, fmt.Errorf("audio is silent")
+	}
+
+	return &LoudnessInfo{
+		Integrated: parseFloat(parsed.InputI),
+		TruePeak:   parseFloat(parsed.InputTP),
+		Range:      parseFloat(parsed.InputLRA),
+		Threshold:  parseFloat(parsed.InputThresh),
+		Offset:     parseFloat(parsed.TargetOffset),
+	}, nil
+}
+
+// Metadata возвращает замеры громкости для сохранения в метаданные видео
+func (l *LoudnessInfo) Metadata() entity.Metadata {
+	return entity.Metadata{
+		constants.MetadataLoudnessIntegrated: l.Integrated,
+		constants.MetadataLoudnessTruePeak:   l.TruePeak,
+		constants.MetadataLoudnessRange:      l.Range,
+		constants.MetadataLoudnessThreshold:  l.Threshold,
+		constants.MetadataLoudnessOffset:     l.Offset,
+	}
+}
+
+// LoudnessFromMetadata читает замеры громкости из метаданных видео, nil если их нет
+func LoudnessFromMetadata(metadata entity.Metadata) *LoudnessInfo {
+	integrated, ok := metadata.Float(constants.MetadataLoudnessIntegrated)
+	if !ok {
+		return nil
+	}
+
+	info := &LoudnessInfo{Integrated: integrated}
+	info.TruePeak, _ = metadata.Float(constants.MetadataLoudnessTruePeak)
+	info.Range, _ = metadata.Float(constants.MetadataLoudnessRange)
+	info.Threshold, _ = metadata.Float(constants.MetadataLoudnessThreshold)
+	info.Offset, _ = metadata.Float(constants.MetadataLoudnessOffset)
+
+	return info
+}
+
+// Filter возвращает фильтр второго прохода loudnorm с замерами первого.
+// linear=true сохраняет динамику, если замеры позволяют обойтись одним усилением
+func (l *LoudnessInfo) Filter() string {
+	return fmt.Sprintf("%s:measured_I=%.2f:measured_TP=%.2f:measured_LRA=%.2f:measured_thresh=%.2f:offset=%.2f:linear=true",
+		loudnormTarget(),
+		l.Integrated,
+		l.TruePeak,
+		l.Range,
+		l.Threshold,
+		l.Offset,
+	)
+}
+
+func loudnormTarget() string {
+	return fmt.Sprintf("loudnorm=I=%.1f:TP=%.1f:LRA=%.1f",
+		constants.LoudnessTargetIntegrated,
+		constants.LoudnessTargetTruePeak,
+		constants.LoudnessTargetRange,
+	)
+}
+
+// loudnessArgs возвращает аргументы ffmpeg второго прохода нормализации, пустые без замеров
+func loudnessArgs(loudness *LoudnessInfo) []string {
+	if loudness == nil {
+		return nil
+	}
+
+	return []string{
+		"-af", loudness.Filter(),
+		"-ar", fmt.Sprintf("%d", constants.AudioSampleRate),
+	}
+}
@@ -0,0 +1,41 @@
+package conversion
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mrkbwp/gotube/pkg/constants"
+)
+
+// PreviewClipSegments возвращает начала фрагментов превью-ролика в секундах.
+// Фрагменты берутся из середин равных частей видео, чтобы не попадали заставка и титры.
+// Видео короче всех фрагментов целиком попадает в один фрагмент
+func PreviewClipSegments(duration int) []float64 {
+	total := constants.PreviewClipSegments * constants.PreviewClipSegmentSeconds
+	if float64(duration) <= total {
+		return []float64{0}
+	}
+
+	part := float64(duration) / constants.PreviewClipSegments
+	starts := make([]float64, 0, constants.PreviewClipSegments)
+	for i := 0; i < constants.PreviewClipSegments; i++ {
+		starts = append(starts, part*float64(i)+(part-constants.PreviewClipSegmentSeconds)/2)
+	}
+
+	return starts
+}
+
+// previewClipFilter собирает фильтр, оставляющий только кадры фрагментов
+// и склеивающий их без пауз
+func previewClipFilter(starts []float64, segmentSeconds float64) string {
+	ranges := make([]string, 0, len(starts))
+	for _, start := range starts {
+		ranges = append(ranges, fmt.Sprintf("between(t,%.3f,%.3f)", start, start+segmentSeconds))
+	}
+
+	return fmt.Sprintf("select='%s',setpts=N/FRAME_RATE/TB,fps=%d,scale=%d:-2",
+		strings.Join(ranges, "+"),
+		constants.PreviewClipFPS,
+		constants.PreviewClipWidth,
+	)
+}
@@ -0,0 +1,373 @@
+package conversion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"github.com/mrkbwp/gotube/pkg/subtitles"
+)
+
+// MediaInfo параметры исходного файла по данным ffprobe
+type MediaInfo struct {
+	// Container - список форматов ffprobe, например mov,mp4,m4a,3gp,3g2,mj2
+	Container    string
+	Duration     float64
+	BitRate      int64
+	CreationTime string
+
+	// Video - первый видеопоток, nil если его нет
+	Video *VideoStreamInfo
+	// Audio - основной звуковой поток (с признаком default или первый), nil если его нет
+	Audio *AudioStreamInfo
+
+	// AudioTracks - все звуковые потоки в порядке следования в файле
+	AudioTracks []*AudioStreamInfo
+	// SubtitleTracks - все потоки субтитров в порядке следования в файле
+	SubtitleTracks []*SubtitleStreamInfo
+}
+
+// VideoStreamInfo параметры видеопотока
+type VideoStreamInfo struct {
+	// Index - номер потока в файле для -map 0:<index>
+	Index       int
+	Codec       string
+	Profile     string
+	Level       int
+	PixelFormat string
+	BitRate     int64
+	// Width и Height - размеры кадра, как он закодирован
+	Width  int
+	Height int
+	FPS    float64
+	// Rotation - поворот при показе по часовой стрелке: 0, 90, 180 или 270
+	Rotation       int
+	ColorPrimaries string
+	ColorTransfer  string
+}
+
+// AudioStreamInfo параметры звукового потока
+type AudioStreamInfo struct {
+	Index      int
+	Codec      string
+	Profile    string
+	Channels   int
+	SampleRate int
+	BitRate    int64
+	// Language - язык из тегов контейнера в формате BCP 47, пустой если не указан
+	Language  string
+	Title     string
+	IsDefault bool
+}
+
+// SubtitleStreamInfo параметры потока субтитров
+type SubtitleStreamInfo struct {
+	Index    int
+	Codec    string
+	Language string
+	Title    string
+	IsForced bool
+}
+
+// textSubtitleCodecs - текстовые форматы субтитров, которые ffmpeg конвертирует в WebVTT.
+// Графические (hdmv_pgs_subtitle, dvd_subtitle) требуют распознавания и пропускаются
+var textSubtitleCodecs = map[string]bool{
+	"subrip":   true,
+	"srt":      true,
+	"ass":      true,
+	"ssa":      true,
+	"webvtt":   true,
+	"mov_text": true,
+	"text":     true,
+}
+
+// IsText сообщает, можно ли сконвертировать субтитры в WebVTT
+func (s *SubtitleStreamInfo) IsText() bool {
+	return textSubtitleCodecs[s.Codec]
+}
+
+// DurationSeconds возвращает длительность в целых секундах
+func (m *MediaInfo) DurationSeconds() int {
+	return int(m.Duration)
+}
+
+// DisplaySize возвращает размеры кадра при показе. ffmpeg поворачивает кадры
+// при декодировании, поэтому масштабировать нужно именно эти размеры
+func (m *MediaInfo) DisplaySize() (width, height int) {
+	if m.Video == nil {
+		return 0, 0
+	}
+	if m.Video.Rotation == 90 || m.Video.Rotation == 270 {
+		return m.Video.Height, m.Video.Width
+	}
+	return m.Video.Width, m.Video.Height
+}
+
+// BitDepth возвращает глубину цвета по формату пикселей: 10 и 12 для yuv420p10le и yuv420p12le, иначе 8
+func (v *VideoStreamInfo) BitDepth() int {
+	switch {
+	case strings.Contains(v.PixelFormat, "p10"):
+		return 10
+	case strings.Contains(v.PixelFormat, "p12"):
+		return 12
+	default:
+		return 8
+	}
+}
+
+// HDRFormat возвращает формат HDR по характеристике передачи, пустую строку для SDR
+func (v *VideoStreamInfo) HDRFormat() string {
+	switch v.ColorTransfer {
+	case "smpte2084":
+		return constants.HDRFormatHDR10
+	case "arib-std-b67":
+		return constants.HDRFormatHLG
+	default:
+		return ""
+	}
+}
+
+// Metadata возвращает параметры исходника для сохранения в videos.metadata
+func (m *MediaInfo) Metadata() entity.Metadata {
+	metadata := entity.Metadata{
+		constants.MetadataContainer: m.Container,
+		constants.MetadataDuration:  m.Duration,
+		constants.MetadataBitRate:   m.BitRate,
+		constants.MetadataHasVideo:  m.Video != nil,
+		constants.MetadataHasAudio:  m.Audio != nil,
+	}
+	if m.CreationTime != "" {
+		metadata[constants.MetadataCreationTime] = m.CreationTime
+	}
+
+	if m.Video != nil {
+		width, height := m.DisplaySize()
+		metadata[constants.MetadataVideoCodec] = m.Video.Codec
+		metadata[constants.MetadataVideoProfile] = m.Video.Profile
+		metadata[constants.MetadataPixelFormat] = m.Video.PixelFormat
+		metadata[constants.MetadataVideoBitRate] = m.Video.BitRate
+		metadata[constants.MetadataWidth] = width
+		metadata[constants.MetadataHeight] = height
+		metadata[constants.MetadataFPS] = m.Video.FPS
+		metadata[constants.MetadataRotation] = m.Video.Rotation
+		metadata[constants.MetadataColorPrimaries] = m.Video.ColorPrimaries
+		metadata[constants.MetadataColorTransfer] = m.Video.ColorTransfer
+		metadata[constants.MetadataHDR] = m.Video.HDRFormat() != ""
+		if format := m.Video.HDRFormat(); format != "" {
+			metadata[constants.MetadataHDRFormat] = format
+		}
+	}
+
+	if m.Video != nil {
+		metadata[constants.MetadataVideoStreamIndex] = m.Video.Index
+	}
+
+	if m.Audio != nil {
+		metadata[constants.MetadataAudioStreamIndex] = m.Audio.Index
+		metadata[constants.MetadataAudioCodec] = m.Audio.Codec
+		metadata[constants.MetadataAudioChannels] = m.Audio.Channels
+		metadata[constants.MetadataAudioSampleRate] = m.Audio.SampleRate
+		metadata[constants.MetadataAudioBitRate] = m.Audio.BitRate
+	}
+
+	audioLanguages := make([]string, 0, len(m.AudioTracks))
+	for _, track := range m.AudioTracks {
+		audioLanguages = append(audioLanguages, track.Language)
+	}
+	metadata[constants.MetadataAudioLanguages] = audioLanguages
+
+	subtitleLanguages := make([]string, 0, len(m.SubtitleTracks))
+	for _, track := range m.SubtitleTracks {
+		subtitleLanguages = append(subtitleLanguages, track.Language)
+	}
+	metadata[constants.MetadataSubtitleLanguages] = subtitleLanguages
+
+	return metadata
+}
+
+// ffprobeOutput вывод ffprobe -show_streams -show_format -of json.
+// Числа ffprobe отдает строками, поэтому они разбираются отдельно
+type ffprobeOutput struct {
+	Streams []ffprobeStream `json:"streams"`
+	Format  struct {
+		FormatName string            `json:"format_name"`
+		Duration   string            `json:"duration"`
+		BitRate    string            `json:"bit_rate"`
+		Tags       map[string]string `json:"tags"`
+	} `json:"format"`
+}
+
+type ffprobeStream struct {
+	Index          int               `json:"index"`
+	CodecType      string            `json:"codec_type"`
+	CodecName      string            `json:"codec_name"`
+	Profile        string            `json:"profile"`
+	Level          int               `json:"level"`
+	PixFmt         string            `json:"pix_fmt"`
+	BitRate        string            `json:"bit_rate"`
+	Width          int               `json:"width"`
+	Height         int               `json:"height"`
+	RFrameRate     string            `json:"r_frame_rate"`
+	AvgFrameRate   string            `json:"avg_frame_rate"`
+	ColorPrimaries string            `json:"color_primaries"`
+	ColorTransfer  string            `json:"color_transfer"`
+	Channels       int               `json:"channels"`
+	SampleRate     string            `json:"sample_rate"`
+	Disposition    map[string]int    `json:"disposition"`
+	Tags           map[string]string `json:"tags"`
+	SideDataList   []struct {
+		Rotation *float64 `json:"rotation"`
+	} `json:"side_data_list"`
+}
+
+// ProbeMedia читает параметры контейнера и всех потоков.
+// Обложки (attached_pic) видеопотоком не считаются
+func (s *FFmpegService) ProbeMedia(ctx context.Context, inputPath string) (*MediaInfo, error) {
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-show_streams",
+		"-show_format",
+		"-of", "json",
+		inputPath,
+	)
+
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("ffprobe failed: %w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var result ffprobeOutput
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	info := &MediaInfo{
+		Container:    result.Format.FormatName,
+		Duration:     parseFloat(result.Format.Duration),
+		BitRate:      parseInt(result.Format.BitRate),
+		CreationTime: result.Format.Tags["creation_time"],
+	}
+
+	for _, stream := range result.Streams {
+		switch stream.CodecType {
+		case "video":
+			if info.Video != nil || stream.Disposition["attached_pic"] == 1 {
+				continue
+			}
+			info.Video = parseVideoStream(stream)
+		case "audio":
+			track := &AudioStreamInfo{
+				Index:      stream.Index,
+				Codec:      stream.CodecName,
+				Profile:    stream.Profile,
+				Channels:   stream.Channels,
+				SampleRate: int(parseInt(stream.SampleRate)),
+				BitRate:    parseInt(stream.BitRate),
+				Language:   streamLanguage(stream),
+				Title:      stream.Tags["title"],
+				IsDefault:  stream.Disposition["default"] == 1,
+			}
+			info.AudioTracks = append(info.AudioTracks, track)
+			// Без выбора потоков ffmpeg берет дорожку с наибольшим числом каналов, а не основную
+			if info.Audio == nil || (track.IsDefault && !info.Audio.IsDefault) {
+				info.Audio = track
+			}
+		case "subtitle":
+			info.SubtitleTracks = append(info.SubtitleTracks, &SubtitleStreamInfo{
+				Index:    stream.Index,
+				Codec:    stream.CodecName,
+				Language: streamLanguage(stream),
+				Title:    stream.Tags["title"],
+				IsForced: stream.Disposition["forced"] == 1,
+			})
+		}
+	}
+
+	return info, nil
+}
+
+func parseVideoStream(stream ffprobeStream) *VideoStreamInfo {
+	video := &VideoStreamInfo{
+		Index:          stream.Index,
+		Codec:          stream.CodecName,
+		Profile:        stream.Profile,
+		Level:          stream.Level,
+		PixelFormat:    stream.PixFmt,
+		BitRate:        parseInt(stream.BitRate),
+		Width:          stream.Width,
+		Height:         stream.Height,
+		FPS:            parseFrameRate(stream.AvgFrameRate),
+		ColorPrimaries: stream.ColorPrimaries,
+		ColorTransfer:  stream.ColorTransfer,
+	}
+	if video.FPS == 0 {
+		video.FPS = parseFrameRate(stream.RFrameRate)
+	}
+
+	// Старые контейнеры хранят поворот в теге rotate (по часовой),
+	// новые - в display matrix (против часовой)
+	if rotate, err := strconv.Atoi(stream.Tags["rotate"]); err == nil {
+		video.Rotation = normalizeRotation(rotate)
+	}
+	for _, sideData := range stream.SideDataList {
+		if sideData.Rotation != nil {
+			video.Rotation = normalizeRotation(-int(*sideData.Rotation))
+		}
+	}
+
+	return video
+}
+
+// streamLanguage возвращает язык потока в формате BCP 47. Контейнеры хранят ISO 639-2 (eng, rus),
+// неопределенный язык (und) и нераспознанные теги возвращаются пустой строкой
+func streamLanguage(stream ffprobeStream) string {
+	tag, err := subtitles.NormalizeLanguage(stream.Tags["language"])
+	if err != nil || tag == "und" {
+		return ""
+	}
+	return tag
+}
+
+// parseFrameRate разбирает частоту кадров ffprobe вида 30000/1001
+func parseFrameRate(value string) float64 {
+	num, den, ok := strings.Cut(value, "/")
+	if !ok {
+		return parseFloat(value)
+	}
+
+	d := parseFloat(den)
+	if d == 0 {
+		return 0
+	}
+
+	return parseFloat(num) / d
+}
+
+// parseFloat разбирает число ffprobe, N/A и пустые значения дают 0
+func parseFloat(value string) float64 {
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return result
+}
+
+func parseInt(value string) int64 {
+	result, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return result
+}
+
+func normalizeRotation(degrees int) int {
+	return ((degrees % 360) + 360) % 360
+}
@@ -0,0 +1,121 @@
+package conversion
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/pkg/constants"
+)
+
+// videoEncoders энкодеры ffmpeg для кодеков профилей
+var videoEncoders = map[string]string{
+	constants.VideoCodecH264: "libx264",
+	constants.VideoCodecH265: "libx265",
+	constants.VideoCodecVP9:  "libvpx-vp9",
+	constants.VideoCodecAV1:  "libsvtav1",
+}
+
+// audioEncoders энкодеры ffmpeg для звуковых кодеков профилей
+var audioEncoders = map[string]string{
+	constants.AudioCodecAAC:  "aac",
+	constants.AudioCodecOpus: "libopus",
+}
+
+// encoderArgs формирует аргументы ffmpeg для конвертации качества профилем,
+// битрейт качества задан в бит/с
+func encoderArgs(profile *entity.TranscodeProfile, quality *entity.VideoQuality) ([]string, error) {
+	videoEncoder, ok := videoEncoders[profile.VideoCodec]
+	if !ok {
+		return nil, fmt.Errorf("unsupported video codec %q in profile %s", profile.VideoCodec, profile.Name)
+	}
+	audioEncoder, ok := audioEncoders[profile.AudioCodec]
+	if !ok {
+		return nil, fmt.Errorf("unsupported audio codec %q in profile %s", profile.AudioCodec, profile.Name)
+	}
+
+	args := []string{"-c:v", videoEncoder}
+
+	if profile.Preset != nil && *profile.Preset != "" {
+		// libvpx задает скорость через deadline (good, best, realtime)
+		if profile.VideoCodec == constants.VideoCodecVP9 {
+			args = append(args, "-deadline", *profile.Preset)
+		} else {
+			args = append(args, "-preset", *profile.Preset)
+		}
+	}
+
+	switch profile.RateControl {
+	case constants.RateControlCRF:
+		if profile.CRF == nil {
+			return nil, fmt.Errorf("crf is not set in profile %s", profile.Name)
+		}
+		args = append(args, "-crf", strconv.Itoa(*profile.CRF))
+		// Без ограничения битрейта libvpx в режиме crf требует -b:v 0
+		if profile.VideoCodec == constants.VideoCodecVP9 && profile.MaxRateRatio == nil {
+			args = append(args, "-b:v", "0")
+		}
+	case constants.RateControlBitrate:
+		args = append(args, "-b:v", strconv.Itoa(quality.Bitrate))
+	default:
+		return nil, fmt.Errorf("unsupported rate control %q in profile %s", profile.RateControl, profile.Name)
+	}
+
+	if profile.MaxRateRatio != nil {
+		args = append(args, "-maxrate", strconv.Itoa(int(float64(quality.Bitrate)**profile.MaxRateRatio)))
+	}
+	if profile.BufSizeRatio != nil {
+		args = append(args, "-bufsize", strconv.Itoa(int(float64(quality.Bitrate)**profile.BufSizeRatio)))
+	}
+
+	// Ключевые кадры через равные интервалы, чтобы сегменты HLS совпадали между качествами
+	args = append(args, "-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%d)", profile.GOPSeconds))
+
+	// Плееры Apple распознают h265 в mp4 только с тегом hvc1
+	if profile.VideoCodec == constants.VideoCodecH265 && profile.Container == constants.ContainerMP4 {
+		args = append(args, "-tag:v", "hvc1")
+	}
+
+	args = append(args,
+		"-c:a", audioEncoder,
+		"-b:a", strconv.Itoa(profile.AudioBitrate),
+		"-f", profile.Container,
+	)
+
+	if profile.Container == constants.ContainerMP4 {
+		args = append(args, "-movflags", "+faststart")
+	}
+
+	return args, nil
+}
+
+// AudioContainer возвращает контейнер звуковой дорожки без видео для звукового кодека профиля
+func AudioContainer(profile *entity.TranscodeProfile) string {
+	if profile.AudioCodec == constants.AudioCodecOpus {
+		return constants.ContainerWebM
+	}
+
+	return constants.ContainerM4A
+}
+
+// audioEncoderArgs формирует аргументы ffmpeg для звуковой дорожки без видео
+func audioEncoderArgs(profile *entity.TranscodeProfile) ([]string, error) {
+	audioEncoder, ok := audioEncoders[profile.AudioCodec]
+	if !ok {
+		return nil, fmt.Errorf("unsupported audio codec %q in profile %s", profile.AudioCodec, profile.Name)
+	}
+
+	args := []string{
+		"-c:a", audioEncoder,
+		"-b:a", strconv.Itoa(profile.AudioBitrate),
+	}
+
+	// m4a пишется muxer-ом mp4
+	if AudioContainer(profile) == constants.ContainerM4A {
+		args = append(args, "-f", constants.ContainerMP4, "-movflags", "+faststart")
+	} else {
+		args = append(args, "-f", constants.ContainerWebM)
+	}
+
+	return args, nil
+}
@@ -0,0 +1,113 @@
+package conversion
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/pkg/constants"
+)
+
+func TestEncoderArgs(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	floatPtr := func(v float64) *float64 { return &v }
+	stringPtr := func(v string) *string { return &v }
+
+	quality := &entity.VideoQuality{Name: "720p", Bitrate: 2500000}
+
+	tests := []struct {
+		name    string
+		profile entity.TranscodeProfile
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "h264 crf mp4",
+			profile: entity.TranscodeProfile{
+				VideoCodec: constants.VideoCodecH264, Container: constants.ContainerMP4, RateControl: constants.RateControlCRF,
+				CRF: intPtr(23), Preset: stringPtr("medium"), MaxRateRatio: floatPtr(1.5), BufSizeRatio: floatPtr(2),
+				GOPSeconds: 2, AudioCodec: constants.AudioCodecAAC, AudioBitrate: 128000,
+			},
+			want: []string{
+				"-c:v", "libx264", "-preset", "medium", "-crf", "23",
+				"-maxrate", "3750000", "-bufsize", "5000000",
+				"-force_key_frames", "expr:gte(t,n_forced*2)",
+				"-c:a", "aac", "-b:a", "128000", "-f", "mp4", "-movflags", "+faststart",
+			},
+		},
+		{
+			name: "h265 mp4 is tagged hvc1",
+			profile: entity.TranscodeProfile{
+				VideoCodec: constants.VideoCodecH265, Container: constants.ContainerMP4, RateControl: constants.RateControlBitrate,
+				GOPSeconds: 4, AudioCodec: constants.AudioCodecAAC, AudioBitrate: 96000,
+			},
+			want: []string{
+				"-c:v", "libx265", "-b:v", "2500000",
+				"-force_key_frames", "expr:gte(t,n_forced*4)", "-tag:v", "hvc1",
+				"-c:a", "aac", "-b:a", "96000", "-f", "mp4", "-movflags", "+faststart",
+			},
+		},
+		{
+			name: "vp9 crf webm without maxrate",
+			profile: entity.TranscodeProfile{
+				VideoCodec: constants.VideoCodecVP9, Container: constants.ContainerWebM, RateControl: constants.RateControlCRF,
+				CRF: intPtr(31), Preset: stringPtr("good"), GOPSeconds: 2, AudioCodec: constants.AudioCodecOpus, AudioBitrate: 128000,
+			},
+			want: []string{
+				"-c:v", "libvpx-vp9", "-deadline", "good", "-crf", "31", "-b:v", "0",
+				"-force_key_frames", "expr:gte(t,n_forced*2)",
+				"-c:a", "libopus", "-b:a", "128000", "-f", "webm",
+			},
+		},
+		{
+			name: "vp9 crf with maxrate",
+			profile: entity.TranscodeProfile{
+				VideoCodec: constants.VideoCodecVP9, Container: constants.ContainerWebM, RateControl: constants.RateControlCRF,
+				CRF: intPtr(31), MaxRateRatio: floatPtr(1), GOPSeconds: 2, AudioCodec: constants.AudioCodecOpus, AudioBitrate: 128000,
+			},
+			want: []string{
+				"-c:v", "libvpx-vp9", "-crf", "31", "-maxrate", "2500000",
+				"-force_key_frames", "expr:gte(t,n_forced*2)",
+				"-c:a", "libopus", "-b:a", "128000", "-f", "webm",
+			},
+		},
+		{
+			name:    "unknown video codec",
+			profile: entity.TranscodeProfile{VideoCodec: "mpeg2", AudioCodec: constants.AudioCodecAAC, RateControl: constants.RateControlBitrate},
+			wantErr: true,
+		},
+		{
+			name:    "unknown audio codec",
+			profile: entity.TranscodeProfile{VideoCodec: constants.VideoCodecH264, AudioCodec: "mp3", RateControl: constants.RateControlBitrate},
+			wantErr: true,
+		},
+		{
+			name:    "crf without value",
+			profile: entity.TranscodeProfile{VideoCodec: constants.VideoCodecH264, AudioCodec: constants.AudioCodecAAC, RateControl: constants.RateControlCRF},
+			wantErr: true,
+		},
+		{
+			name:    "unknown rate control",
+			profile: entity.TranscodeProfile{VideoCodec: constants.VideoCodecH264, AudioCodec: constants.AudioCodecAAC, RateControl: "cbr"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encoderArgs(&tt.profile, quality)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("encoderArgs() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("encoderArgs(): %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("encoderArgs() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package conversion
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"github.com/redis/go-redis/v9"
+)
+
+// ProgressTracker сохраняет прогресс конвертации качеств в Redis и уведомляет подписчиков.
+// Прогресс вспомогательный, ошибки Redis только логируются
+type ProgressTracker struct {
+	redisClient *redis.Client
+}
+
+func NewProgressTracker(redisClient *redis.Client) *ProgressTracker {
+	return &ProgressTracker{
+		redisClient: redisClient,
+	}
+}
+
+// SetQualityProgress сохраняет процент готовности качества
+func (t *ProgressTracker) SetQualityProgress(ctx context.Context, videoID uuid.UUID, quality string, percent int) {
+	t.setQuality(ctx, videoID, quality, strconv.Itoa(percent))
+}
+
+// MarkQualityFailed помечает качество, которое не удалось сконвертировать
+func (t *ProgressTracker) MarkQualityFailed(ctx context.Context, videoID uuid.UUID, quality string) {
+	t.setQuality(ctx, videoID, quality, constants.QualityStatusFailed)
+}
+
+// Notify уведомляет подписчиков об изменении статуса видео
+func (t *ProgressTracker) Notify(ctx context.Context, videoID uuid.UUID) {
+	if t.redisClient == nil {
+		return
+	}
+
+	channel := fmt.Sprintf(constants.VideoProcessingChannel, videoID)
+	if err := t.redisClient.Publish(ctx, channel, videoID.String()).Err(); err != nil {
+		log.Printf("Failed to publish processing update for video %s: %v", videoID, err)
+	}
+}
+
+func (t *ProgressTracker) setQuality(ctx context.Context, videoID uuid.UUID, quality, value string) {
+	if t.redisClient == nil {
+		return
+	}
+
+	key := fmt.Sprintf(constants.VideoProcessingKey, videoID)
+	pipe := t.redisClient.TxPipeline()
+	pipe.HSet(ctx, key, quality, value)
+	pipe.Expire(ctx, key, constants.VideoProcessingTTL)
+	pipe.Publish(ctx, fmt.Sprintf(constants.VideoProcessingChannel, videoID), quality)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Failed to save progress for video %s quality %s: %v", videoID, quality, err)
+	}
+}
+
+// parseProgress читает вывод ffmpeg -progress и сообщает процент готовности при каждом его изменении.
+// До завершения процент не превышает 99, 100 сообщается по progress=end
+func parseProgress(r io.Reader, duration int, onProgress func(percent int)) {
+	// Вывод дочитывается до конца, иначе ffmpeg заблокируется на записи в pipe
+	defer io.Copy(io.Discard, r)
+
+	last := -1
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+
+		var percent int
+		switch key {
+		case "out_time_us":
+			if duration <= 0 {
+				continue
+			}
+			// До первого кадра ffmpeg пишет N/A
+			outTime, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || outTime < 0 {
+				continue
+			}
+			percent = min(int(outTime*100/(int64(duration)*1000000)), 99)
+		case "progress":
+			if value != "end" {
+				continue
+			}
+			percent = 100
+		default:
+			continue
+		}
+
+		if percent != last {
+			last = percent
+			onProgress(percent)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package conversion
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mrkbwp/gotube/pkg/constants"
+)
+
+// SpriteLayout раскладка кадров превью по спрайтам
+type SpriteLayout struct {
+	// Interval - секунды между кадрами
+	Interval    int
+	Columns     int
+	Rows        int
+	FrameWidth  int
+	FrameHeight int
+}
+
+// NewSpriteLayout подбирает интервал по длительности и высоту кадра по пропорциям исходника
+func NewSpriteLayout(duration, sourceWidth, sourceHeight int) SpriteLayout {
+	interval := constants.SpriteInterval
+	if duration > interval*constants.SpriteMaxFrames {
+		interval = (duration + constants.SpriteMaxFrames - 1) / constants.SpriteMaxFrames
+	}
+
+	frameHeight := constants.SpriteFrameWidth * 9 / 16
+	if sourceWidth > 0 && sourceHeight > 0 {
+		frameHeight = constants.SpriteFrameWidth * sourceHeight / sourceWidth
+	}
+	// Нечетные размеры не принимает mjpeg с yuv420p
+	frameHeight = max(frameHeight-frameHeight%2, 2)
+
+	return SpriteLayout{
+		Interval:    interval,
+		Columns:     constants.SpriteColumns,
+		Rows:        constants.SpriteRows,
+		FrameWidth:  constants.SpriteFrameWidth,
+		FrameHeight: frameHeight,
+	}
+}
+
+// BuildSpriteVTT формирует WebVTT, где каждому интервалу соответствует область спрайта.
+// Спрайты указываются относительными путями, рядом с VTT
+func BuildSpriteVTT(duration int, layout SpriteLayout) []byte {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+
+	framesPerSprite := layout.Columns * layout.Rows
+	for frame, start := 0, 0; start < duration; frame, start = frame+1, start+layout.Interval {
+		end := min(start+layout.Interval, duration)
+		position := frame % framesPerSprite
+
+		fmt.Fprintf(&b, "%s --> %s\n", formatVTTTime(start), formatVTTTime(end))
+		fmt.Fprintf(&b, constants.SpriteImagePattern+"#xywh=%d,%d,%d,%d\n\n",
+			frame/framesPerSprite+1,
+			position%layout.Columns*layout.FrameWidth,
+			position/layout.Columns*layout.FrameHeight,
+			layout.FrameWidth,
+			layout.FrameHeight,
+		)
+	}
+
+	return []byte(b.String())
+}
+
+// formatVTTTime форматирует секунды как ЧЧ:ММ:СС.ммм
+func formatVTTTime(seconds int) string {
+	return fmt.Sprintf("%02d:%02d:%02d.000", seconds/3600, seconds/60%60, seconds%60)
+}
@@ -0,0 +1,46 @@
+package conversion
+
+import (
+	"fmt"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/pkg/constants"
+)
+
+// StreamMap номера потоков исходника, которые попадают в выходной файл
+type StreamMap struct {
+	// Video - -1, если выбор потоков оставлен ffmpeg
+	Video int
+	// Audio - -1, если звука нет
+	Audio int
+}
+
+// StreamMapFromMetadata читает номера основных потоков из метаданных видео.
+// Для видео, проанализированных до появления номеров, выбор остается за ffmpeg
+func StreamMapFromMetadata(metadata entity.Metadata) StreamMap {
+	streams := StreamMap{Video: -1, Audio: -1}
+
+	if index, ok := metadata.Float(constants.MetadataVideoStreamIndex); ok {
+		streams.Video = int(index)
+	}
+	if index, ok := metadata.Float(constants.MetadataAudioStreamIndex); ok {
+		streams.Audio = int(index)
+	}
+
+	return streams
+}
+
+// args возвращает аргументы -map. Без явного выбора ffmpeg берет звук с наибольшим числом каналов
+// и может смешать дорожки разных языков между качествами
+func (m StreamMap) args() []string {
+	if m.Video < 0 {
+		return nil
+	}
+
+	args := []string{"-map", fmt.Sprintf("0:%d", m.Video)}
+	if m.Audio >= 0 {
+		args = append(args, "-map", fmt.Sprintf("0:%d", m.Audio))
+	}
+
+	return args
+}
@@ -0,0 +1,570 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/repositories"
+)
+
+// ConversionJobRepository реализует интерфейс ConversionJobRepository
+type ConversionJobRepository struct {
+	db *sqlx.DB
+}
+
+// NewConversionJobRepository создает новый экземпляр ConversionJobRepository
+func NewConversionJobRepository(db *sqlx.DB) repositories.ConversionJobRepository {
+	return &ConversionJobRepository{db: db}
+}
+
+func (r *ConversionJobRepository) EnqueueUploadedVideos(ctx context.Context) (int64, error) {
+	query := `
+        INSERT INTO conversion_jobs (video_id, status, created_at, updated_at)
+        SELECT id, $1, NOW(), NOW() FROM videos
+        WHERE status = $2
+        AND deleted_at IS NULL
+        ON CONFLICT (video_id) WHERE quality_id IS NULL DO NOTHING
+    `
+
+	result, err := r.db.ExecContext(ctx, query,
+		string(constants.ConversionJobStatusPending),
+		string(constants.VideoStatusUploaded),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue videos: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rows, nil
+}
+
+func (r *ConversionJobRepository) EnqueueVideo(ctx context.Context, videoID uuid.UUID) error {
+	query := `
+        INSERT INTO conversion_jobs (video_id, status, created_at, updated_at)
+        SELECT id, $1, NOW(), NOW() FROM videos
+        WHERE id = $2
+        AND status = $3
+        AND deleted_at IS NULL
+        ON CONFLICT (video_id) WHERE quality_id IS NULL DO NOTHING
+    `
+
+	_, err := r.db.ExecContext(ctx, query,
+		string(constants.ConversionJobStatusPending),
+		videoID,
+		string(constants.VideoStatusUploaded),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue video: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ConversionJobRepository) AcquireVideoJob(ctx context.Context, videoID uuid.UUID, owner string, lease time.Duration) (*entity.ConversionJob, error) {
+	query := `
+        UPDATE conversion_jobs
+        SET status = $1,
+            attempts = attempts + 1,
+            lease_owner = $2,
+            lease_expires_at = NOW() + $3 * INTERVAL '1 second',
+            heartbeat_at = NOW(),
+            updated_at = NOW()
+        WHERE id IN (
+            SELECT id FROM conversion_jobs
+            WHERE video_id = $4
+            AND quality_id IS NULL
+            AND status = $5
+            AND (next_attempt_at IS NULL OR next_attempt_at <= NOW())
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING *
+    `
+
+	var jobs []*entity.ConversionJob
+	err := r.db.SelectContext(ctx, &jobs, query,
+		string(constants.ConversionJobStatusProcessing),
+		owner,
+		int(lease.Seconds()),
+		videoID,
+		string(constants.ConversionJobStatusPending),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire job: %w", err)
+	}
+
+	if len(jobs) == 0 {
+		return nil, constants.ErrNotFound
+	}
+
+	return jobs[0], nil
+}
+
+func (r *ConversionJobRepository) AcquireVideoJobs(ctx context.Context, owner string, lease time.Duration, limit int) ([]*entity.ConversionJob, error) {
+	// SKIP LOCKED не дает двум репликам арендовать одно задание
+	query := `
+        UPDATE conversion_jobs
+        SET status = $1,
+            attempts = attempts + 1,
+            lease_owner = $2,
+            lease_expires_at = NOW() + $3 * INTERVAL '1 second',
+            heartbeat_at = NOW(),
+            updated_at = NOW()
+        WHERE id IN (
+            SELECT id FROM conversion_jobs
+            WHERE quality_id IS NULL
+            AND status = $4
+            AND (next_attempt_at IS NULL OR next_attempt_at <= NOW())
+            ORDER BY created_at ASC
+            LIMIT $5
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING *
+    `
+
+	var jobs []*entity.ConversionJob
+	err := r.db.SelectContext(ctx, &jobs, query,
+		string(constants.ConversionJobStatusProcessing),
+		owner,
+		int(lease.Seconds()),
+		string(constants.ConversionJobStatusPending),
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (r *ConversionJobRepository) Heartbeat(ctx context.Context, jobID uuid.UUID, owner string, lease time.Duration) error {
+	query := `
+        UPDATE conversion_jobs
+        SET lease_expires_at = NOW() + $1 * INTERVAL '1 second',
+            heartbeat_at = NOW(),
+            updated_at = NOW()
+        WHERE id = $2
+        AND lease_owner = $3
+        AND status = $4
+    `
+
+	result, err := r.db.ExecContext(ctx, query,
+		int(lease.Seconds()),
+		jobID,
+		owner,
+		string(constants.ConversionJobStatusProcessing),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to extend lease: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return constants.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *ConversionJobRepository) ReleaseJob(ctx context.Context, jobID uuid.UUID, owner string) error {
+	query := `
+        UPDATE conversion_jobs
+        SET status = $1,
+            lease_owner = NULL,
+            lease_expires_at = NULL,
+            dispatched_at = NULL,
+            updated_at = NOW()
+        WHERE id = $2
+        AND lease_owner = $3
+        AND status = $4
+    `
+
+	result, err := r.db.ExecContext(ctx, query,
+		string(constants.ConversionJobStatusPending),
+		jobID,
+		owner,
+		string(constants.ConversionJobStatusProcessing),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to release job: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return constants.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *ConversionJobRepository) RequeueExpired(ctx context.Context, maxAttempts int) (int64, []*entity.ConversionJob, error) {
+	exhaustedQuery := `
+        SELECT * FROM conversion_jobs
+        WHERE quality_id IS NULL
+        AND status = $1
+        AND lease_expires_at < NOW()
+        AND attempts >= $2
+    `
+
+	var exhausted []*entity.ConversionJob
+	err := r.db.SelectContext(ctx, &exhausted, exhaustedQuery,
+		string(constants.ConversionJobStatusProcessing),
+		maxAttempts,
+	)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get exhausted jobs: %w", err)
+	}
+
+	requeueQuery := `
+        UPDATE conversion_jobs
+        SET status = $1,
+            lease_owner = NULL,
+            lease_expires_at = NULL,
+            last_error = 'lease expired',
+            failure_class = $2,
+            dispatched_at = NULL,
+            updated_at = NOW()
+        WHERE quality_id IS NULL
+        AND status = $3
+        AND lease_expires_at < NOW()
+        AND attempts < $4
+    `
+
+	result, err := r.db.ExecContext(ctx, requeueQuery,
+		string(constants.ConversionJobStatusPending),
+		constants.FailureClassLease,
+		string(constants.ConversionJobStatusProcessing),
+		maxAttempts,
+	)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to requeue expired jobs: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rows, exhausted, nil
+}
+
+func (r *ConversionJobRepository) DispatchDueJobs(ctx context.Context, redispatchAfter time.Duration, limit int) ([]*entity.ConversionJob, error) {
+	// SKIP LOCKED не дает двум сборщикам отправить одно задание дважды
+	query := `
+        UPDATE conversion_jobs
+        SET dispatched_at = NOW(),
+            updated_at = NOW()
+        WHERE id IN (
+            SELECT id FROM conversion_jobs
+            WHERE quality_id IS NULL
+            AND status = $1
+            AND (next_attempt_at IS NULL OR next_attempt_at <= NOW())
+            AND (dispatched_at IS NULL OR dispatched_at < NOW() - $2 * INTERVAL '1 second')
+            ORDER BY created_at ASC
+            LIMIT $3
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING *
+    `
+
+	var jobs []*entity.ConversionJob
+	err := r.db.SelectContext(ctx, &jobs, query,
+		string(constants.ConversionJobStatusPending),
+		int(redispatchAfter.Seconds()),
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dispatch jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (r *ConversionJobRepository) RetryJob(ctx context.Context, jobID uuid.UUID, owner, failureClass, lastError string, nextAttemptAt time.Time) error {
+	query := `
+        UPDATE conversion_jobs
+        SET status = $1,
+            lease_owner = NULL,
+            lease_expires_at = NULL,
+            failure_class = $2,
+            last_error = $3,
+            next_attempt_at = $4,
+            dispatched_at = NULL,
+            updated_at = NOW()
+        WHERE id = $5
+        AND lease_owner = $6
+        AND status = $7
+    `
+
+	result, err := r.db.ExecContext(ctx, query,
+		string(constants.ConversionJobStatusPending),
+		failureClass,
+		lastError,
+		nextAttemptAt,
+		jobID,
+		owner,
+		string(constants.ConversionJobStatusProcessing),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to schedule retry: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return constants.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *ConversionJobRepository) DeadLetterJob(ctx context.Context, jobID uuid.UUID, failureClass, lastError string) (*entity.ConversionDeadLetter, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Условие на статус не дает двум репликам отправить задание в dead letter дважды
+	failQuery := `
+        UPDATE conversion_jobs
+        SET status = $1,
+            lease_owner = NULL,
+            lease_expires_at = NULL,
+            failure_class = $2,
+            last_error = $3,
+            updated_at = NOW()
+        WHERE id = $4
+        AND status = $5
+        RETURNING *
+    `
+
+	var jobs []*entity.ConversionJob
+	err = tx.SelectContext(ctx, &jobs, failQuery,
+		string(constants.ConversionJobStatusFailed),
+		failureClass,
+		lastError,
+		jobID,
+		string(constants.ConversionJobStatusProcessing),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fail job: %w", err)
+	}
+
+	if len(jobs) == 0 {
+		return nil, constants.ErrNotFound
+	}
+
+	insertQuery := `
+        INSERT INTO conversion_dead_letters (job_id, video_id, failure_class, error, attempts, created_at)
+        VALUES ($1, $2, $3, $4, $5, NOW())
+        RETURNING *
+    `
+
+	deadLetter := &entity.ConversionDeadLetter{}
+	err = tx.GetContext(ctx, deadLetter, insertQuery,
+		jobs[0].ID,
+		jobs[0].VideoID,
+		failureClass,
+		lastError,
+		jobs[0].Attempts,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dead letter: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return deadLetter, nil
+}
+
+func (r *ConversionJobRepository) GetDeadLetters(ctx context.Context, page, limit int) ([]*entity.ConversionDeadLetter, int64, error) {
+	query := `
+        SELECT * FROM conversion_dead_letters
+        WHERE redriven_at IS NULL
+        ORDER BY created_at DESC
+        LIMIT $1 OFFSET $2
+    `
+
+	deadLetters := []*entity.ConversionDeadLetter{}
+	err := r.db.SelectContext(ctx, &deadLetters, query, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get dead letters: %w", err)
+	}
+
+	var total int64
+	err = r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM conversion_dead_letters WHERE redriven_at IS NULL`)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get total count: %w", err)
+	}
+
+	return deadLetters, total, nil
+}
+
+func (r *ConversionJobRepository) RedriveDeadLetter(ctx context.Context, id uuid.UUID) (*entity.ConversionDeadLetter, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	deadLetterQuery := `
+        UPDATE conversion_dead_letters
+        SET redriven_at = NOW()
+        WHERE id = $1
+        AND redriven_at IS NULL
+        RETURNING *
+    `
+
+	deadLetter := &entity.ConversionDeadLetter{}
+	err = tx.GetContext(ctx, deadLetter, deadLetterQuery, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, constants.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to redrive dead letter: %w", err)
+	}
+
+	jobQuery := `
+        UPDATE conversion_jobs
+        SET status = $1,
+            attempts = 0,
+            failure_class = NULL,
+            last_error = NULL,
+            next_attempt_at = NULL,
+            dispatched_at = NULL,
+            updated_at = NOW()
+        WHERE id = $2
+        AND status = $3
+    `
+
+	_, err = tx.ExecContext(ctx, jobQuery,
+		string(constants.ConversionJobStatusPending),
+		deadLetter.JobID,
+		string(constants.ConversionJobStatusFailed),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to requeue job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return deadLetter, nil
+}
+
+func (r *ConversionJobRepository) GetQualityJobs(ctx context.Context, videoID uuid.UUID) ([]*entity.ConversionJob, error) {
+	query := `
+        SELECT * FROM conversion_jobs
+        WHERE video_id = $1
+        AND quality_id IS NOT NULL
+    `
+
+	var jobs []*entity.ConversionJob
+	err := r.db.SelectContext(ctx, &jobs, query, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quality jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (r *ConversionJobRepository) StartQualityJob(ctx context.Context, videoID, qualityID uuid.UUID, owner string, lease time.Duration) (*entity.ConversionJob, error) {
+	query := `
+        INSERT INTO conversion_jobs (video_id, quality_id, status, attempts, lease_owner, lease_expires_at, heartbeat_at, created_at, updated_at)
+        VALUES ($1, $2, $3, 1, $4, NOW() + $5 * INTERVAL '1 second', NOW(), NOW(), NOW())
+        ON CONFLICT (video_id, quality_id) WHERE quality_id IS NOT NULL DO UPDATE SET
+            status = EXCLUDED.status,
+            attempts = conversion_jobs.attempts + 1,
+            lease_owner = EXCLUDED.lease_owner,
+            lease_expires_at = EXCLUDED.lease_expires_at,
+            heartbeat_at = EXCLUDED.heartbeat_at,
+            updated_at = EXCLUDED.updated_at
+        RETURNING *
+    `
+
+	job := &entity.ConversionJob{}
+	err := r.db.GetContext(ctx, job, query,
+		videoID,
+		qualityID,
+		string(constants.ConversionJobStatusProcessing),
+		owner,
+		int(lease.Seconds()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start quality job: %w", err)
+	}
+
+	return job, nil
+}
+
+func (r *ConversionJobRepository) CompleteJob(ctx context.Context, jobID uuid.UUID, owner string) error {
+	return r.finishJob(ctx, jobID, owner, constants.ConversionJobStatusCompleted, nil)
+}
+
+func (r *ConversionJobRepository) FailJob(ctx context.Context, jobID uuid.UUID, owner, lastError string) error {
+	return r.finishJob(ctx, jobID, owner, constants.ConversionJobStatusFailed, &lastError)
+}
+
+func (r *ConversionJobRepository) finishJob(ctx context.Context, jobID uuid.UUID, owner string, status constants.ConversionJobStatus, lastError *string) error {
+	// Завершить задание может только текущий арендатор, иначе воркер с просроченной арендой
+	// перезапишет результат воркера, который забрал задание
+	query := `
+        UPDATE conversion_jobs
+        SET status = $1,
+            last_error = $2,
+            lease_owner = NULL,
+            lease_expires_at = NULL,
+            updated_at = NOW()
+        WHERE id = $3
+        AND lease_owner = $4
+        AND status = $5
+    `
+
+	result, err := r.db.ExecContext(ctx, query,
+		string(status),
+		lastError,
+		jobID,
+		owner,
+		string(constants.ConversionJobStatusProcessing),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return constants.ErrNotFound
+	}
+
+	return nil
+}
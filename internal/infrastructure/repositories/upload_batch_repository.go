@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/repositories"
+)
+
+type UploadBatchRepository struct {
+	db *sqlx.DB
+}
+
+func NewUploadBatchRepository(db *sqlx.DB) repositories.UploadBatchRepository {
+	return &UploadBatchRepository{db: db}
+}
+
+func (r *UploadBatchRepository) Create(ctx context.Context, batch *entity.UploadBatch) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	batchQuery := `
+        INSERT INTO upload_batches (user_id, status, total_items, created_at, updated_at)
+        VALUES ($1, $2, $3, NOW(), NOW())
+        RETURNING id, created_at, updated_at
+    `
+
+	err = tx.QueryRowContext(ctx, batchQuery,
+		batch.UserID,
+		batch.Status,
+		len(batch.Items),
+	).Scan(&batch.ID, &batch.CreatedAt, &batch.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create upload batch: %w", err)
+	}
+	batch.Total = len(batch.Items)
+
+	itemQuery := `
+        INSERT INTO upload_batch_items (
+            batch_id, position, filename, title, description, category_id, privacy, publish_at, tags, status,
+            created_at, updated_at
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
+        RETURNING id, created_at, updated_at
+    `
+
+	for _, item := range batch.Items {
+		item.BatchID = batch.ID
+
+		err = tx.QueryRowContext(ctx, itemQuery,
+			item.BatchID,
+			item.Position,
+			item.Filename,
+			item.Title,
+			item.Description,
+			item.CategoryID,
+			item.Privacy,
+			item.PublishAt,
+			item.Tags,
+			item.Status,
+		).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create upload batch item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UploadBatchRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.UploadBatch, error) {
+	var batch entity.UploadBatch
+	if err := r.db.GetContext(ctx, &batch, `SELECT * FROM upload_batches WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, constants.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get upload batch: %w", err)
+	}
+
+	itemsQuery := `
+        SELECT i.*, v.video_code
+        FROM upload_batch_items i
+        LEFT JOIN videos v ON v.id = i.video_id
+        WHERE i.batch_id = $1
+        ORDER BY i.position ASC
+    `
+
+	if err := r.db.SelectContext(ctx, &batch.Items, itemsQuery, id); err != nil {
+		return nil, fmt.Errorf("failed to get upload batch items: %w", err)
+	}
+
+	return &batch, nil
+}
+
+func (r *UploadBatchRepository) UpdateItem(ctx context.Context, item *entity.UploadBatchItem) error {
+	query := `
+        WITH item AS (
+            UPDATE upload_batch_items
+            SET status = $1,
+                video_id = $2,
+                error = $3,
+                updated_at = NOW()
+            WHERE id = $4
+            RETURNING batch_id
+        )
+        UPDATE upload_batches
+        SET updated_at = NOW()
+        WHERE id IN (SELECT batch_id FROM item)
+    `
+
+	result, err := r.db.ExecContext(ctx, query, item.Status, item.VideoID, item.Error, item.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update upload batch item: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return constants.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *UploadBatchRepository) Complete(ctx context.Context, id uuid.UUID) error {
+	query := `
+        UPDATE upload_batches
+        SET status = $1,
+            completed_at = NOW(),
+            updated_at = NOW()
+        WHERE id = $2
+        AND status = $3
+    `
+
+	result, err := r.db.ExecContext(ctx, query, constants.BatchStatusCompleted, id, constants.BatchStatusProcessing)
+	if err != nil {
+		return fmt.Errorf("failed to complete upload batch: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return constants.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *UploadBatchRepository) FailStale(ctx context.Context, before time.Time, message string) (int64, error) {
+	query := `
+        WITH stale AS (
+            UPDATE upload_batches
+            SET status = $1,
+                completed_at = NOW(),
+                updated_at = NOW()
+            WHERE status = $2
+            AND updated_at < $3
+            RETURNING id
+        )
+        UPDATE upload_batch_items
+        SET status = $4,
+            error = $5,
+            updated_at = NOW()
+        WHERE batch_id IN (SELECT id FROM stale)
+        AND status IN ($6, $7)
+    `
+
+	result, err := r.db.ExecContext(ctx, query,
+		constants.BatchStatusCompleted,
+		constants.BatchStatusProcessing,
+		before,
+		constants.BatchItemStatusFailed,
+		message,
+		constants.BatchItemStatusPending,
+		constants.BatchItemStatusProcessing,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail stale upload batches: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/mrkbwp/gotube/pkg/constants"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/repositories"
+)
+
+type UploadQuotaRepository struct {
+	db *sqlx.DB
+}
+
+func NewUploadQuotaRepository(db *sqlx.DB) repositories.UploadQuotaRepository {
+	return &UploadQuotaRepository{db: db}
+}
+
+func (r *UploadQuotaRepository) GetQuota(ctx context.Context, userID uuid.UUID, role string) (*entity.UploadQuota, error) {
+	// Индивидуальная квота важнее квоты роли
+	query := `
+        SELECT * FROM upload_quotas
+        WHERE user_id = $1 OR role = $2
+        ORDER BY user_id IS NULL
+        LIMIT 1
+    `
+
+	var quota entity.UploadQuota
+	if err := r.db.GetContext(ctx, &quota, query, userID, role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, constants.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get upload quota: %w", err)
+	}
+
+	return &quota, nil
+}
+
+func (r *UploadQuotaRepository) GetStorageUsage(ctx context.Context, userID uuid.UUID) (int64, error) {
+	// У видео с незавершенной загрузкой размер исходника еще не известен, считаем заявленный
+	query := `
+        SELECT COALESCE(SUM(CASE WHEN v.original_size > 0 THEN v.original_size ELSE COALESCE(u.upload_length, 0) END), 0)
+        FROM videos v
+        LEFT JOIN video_uploads u ON u.video_id = v.id AND u.status = $2
+        WHERE v.user_id = $1
+        AND v.deleted_at IS NULL
+    `
+
+	var usage int64
+	if err := r.db.GetContext(ctx, &usage, query, userID, constants.UploadStatusUploading); err != nil {
+		return 0, fmt.Errorf("failed to get storage usage: %w", err)
+	}
+
+	return usage, nil
+}
+
+func (r *UploadQuotaRepository) ReserveDailyUpload(ctx context.Context, userID uuid.UUID, size int64, maxUploads *int, maxBytes *int64) error {
+	// Проверка лимитов и увеличение счетчиков в одном запросе, чтобы параллельные загрузки не превысили квоту
+	query := `
+        INSERT INTO user_upload_usage AS uu (user_id, day, uploads, bytes)
+        SELECT $1, (NOW() AT TIME ZONE 'UTC')::date, 1, $2
+        WHERE ($3::integer IS NULL OR $3 >= 1)
+        AND ($4::bigint IS NULL OR $4 >= $2)
+        ON CONFLICT (user_id, day) DO UPDATE
+        SET uploads = uu.uploads + 1,
+            bytes = uu.bytes + EXCLUDED.bytes
+        WHERE ($3::integer IS NULL OR uu.uploads + 1 <= $3)
+        AND ($4::bigint IS NULL OR uu.bytes + EXCLUDED.bytes <= $4)
+    `
+
+	result, err := r.db.ExecContext(ctx, query, userID, size, maxUploads, maxBytes)
+	if err != nil {
+		return fmt.Errorf("failed to reserve daily upload: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return constants.ErrDailyUploadQuotaExceeded
+	}
+
+	return nil
+}
+
+func (r *UploadQuotaRepository) ReserveDailyBytes(ctx context.Context, userID uuid.UUID, size int64, maxBytes *int64) error {
+	// Загрузка уже учтена при создании видео, поэтому счетчик загрузок не меняется. Если сутки сменились,
+	// байты учитываются в новых сутках
+	query := `
+        INSERT INTO user_upload_usage AS uu (user_id, day, uploads, bytes)
+        SELECT $1, (NOW() AT TIME ZONE 'UTC')::date, 0, $2
+        WHERE ($3::bigint IS NULL OR $3 >= $2)
+        ON CONFLICT (user_id, day) DO UPDATE
+        SET bytes = uu.bytes + EXCLUDED.bytes
+        WHERE ($3::bigint IS NULL OR uu.bytes + EXCLUDED.bytes <= $3)
+    `
+
+	result, err := r.db.ExecContext(ctx, query, userID, size, maxBytes)
+	if err != nil {
+		return fmt.Errorf("failed to reserve daily upload bytes: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return constants.ErrDailyUploadQuotaExceeded
+	}
+
+	return nil
+}
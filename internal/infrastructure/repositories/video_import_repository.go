@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/repositories"
+)
+
+type VideoImportRepository struct {
+	db *sqlx.DB
+}
+
+func NewVideoImportRepository(db *sqlx.DB) repositories.VideoImportRepository {
+	return &VideoImportRepository{db: db}
+}
+
+func (r *VideoImportRepository) Create(ctx context.Context, videoImport *entity.VideoImport) error {
+	query := `
+        INSERT INTO video_imports (video_id, user_id, source_url, status, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, NOW(), NOW())
+        RETURNING id, created_at, updated_at
+    `
+
+	err := r.db.QueryRowContext(ctx, query,
+		videoImport.VideoID,
+		videoImport.UserID,
+		videoImport.SourceURL,
+		videoImport.Status,
+	).Scan(&videoImport.ID, &videoImport.CreatedAt, &videoImport.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create video import: %w", err)
+	}
+
+	return nil
+}
+
+func (r *VideoImportRepository) GetByVideoID(ctx context.Context, videoID uuid.UUID) (*entity.VideoImport, error) {
+	query := `SELECT * FROM video_imports WHERE video_id = $1`
+
+	var videoImport entity.VideoImport
+	if err := r.db.GetContext(ctx, &videoImport, query, videoID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, constants.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get video import: %w", err)
+	}
+
+	return &videoImport, nil
+}
+
+func (r *VideoImportRepository) Acquire(ctx context.Context, lease time.Duration, maxAttempts, limit int) ([]*entity.VideoImport, error) {
+	// SKIP LOCKED не дает двум репликам арендовать один импорт
+	query := `
+        UPDATE video_imports
+        SET status = $1,
+            attempts = attempts + 1,
+            lease_expires_at = NOW() + $2 * INTERVAL '1 second',
+            updated_at = NOW()
+        WHERE id IN (
+            SELECT id FROM video_imports
+            WHERE (status = $3 OR (status = $1 AND lease_expires_at < NOW()))
+            AND attempts < $4
+            ORDER BY created_at ASC
+            LIMIT $5
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING *
+    `
+
+	var imports []*entity.VideoImport
+	err := r.db.SelectContext(ctx, &imports, query,
+		constants.ImportStatusDownloading,
+		int(lease.Seconds()),
+		constants.ImportStatusPending,
+		maxAttempts,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire video imports: %w", err)
+	}
+
+	return imports, nil
+}
+
+func (r *VideoImportRepository) FailAbandoned(ctx context.Context, maxAttempts int, message string) ([]*entity.VideoImport, error) {
+	query := `
+        UPDATE video_imports
+        SET status = $1,
+            error = $2,
+            lease_expires_at = NULL,
+            updated_at = NOW()
+        WHERE status = $3
+        AND lease_expires_at < NOW()
+        AND attempts >= $4
+        RETURNING *
+    `
+
+	var imports []*entity.VideoImport
+	err := r.db.SelectContext(ctx, &imports, query,
+		constants.ImportStatusFailed,
+		message,
+		constants.ImportStatusDownloading,
+		maxAttempts,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fail abandoned video imports: %w", err)
+	}
+
+	return imports, nil
+}
+
+func (r *VideoImportRepository) Complete(ctx context.Context, id uuid.UUID, size int64) error {
+	query := `
+        UPDATE video_imports
+        SET status = $1,
+            size = $2,
+            lease_expires_at = NULL,
+            completed_at = NOW(),
+            updated_at = NOW()
+        WHERE id = $3
+    `
+
+	return r.exec(ctx, query, constants.ImportStatusCompleted, size, id)
+}
+
+func (r *VideoImportRepository) Fail(ctx context.Context, id uuid.UUID, message string) error {
+	query := `
+        UPDATE video_imports
+        SET status = $1,
+            error = $2,
+            lease_expires_at = NULL,
+            updated_at = NOW()
+        WHERE id = $3
+    `
+
+	return r.exec(ctx, query, constants.ImportStatusFailed, message, id)
+}
+
+func (r *VideoImportRepository) exec(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update video import: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return constants.ErrNotFound
+	}
+
+	return nil
+}
@@ -26,6 +26,8 @@ func NewVideoRepository(db *sqlx.DB) repositories.VideoRepository {
 }
 
 func (r *VideoRepository) Create(ctx context.Context, video *entity.Video) error {
+	video.IsPrivate = entity.IsPrivateVisibility(video.Visibility)
+
 	// Получаем список полей из структуры Video
 	fields, err := sqlutil.GetFields(video)
 	if err != nil {
@@ -139,7 +141,7 @@ func (r *VideoRepository) GetNewVideos(ctx context.Context, page, limit int) ([]
 		Where("status = ?", constants.VideoStatusReady).
 		Where("deleted_at IS NULL").
 		Where("is_blocked = ?", false).
-		Where("is_private = ?", false).
+		Where("visibility = ?", constants.VideoVisibilityPublic).
 		OrderBy("created_at DESC").
 		Limit(uint64(limit)).
 		Offset(uint64((page - 1) * limit))
@@ -162,7 +164,7 @@ func (r *VideoRepository) GetNewVideos(ctx context.Context, page, limit int) ([]
 		Where("status = ?", constants.VideoStatusReady).
 		Where("deleted_at IS NULL").
 		Where("is_blocked = ?", false).
-		Where("is_private = ?", false)
+		Where("visibility = ?", constants.VideoVisibilityPublic)
 
 	countSql, countArgs, err := countQuery.ToSql()
 	if err != nil {
@@ -192,7 +194,7 @@ func (r *VideoRepository) GetPopularVideos(ctx context.Context, page, limit int)
 		Where("status = ?", constants.VideoStatusReady).
 		Where("deleted_at IS NULL").
 		Where("is_blocked = ?", false).
-		Where("is_private = ?", false).
+		Where("visibility = ?", constants.VideoVisibilityPublic).
 		OrderBy("views DESC, likes DESC").
 		Limit(uint64(limit)).
 		Offset(uint64((page - 1) * limit))
@@ -215,7 +217,7 @@ func (r *VideoRepository) GetPopularVideos(ctx context.Context, page, limit int)
 		Where("status = ?", constants.VideoStatusReady).
 		Where("deleted_at IS NULL").
 		Where("is_blocked = ?", false).
-		Where("is_private = ?", false)
+		Where("visibility = ?", constants.VideoVisibilityPublic)
 
 	countSql, countArgs, err := countQuery.ToSql()
 	if err != nil {
@@ -231,7 +233,7 @@ func (r *VideoRepository) GetPopularVideos(ctx context.Context, page, limit int)
 	return videos, total, nil
 }
 
-func (r *VideoRepository) GetUserVideos(ctx context.Context, userID uuid.UUID, page, limit int) ([]*entity.Video, int64, error) {
+func (r *VideoRepository) GetUserVideos(ctx context.Context, userID uuid.UUID, listedOnly bool, page, limit int) ([]*entity.Video, int64, error) {
 	fields, err := sqlutil.GetFields(&entity.Video{}) // Получаем поля из структуры
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get fields: %w", err)
@@ -251,6 +253,13 @@ func (r *VideoRepository) GetUserVideos(ctx context.Context, userID uuid.UUID, p
 		Limit(uint64(limit)).
 		Offset(uint64(offset))
 
+	if listedOnly {
+		query = query.
+			Where("status = ?", constants.VideoStatusReady).
+			Where("is_blocked = ?", false).
+			Where("visibility = ?", constants.VideoVisibilityPublic)
+	}
+
 	sqlQuery, args, err := query.ToSql()
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to build query: %w", err)
@@ -269,6 +278,13 @@ func (r *VideoRepository) GetUserVideos(ctx context.Context, userID uuid.UUID, p
 		Where("user_id = ?", userID).
 		Where("deleted_at IS NULL")
 
+	if listedOnly {
+		countQuery = countQuery.
+			Where("status = ?", constants.VideoStatusReady).
+			Where("is_blocked = ?", false).
+			Where("visibility = ?", constants.VideoVisibilityPublic)
+	}
+
 	countSql, countArgs, err := countQuery.ToSql()
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to build count query: %w", err)
@@ -284,12 +300,17 @@ func (r *VideoRepository) GetUserVideos(ctx context.Context, userID uuid.UUID, p
 }
 
 func (r *VideoRepository) Update(ctx context.Context, video *entity.Video) error {
+	video.IsPrivate = entity.IsPrivateVisibility(video.Visibility)
+
 	sb := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	query, args, err := sb.
 		Update(constants.VideosTable).
 		Set("title", video.Title).
 		Set("description", video.Description).
 		Set("category_id", video.CategoryID).
+		Set("visibility", video.Visibility).
+		Set("is_private", video.IsPrivate).
+		Set("publish_at", video.PublishAt).
 		Set("updated_at", time.Now()).
 		Where("id = ?", video.ID).
 		Where("deleted_at IS NULL").
@@ -315,6 +336,30 @@ func (r *VideoRepository) Update(ctx context.Context, video *entity.Video) error
 	return nil
 }
 
+func (r *VideoRepository) PublishScheduled(ctx context.Context) (int64, error) {
+	query := `
+        UPDATE videos
+        SET visibility = $1,
+            is_private = false,
+            updated_at = NOW()
+        WHERE visibility = $2
+        AND publish_at <= NOW()
+        AND deleted_at IS NULL
+    `
+
+	result, err := r.db.ExecContext(ctx, query, constants.VideoVisibilityPublic, constants.VideoVisibilityScheduled)
+	if err != nil {
+		return 0, fmt.Errorf("failed to publish scheduled videos: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rows, nil
+}
+
 func (r *VideoRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	sb := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 
@@ -522,7 +567,12 @@ func (r *VideoRepository) GetVideoFiles(ctx context.Context, videoID uuid.UUID)
             vf.video_id,
             vf.quality_id,
             vf.file_format,
+            vf.codec,
+            vf.profile_id,
+            vf.language,
+            vf.storage_path,
             vf.file_size,
+            vf.checksum,
             vf.width,
             vf.height,
             vf.bitrate,
@@ -577,20 +627,64 @@ func (r *VideoRepository) GetVideoQualities(ctx context.Context) ([]*entity.Vide
 	return qualities, nil
 }
 
+func (r *VideoRepository) GetVideoQualityByName(ctx context.Context, name string) (*entity.VideoQuality, error) {
+	query := `
+        SELECT * FROM video_qualities
+        WHERE name = $1
+    `
+
+	var quality entity.VideoQuality
+	err := r.db.GetContext(ctx, &quality, query, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, constants.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get quality: %w", err)
+	}
+
+	return &quality, nil
+}
+
+func (r *VideoRepository) GetTranscodeProfiles(ctx context.Context) ([]*entity.TranscodeProfile, error) {
+	query := `
+        SELECT * FROM transcode_profiles
+        WHERE is_active = true
+        ORDER BY is_default DESC, name ASC
+    `
+
+	var profiles []*entity.TranscodeProfile
+	err := r.db.SelectContext(ctx, &profiles, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transcode profiles: %w", err)
+	}
+
+	return profiles, nil
+}
+
 func (r *VideoRepository) CreateVideoFile(ctx context.Context, file *entity.VideoFile) error {
 	query := `
         INSERT INTO video_files (
-            video_id, quality_id, file_format, file_size, 
-            width, height, bitrate, status, 
+            video_id, quality_id, profile_id, file_format, codec, language, storage_path,
+            file_size, checksum, width, height, bitrate, status, 
             created_at, updated_at
         ) VALUES (
-            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
         )
+        ON CONFLICT (video_id, quality_id, file_format, codec, language) DO UPDATE SET
+            profile_id = EXCLUDED.profile_id,
+            storage_path = EXCLUDED.storage_path,
+            file_size = EXCLUDED.file_size,
+            checksum = EXCLUDED.checksum,
+            width = EXCLUDED.width,
+            height = EXCLUDED.height,
+            bitrate = EXCLUDED.bitrate,
+            status = EXCLUDED.status,
+            updated_at = EXCLUDED.updated_at
     `
 
 	_, err := r.db.ExecContext(ctx, query,
-		file.VideoID, file.QualityID, file.Format, file.FileSize,
-		file.Width, file.Height, file.Bitrate, file.Status,
+		file.VideoID, file.QualityID, file.ProfileID, file.Format, file.Codec, file.Language, file.StoragePath,
+		file.FileSize, file.Checksum, file.Width, file.Height, file.Bitrate, file.Status,
 		file.CreatedAt, file.UpdatedAt,
 	)
 
@@ -625,6 +719,146 @@ func (r *VideoRepository) UpdateStatus(ctx context.Context, videoID uuid.UUID, s
 	return nil
 }
 
+func (r *VideoRepository) CompleteUpload(ctx context.Context, video *entity.Video) error {
+	query := `
+        UPDATE videos
+        SET original_size = $1,
+            original_content_type = $2,
+            original_checksum = $3,
+            status = $4,
+            updated_at = NOW()
+        WHERE id = $5
+        AND status IN ($6, $7)
+        AND deleted_at IS NULL
+    `
+
+	result, err := r.db.ExecContext(ctx, query,
+		video.OriginalSize,
+		video.OriginalContentType,
+		video.OriginalChecksum,
+		constants.VideoStatusUploaded,
+		video.ID,
+		constants.VideoStatusUploading,
+		constants.VideoStatusImporting,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete video upload: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return constants.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *VideoRepository) UpdateErrorMessage(ctx context.Context, videoID uuid.UUID, message *string) error {
+	query := `
+        UPDATE videos 
+        SET error_message = $1, updated_at = NOW()
+        WHERE id = $2
+    `
+
+	result, err := r.db.ExecContext(ctx, query, message, videoID)
+	if err != nil {
+		return fmt.Errorf("failed to update video error message: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return constants.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *VideoRepository) UpdateMetadata(ctx context.Context, videoID uuid.UUID, metadata entity.Metadata) error {
+	query := `
+        UPDATE videos 
+        SET metadata = COALESCE(metadata, '{}'::jsonb) || $1::jsonb,
+            updated_at = NOW()
+        WHERE id = $2 
+        AND deleted_at IS NULL
+    `
+
+	result, err := r.db.ExecContext(ctx, query, metadata, videoID)
+	if err != nil {
+		return fmt.Errorf("failed to update video metadata: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return constants.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *VideoRepository) UpdatePreviewsVTT(ctx context.Context, videoID uuid.UUID, url string) error {
+	query := `
+        UPDATE videos 
+        SET previews_vtt_url = $1,
+            updated_at = NOW()
+        WHERE id = $2 
+        AND deleted_at IS NULL
+    `
+
+	result, err := r.db.ExecContext(ctx, query, url, videoID)
+	if err != nil {
+		return fmt.Errorf("failed to update video previews: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return constants.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *VideoRepository) UpdatePreviewClip(ctx context.Context, videoID uuid.UUID, url string) error {
+	query := `
+        UPDATE videos 
+        SET preview_clip_url = $1,
+            updated_at = NOW()
+        WHERE id = $2 
+        AND deleted_at IS NULL
+    `
+
+	result, err := r.db.ExecContext(ctx, query, url, videoID)
+	if err != nil {
+		return fmt.Errorf("failed to update video preview clip: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return constants.ErrNotFound
+	}
+
+	return nil
+}
+
 func (r *VideoRepository) UpdateThumbnailAndDuration(ctx context.Context, videoID uuid.UUID, thumbnailURL string, duration int) error {
 	query := `
         UPDATE videos 
@@ -0,0 +1,177 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/mrkbwp/gotube/pkg/constants"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/repositories"
+)
+
+type VideoShareRepository struct {
+	db *sqlx.DB
+}
+
+func NewVideoShareRepository(db *sqlx.DB) repositories.VideoShareRepository {
+	return &VideoShareRepository{db: db}
+}
+
+func (r *VideoShareRepository) CreateLink(ctx context.Context, link *entity.VideoShareLink) error {
+	query := `
+        INSERT INTO video_share_links (video_id, created_by, password_hash, max_views, expires_at, created_at)
+        VALUES ($1, $2, $3, $4, $5, NOW())
+        RETURNING id, created_at
+    `
+
+	err := r.db.QueryRowContext(ctx, query,
+		link.VideoID,
+		link.CreatedBy,
+		link.PasswordHash,
+		link.MaxViews,
+		link.ExpiresAt,
+	).Scan(&link.ID, &link.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create share link: %w", err)
+	}
+
+	return nil
+}
+
+func (r *VideoShareRepository) GetLink(ctx context.Context, id uuid.UUID) (*entity.VideoShareLink, error) {
+	query := `SELECT * FROM video_share_links WHERE id = $1`
+
+	var link entity.VideoShareLink
+	if err := r.db.GetContext(ctx, &link, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, constants.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get share link: %w", err)
+	}
+
+	return &link, nil
+}
+
+func (r *VideoShareRepository) GetVideoLinks(ctx context.Context, videoID uuid.UUID) ([]*entity.VideoShareLink, error) {
+	query := `SELECT * FROM video_share_links WHERE video_id = $1 ORDER BY created_at DESC`
+
+	links := make([]*entity.VideoShareLink, 0)
+	if err := r.db.SelectContext(ctx, &links, query, videoID); err != nil {
+		return nil, fmt.Errorf("failed to get share links: %w", err)
+	}
+
+	return links, nil
+}
+
+func (r *VideoShareRepository) RevokeLink(ctx context.Context, videoID, id uuid.UUID) error {
+	query := `
+        UPDATE video_share_links
+        SET revoked_at = NOW()
+        WHERE id = $1 AND video_id = $2 AND revoked_at IS NULL
+    `
+
+	result, err := r.db.ExecContext(ctx, query, id, videoID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return constants.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *VideoShareRepository) CountLinkView(ctx context.Context, id uuid.UUID) error {
+	// Проверка лимита и увеличение счетчика в одном запросе, чтобы параллельные просмотры не превысили лимит
+	query := `
+        UPDATE video_share_links
+        SET views = views + 1
+        WHERE id = $1 AND (max_views IS NULL OR views < max_views)
+    `
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to count share link view: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return constants.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *VideoShareRepository) CreateGrant(ctx context.Context, grant *entity.VideoAccessGrant) error {
+	query := `
+        INSERT INTO video_access_grants (video_id, user_id, granted_by, created_at)
+        VALUES ($1, $2, $3, NOW())
+        ON CONFLICT (video_id, user_id) DO UPDATE SET video_id = EXCLUDED.video_id
+        RETURNING granted_by, created_at
+    `
+
+	err := r.db.QueryRowContext(ctx, query,
+		grant.VideoID,
+		grant.UserID,
+		grant.GrantedBy,
+	).Scan(&grant.GrantedBy, &grant.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create access grant: %w", err)
+	}
+
+	return nil
+}
+
+func (r *VideoShareRepository) HasGrant(ctx context.Context, videoID, userID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM video_access_grants WHERE video_id = $1 AND user_id = $2)`
+
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists, query, videoID, userID); err != nil {
+		return false, fmt.Errorf("failed to check access grant: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *VideoShareRepository) GetVideoGrants(ctx context.Context, videoID uuid.UUID) ([]*entity.VideoAccessGrant, error) {
+	query := `SELECT * FROM video_access_grants WHERE video_id = $1 ORDER BY created_at`
+
+	grants := make([]*entity.VideoAccessGrant, 0)
+	if err := r.db.SelectContext(ctx, &grants, query, videoID); err != nil {
+		return nil, fmt.Errorf("failed to get access grants: %w", err)
+	}
+
+	return grants, nil
+}
+
+func (r *VideoShareRepository) DeleteGrant(ctx context.Context, videoID, userID uuid.UUID) error {
+	query := `DELETE FROM video_access_grants WHERE video_id = $1 AND user_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, videoID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete access grant: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return constants.ErrNotFound
+	}
+
+	return nil
+}
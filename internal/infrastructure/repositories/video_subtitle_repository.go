@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/mrkbwp/gotube/pkg/constants"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/repositories"
+)
+
+type VideoSubtitleRepository struct {
+	db *sqlx.DB
+}
+
+func NewVideoSubtitleRepository(db *sqlx.DB) repositories.VideoSubtitleRepository {
+	return &VideoSubtitleRepository{db: db}
+}
+
+func (r *VideoSubtitleRepository) GetByVideoID(ctx context.Context, videoID uuid.UUID) ([]*entity.VideoSubtitle, error) {
+	query := `
+        SELECT * FROM video_subtitles
+        WHERE video_id = $1
+        ORDER BY language ASC
+    `
+
+	var subtitles []*entity.VideoSubtitle
+	if err := r.db.SelectContext(ctx, &subtitles, query, videoID); err != nil {
+		return nil, fmt.Errorf("failed to get video subtitles: %w", err)
+	}
+
+	return subtitles, nil
+}
+
+func (r *VideoSubtitleRepository) Upsert(ctx context.Context, subtitle *entity.VideoSubtitle) error {
+	query := `
+        INSERT INTO video_subtitles (video_id, language, label, source, storage_path, cues_count, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+        ON CONFLICT (video_id, language) DO UPDATE SET
+            label = EXCLUDED.label,
+            source = EXCLUDED.source,
+            storage_path = EXCLUDED.storage_path,
+            cues_count = EXCLUDED.cues_count,
+            updated_at = EXCLUDED.updated_at
+        WHERE EXCLUDED.source = $7
+        OR video_subtitles.source = $8
+        RETURNING id, created_at, updated_at
+    `
+
+	err := r.db.QueryRowContext(ctx, query,
+		subtitle.VideoID,
+		subtitle.Language,
+		subtitle.Label,
+		subtitle.Source,
+		subtitle.StoragePath,
+		subtitle.CuesCount,
+		constants.SubtitleSourceUpload,
+		constants.SubtitleSourceEmbedded,
+	).Scan(&subtitle.ID, &subtitle.CreatedAt, &subtitle.UpdatedAt)
+	if err != nil {
+		// Строка не обновилась: дорожку языка загрузил владелец
+		if errors.Is(err, sql.ErrNoRows) {
+			return constants.ErrAlreadyExists
+		}
+		return fmt.Errorf("failed to save video subtitles: %w", err)
+	}
+
+	return nil
+}
+
+func (r *VideoSubtitleRepository) Delete(ctx context.Context, videoID uuid.UUID, language string) (*entity.VideoSubtitle, error) {
+	query := `
+        DELETE FROM video_subtitles
+        WHERE video_id = $1
+        AND language = $2
+        RETURNING *
+    `
+
+	var subtitles []*entity.VideoSubtitle
+	if err := r.db.SelectContext(ctx, &subtitles, query, videoID, language); err != nil {
+		return nil, fmt.Errorf("failed to delete video subtitles: %w", err)
+	}
+
+	if len(subtitles) == 0 {
+		return nil, constants.ErrNotFound
+	}
+
+	return subtitles[0], nil
+}
@@ -0,0 +1,226 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"github.com/mrkbwp/gotube/pkg/constants"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/repositories"
+)
+
+type VideoThumbnailRepository struct {
+	db *sqlx.DB
+}
+
+func NewVideoThumbnailRepository(db *sqlx.DB) repositories.VideoThumbnailRepository {
+	return &VideoThumbnailRepository{db: db}
+}
+
+func (r *VideoThumbnailRepository) GetByVideoID(ctx context.Context, videoID uuid.UUID) ([]*entity.VideoThumbnail, error) {
+	query := `
+        SELECT * FROM video_thumbnails
+        WHERE video_id = $1
+        ORDER BY source = 'custom' DESC, position_seconds ASC NULLS LAST, created_at DESC
+    `
+
+	var thumbnails []*entity.VideoThumbnail
+	if err := r.db.SelectContext(ctx, &thumbnails, query, videoID); err != nil {
+		return nil, fmt.Errorf("failed to get video thumbnails: %w", err)
+	}
+
+	return thumbnails, nil
+}
+
+func (r *VideoThumbnailRepository) ReplaceGenerated(ctx context.Context, videoID uuid.UUID, thumbnails []*entity.VideoThumbnail) (string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Обложку, выбранную владельцем, повторная конвертация не меняет
+	var customSelected bool
+	err = tx.GetContext(ctx, &customSelected, `
+        SELECT EXISTS (
+            SELECT 1 FROM video_thumbnails
+            WHERE video_id = $1
+            AND is_selected
+            AND source = $2
+        )
+    `, videoID, constants.ThumbnailSourceCustom)
+	if err != nil {
+		return "", fmt.Errorf("failed to check selected thumbnail: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        DELETE FROM video_thumbnails
+        WHERE video_id = $1
+        AND source <> $2
+    `, videoID, constants.ThumbnailSourceCustom)
+	if err != nil {
+		return "", fmt.Errorf("failed to delete generated thumbnails: %w", err)
+	}
+
+	insertQuery := `
+        INSERT INTO video_thumbnails (video_id, source, position_seconds, url, sizes, is_selected, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW())
+        RETURNING id, created_at
+    `
+
+	for _, thumbnail := range thumbnails {
+		thumbnail.VideoID = videoID
+		if customSelected {
+			thumbnail.IsSelected = false
+		}
+
+		err = tx.QueryRowContext(ctx, insertQuery,
+			thumbnail.VideoID,
+			thumbnail.Source,
+			thumbnail.Position,
+			thumbnail.URL,
+			thumbnail.Sizes,
+			thumbnail.IsSelected,
+		).Scan(&thumbnail.ID, &thumbnail.CreatedAt)
+		if err != nil {
+			return "", fmt.Errorf("failed to create video thumbnail: %w", err)
+		}
+	}
+
+	var selectedURL string
+	err = tx.GetContext(ctx, &selectedURL, `
+        SELECT COALESCE((
+            SELECT url FROM video_thumbnails
+            WHERE video_id = $1
+            AND is_selected
+        ), '')
+    `, videoID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get selected thumbnail: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return selectedURL, nil
+}
+
+func (r *VideoThumbnailRepository) Create(ctx context.Context, thumbnail *entity.VideoThumbnail) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if thumbnail.IsSelected {
+		if err := unselectThumbnails(ctx, tx, thumbnail.VideoID); err != nil {
+			return err
+		}
+	}
+
+	query := `
+        INSERT INTO video_thumbnails (video_id, source, position_seconds, url, sizes, is_selected, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW())
+        RETURNING id, created_at
+    `
+
+	err = tx.QueryRowContext(ctx, query,
+		thumbnail.VideoID,
+		thumbnail.Source,
+		thumbnail.Position,
+		thumbnail.URL,
+		thumbnail.Sizes,
+		thumbnail.IsSelected,
+	).Scan(&thumbnail.ID, &thumbnail.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create video thumbnail: %w", err)
+	}
+
+	if thumbnail.IsSelected {
+		if err := setVideoThumbnail(ctx, tx, thumbnail.VideoID, thumbnail.URL); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *VideoThumbnailRepository) Select(ctx context.Context, videoID, thumbnailID uuid.UUID) (*entity.VideoThumbnail, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := unselectThumbnails(ctx, tx, videoID); err != nil {
+		return nil, err
+	}
+
+	query := `
+        UPDATE video_thumbnails
+        SET is_selected = true
+        WHERE id = $1
+        AND video_id = $2
+        RETURNING *
+    `
+
+	var thumbnails []*entity.VideoThumbnail
+	if err := tx.SelectContext(ctx, &thumbnails, query, thumbnailID, videoID); err != nil {
+		return nil, fmt.Errorf("failed to select video thumbnail: %w", err)
+	}
+
+	if len(thumbnails) == 0 {
+		return nil, constants.ErrNotFound
+	}
+
+	if err := setVideoThumbnail(ctx, tx, videoID, thumbnails[0].URL); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return thumbnails[0], nil
+}
+
+// unselectThumbnails снимает выбор со всех вариантов обложки видео
+func unselectThumbnails(ctx context.Context, tx *sqlx.Tx, videoID uuid.UUID) error {
+	query := `
+        UPDATE video_thumbnails
+        SET is_selected = false
+        WHERE video_id = $1
+        AND is_selected
+    `
+
+	if _, err := tx.ExecContext(ctx, query, videoID); err != nil {
+		return fmt.Errorf("failed to unselect video thumbnails: %w", err)
+	}
+
+	return nil
+}
+
+// setVideoThumbnail обновляет обложку в карточке видео
+func setVideoThumbnail(ctx context.Context, tx *sqlx.Tx, videoID uuid.UUID, url string) error {
+	query := `
+        UPDATE videos
+        SET thumbnail_url = $1,
+            updated_at = NOW()
+        WHERE id = $2
+        AND deleted_at IS NULL
+    `
+
+	if _, err := tx.ExecContext(ctx, query, url, videoID); err != nil {
+		return fmt.Errorf("failed to update video thumbnail: %w", err)
+	}
+
+	return nil
+}
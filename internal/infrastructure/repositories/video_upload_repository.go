@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/mrkbwp/gotube/pkg/constants"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/repositories"
+)
+
+type VideoUploadRepository struct {
+	db *sqlx.DB
+}
+
+func NewVideoUploadRepository(db *sqlx.DB) repositories.VideoUploadRepository {
+	return &VideoUploadRepository{db: db}
+}
+
+func (r *VideoUploadRepository) Create(ctx context.Context, upload *entity.VideoUpload) error {
+	query := `
+        INSERT INTO video_uploads (user_id, video_id, kind, upload_length, metadata, status, expires_at, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+        RETURNING id, upload_offset, created_at, updated_at
+    `
+
+	err := r.db.QueryRowContext(ctx, query,
+		upload.UserID,
+		upload.VideoID,
+		upload.Kind,
+		upload.Length,
+		upload.Metadata,
+		upload.Status,
+		upload.ExpiresAt,
+	).Scan(&upload.ID, &upload.Offset, &upload.CreatedAt, &upload.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create video upload: %w", err)
+	}
+
+	return nil
+}
+
+func (r *VideoUploadRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.VideoUpload, error) {
+	query := `SELECT * FROM video_uploads WHERE id = $1`
+
+	var upload entity.VideoUpload
+	if err := r.db.GetContext(ctx, &upload, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, constants.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get video upload: %w", err)
+	}
+
+	return &upload, nil
+}
+
+func (r *VideoUploadRepository) UpdateProgress(ctx context.Context, upload *entity.VideoUpload) error {
+	query := `
+        UPDATE video_uploads
+        SET upload_offset = $1,
+            storage_upload_id = $2,
+            parts = $3,
+            tail_size = $4,
+            hash_state = $5,
+            expires_at = $6,
+            updated_at = NOW()
+        WHERE id = $7
+        AND status = $8
+    `
+
+	result, err := r.db.ExecContext(ctx, query,
+		upload.Offset,
+		upload.StorageUploadID,
+		upload.Parts,
+		upload.TailSize,
+		upload.HashState,
+		upload.ExpiresAt,
+		upload.ID,
+		constants.UploadStatusUploading,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update video upload: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return constants.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *VideoUploadRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
+	query := `
+        UPDATE video_uploads
+        SET status = $1,
+            updated_at = NOW()
+        WHERE id = $2
+        AND status = $3
+    `
+
+	result, err := r.db.ExecContext(ctx, query, status, id, constants.UploadStatusUploading)
+	if err != nil {
+		return fmt.Errorf("failed to update video upload status: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return constants.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *VideoUploadRepository) GetExpired(ctx context.Context, limit int) ([]*entity.VideoUpload, error) {
+	query := `
+        SELECT * FROM video_uploads
+        WHERE status = $1
+        AND expires_at < NOW()
+        ORDER BY expires_at
+        LIMIT $2
+    `
+
+	var uploads []*entity.VideoUpload
+	if err := r.db.SelectContext(ctx, &uploads, query, constants.UploadStatusUploading, limit); err != nil {
+		return nil, fmt.Errorf("failed to get expired video uploads: %w", err)
+	}
+
+	return uploads, nil
+}
+
+func (r *VideoUploadRepository) Lock(ctx context.Context, id uuid.UUID) (func(), error) {
+	// Сессионная advisory-блокировка живет на отдельном соединении до unlock
+	conn, err := r.db.Connx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	var locked bool
+	err = conn.GetContext(ctx, &locked, `SELECT pg_try_advisory_lock(hashtextextended($1, 0))`, id.String())
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to lock video upload: %w", err)
+	}
+
+	if !locked {
+		conn.Close()
+		return nil, constants.ErrUploadLocked
+	}
+
+	return func() {
+		_, _ = conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtextextended($1, 0))`, id.String())
+		conn.Close()
+	}, nil
+}
@@ -0,0 +1,494 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/repositories"
+	"github.com/mrkbwp/gotube/internal/domain/services"
+	"github.com/mrkbwp/gotube/internal/dto"
+	"github.com/mrkbwp/gotube/pkg/constants"
+)
+
+const (
+	// batchArchiveField и batchFilesField - поля multipart с файлами пакета
+	batchArchiveField = "archive"
+	batchFilesField   = "files"
+
+	batchInterruptedMessage = "batch processing was interrupted"
+)
+
+// BatchService реализует интерфейс BatchService. Файлы пакета ждут во временной папке процесса,
+// который его принял, поэтому пакет, прерванный падением процесса, не продолжается, а завершается с ошибкой
+type BatchService struct {
+	batchRepo    repositories.UploadBatchRepository
+	videoService services.VideoService
+	tempDir      string
+	maxFileSize  int64
+	maxBatchSize int64
+
+	// running - папки пакетов, которые обрабатывает этот процесс
+	running  sync.Map
+	wg       sync.WaitGroup
+	ticker   *time.Ticker
+	stopChan chan struct{}
+}
+
+// NewBatchService создает новый экземпляр BatchService
+func NewBatchService(
+	batchRepo repositories.UploadBatchRepository,
+	videoService services.VideoService,
+	tempDir string,
+	maxFileSize, maxBatchSize int64,
+) services.BatchService {
+	return &BatchService{
+		batchRepo:    batchRepo,
+		videoService: videoService,
+		tempDir:      tempDir,
+		maxFileSize:  maxFileSize,
+		maxBatchSize: maxBatchSize,
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// batchFile файл пакета во временной папке или запись ZIP архива
+type batchFile struct {
+	path  string
+	entry *zip.File
+	size  int64
+}
+
+func (f *batchFile) open() (io.ReadCloser, error) {
+	if f.entry != nil {
+		return f.entry.Open()
+	}
+	return os.Open(f.path)
+}
+
+// stagedBatch файлы пакета, принятые во временную папку
+type stagedBatch struct {
+	dir     string
+	archive *zip.ReadCloser
+	files   map[string]*batchFile
+	// items - файлы элементов пакета в порядке манифеста
+	items []*batchFile
+}
+
+func (b *stagedBatch) close() {
+	if b.archive != nil {
+		_ = b.archive.Close()
+	}
+	if err := os.RemoveAll(b.dir); err != nil {
+		fmt.Printf("Failed to remove batch directory %s: %v\n", b.dir, err)
+	}
+}
+
+// CreateBatch сохраняет файлы пакета во временную папку, сверяет их с манифестом и запускает загрузку
+func (s *BatchService) CreateBatch(ctx context.Context, userID uuid.UUID, items []*entity.UploadBatchItem, files *multipart.Reader) (*entity.UploadBatch, error) {
+	if len(items) == 0 || len(items) > constants.MaxBatchItems {
+		return nil, fmt.Errorf("%w: manifest must list from 1 to %d files", constants.ErrInvalidBatch, constants.MaxBatchItems)
+	}
+
+	if err := os.MkdirAll(s.tempDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create batch directory: %w", err)
+	}
+	dir, err := os.MkdirTemp(s.tempDir, "batch-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch directory: %w", err)
+	}
+
+	staged := &stagedBatch{dir: dir, files: make(map[string]*batchFile)}
+	started := false
+	defer func() {
+		if !started {
+			staged.close()
+		}
+	}()
+
+	if err := s.stageFiles(staged, files); err != nil {
+		return nil, err
+	}
+	if err := s.matchFiles(staged, items); err != nil {
+		return nil, err
+	}
+
+	for i, item := range items {
+		item.Position = i + 1
+		item.Status = constants.BatchItemStatusPending
+	}
+
+	batch := &entity.UploadBatch{
+		UserID: userID,
+		Status: constants.BatchStatusProcessing,
+		Items:  items,
+	}
+
+	if err := s.batchRepo.Create(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	started = true
+	s.running.Store(dir, struct{}{})
+	s.wg.Add(1)
+	go s.processBatch(batch, staged)
+
+	return batch, nil
+}
+
+// GetBatch возвращает пакет пользователя
+func (s *BatchService) GetBatch(ctx context.Context, id, userID uuid.UUID) (*entity.UploadBatch, error) {
+	batch, err := s.batchRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, constants.ErrBatchNotFound
+		}
+		return nil, err
+	}
+
+	if batch.UserID != userID {
+		return nil, constants.ErrBatchNotFound
+	}
+
+	return batch, nil
+}
+
+// StartBatchProcessing запускает периодическое завершение пакетов, прерванных падением процесса
+func (s *BatchService) StartBatchProcessing() {
+	s.ticker = time.NewTicker(constants.BatchCleanupInterval)
+
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				s.cleanup(context.Background())
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// StopBatchProcessing останавливает очистку, прерывает пакеты после текущего файла и ждет их
+func (s *BatchService) StopBatchProcessing() {
+	if s.ticker != nil {
+		s.ticker.Stop()
+	}
+	close(s.stopChan)
+	s.wg.Wait()
+}
+
+// stageFiles сохраняет во временную папку ZIP из поля archive или файлы из полей files
+func (s *BatchService) stageFiles(staged *stagedBatch, files *multipart.Reader) error {
+	var total int64
+
+	for {
+		part, err := files.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", constants.ErrInvalidBatch, err)
+		}
+
+		switch part.FormName() {
+		case batchArchiveField:
+			if staged.archive != nil || len(staged.files) > 0 {
+				return fmt.Errorf("%w: send either one archive or files", constants.ErrInvalidBatch)
+			}
+
+			archivePath := filepath.Join(staged.dir, "archive.zip")
+			size, err := writeLimited(archivePath, part, s.maxBatchSize)
+			if err != nil {
+				return err
+			}
+			if size > s.maxBatchSize {
+				return fmt.Errorf("%w: limit is %d bytes", constants.ErrBatchTooLarge, s.maxBatchSize)
+			}
+			total += size
+
+			if err := staged.openArchive(archivePath); err != nil {
+				return err
+			}
+
+		case batchFilesField:
+			if staged.archive != nil {
+				return fmt.Errorf("%w: send either one archive or files", constants.ErrInvalidBatch)
+			}
+
+			name := filepath.Base(part.FileName())
+			if name == "." || name == string(filepath.Separator) {
+				return fmt.Errorf("%w: file name is required", constants.ErrInvalidBatch)
+			}
+			if _, ok := staged.files[name]; ok {
+				return fmt.Errorf("%w: file %s is sent more than once", constants.ErrInvalidBatch, name)
+			}
+
+			limit := min(s.maxFileSize, s.maxBatchSize-total)
+			filePath := filepath.Join(staged.dir, strconv.Itoa(len(staged.files)))
+			size, err := writeLimited(filePath, part, limit)
+			if err != nil {
+				return err
+			}
+			if size > limit {
+				if size > s.maxFileSize {
+					return fmt.Errorf("%w: %s, limit is %d bytes", constants.ErrFileTooLarge, name, s.maxFileSize)
+				}
+				return fmt.Errorf("%w: limit is %d bytes", constants.ErrBatchTooLarge, s.maxBatchSize)
+			}
+			total += size
+
+			staged.files[name] = &batchFile{path: filePath, size: size}
+
+		default:
+			return fmt.Errorf("%w: unexpected field %s", constants.ErrInvalidBatch, part.FormName())
+		}
+
+		_ = part.Close()
+	}
+
+	if len(staged.files) == 0 {
+		return fmt.Errorf("%w: no files", constants.ErrInvalidBatch)
+	}
+
+	return nil
+}
+
+// openArchive открывает сохраненный ZIP и запоминает его файлы
+func (b *stagedBatch) openArchive(archivePath string) error {
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("%w: archive is not a valid ZIP file", constants.ErrInvalidBatch)
+	}
+	b.archive = archive
+
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		b.files[entry.Name] = &batchFile{entry: entry, size: int64(entry.UncompressedSize64)}
+	}
+
+	return nil
+}
+
+// matchFiles находит файл каждого элемента манифеста. В архиве файл ищется по пути, а если путь
+// в манифесте не указан - по имени в любой папке. Отдельные файлы должны совпадать с манифестом один к одному
+func (s *BatchService) matchFiles(staged *stagedBatch, items []*entity.UploadBatchItem) error {
+	used := make(map[*batchFile]bool, len(items))
+	var total int64
+
+	for _, item := range items {
+		file, err := staged.find(item.Filename)
+		if err != nil {
+			return err
+		}
+		if used[file] {
+			return fmt.Errorf("%w: file %s is listed in manifest more than once", constants.ErrInvalidBatch, item.Filename)
+		}
+		used[file] = true
+
+		// Размеры записей архива известны до распаковки, отдельные файлы уже проверены при сохранении
+		if file.entry != nil {
+			if file.size > s.maxFileSize {
+				return fmt.Errorf("%w: %s, limit is %d bytes", constants.ErrFileTooLarge, item.Filename, s.maxFileSize)
+			}
+			total += file.size
+			if total > s.maxBatchSize {
+				return fmt.Errorf("%w: limit is %d bytes", constants.ErrBatchTooLarge, s.maxBatchSize)
+			}
+		}
+
+		staged.items = append(staged.items, file)
+	}
+
+	if staged.archive == nil {
+		for name, file := range staged.files {
+			if !used[file] {
+				return fmt.Errorf("%w: file %s is not listed in manifest", constants.ErrInvalidBatch, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// find возвращает файл пакета по имени из манифеста
+func (b *stagedBatch) find(name string) (*batchFile, error) {
+	if file, ok := b.files[name]; ok {
+		return file, nil
+	}
+
+	if b.archive != nil && path.Base(name) == name {
+		var found *batchFile
+		for entryName, file := range b.files {
+			if path.Base(entryName) != name {
+				continue
+			}
+			if found != nil {
+				return nil, fmt.Errorf("%w: archive has several files named %s, specify the path", constants.ErrInvalidBatch, name)
+			}
+			found = file
+		}
+		if found != nil {
+			return found, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: file %s from manifest is missing", constants.ErrInvalidBatch, name)
+}
+
+// processBatch по очереди загружает видео пакета
+func (s *BatchService) processBatch(batch *entity.UploadBatch, staged *stagedBatch) {
+	defer func() {
+		staged.close()
+		s.running.Delete(staged.dir)
+		s.wg.Done()
+	}()
+
+	ctx := context.Background()
+
+	for i, item := range batch.Items {
+		select {
+		case <-s.stopChan:
+			message := batchInterruptedMessage
+			for _, pending := range batch.Items[i:] {
+				pending.Status = constants.BatchItemStatusFailed
+				pending.Error = &message
+				s.updateItem(ctx, pending)
+			}
+			s.completeBatch(ctx, batch)
+			return
+		default:
+		}
+
+		s.processItem(ctx, batch.UserID, item, staged.items[i])
+	}
+
+	s.completeBatch(ctx, batch)
+}
+
+// processItem загружает видео одного файла пакета и сохраняет результат в элементе
+func (s *BatchService) processItem(ctx context.Context, userID uuid.UUID, item *entity.UploadBatchItem, file *batchFile) {
+	item.Status = constants.BatchItemStatusProcessing
+	s.updateItem(ctx, item)
+
+	video, err := s.uploadItem(ctx, userID, item, file)
+	if err != nil {
+		fmt.Printf("Failed to upload batch item %s (%s): %v\n", item.ID, item.Filename, err)
+
+		message := batchItemErrorMessage(err)
+		item.Status = constants.BatchItemStatusFailed
+		item.Error = &message
+	} else {
+		item.Status = constants.BatchItemStatusCompleted
+		item.VideoID = &video.ID
+		item.VideoCode = &video.VideoCode
+	}
+
+	s.updateItem(ctx, item)
+}
+
+func (s *BatchService) uploadItem(ctx context.Context, userID uuid.UUID, item *entity.UploadBatchItem, file *batchFile) (*entity.Video, error) {
+	reader, err := file.open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open batch file: %w", err)
+	}
+	defer reader.Close()
+
+	return s.videoService.UploadVideo(ctx, userID, reader, file.size, path.Base(item.Filename), dto.VideoDetails{
+		CategoryID:  item.CategoryID,
+		Title:       item.Title,
+		Description: item.Description,
+		Visibility:  item.Privacy,
+		PublishAt:   item.PublishAt,
+		Tags:        item.Tags,
+	})
+}
+
+func (s *BatchService) updateItem(ctx context.Context, item *entity.UploadBatchItem) {
+	if err := s.batchRepo.UpdateItem(ctx, item); err != nil {
+		fmt.Printf("Failed to update batch item %s: %v\n", item.ID, err)
+	}
+}
+
+func (s *BatchService) completeBatch(ctx context.Context, batch *entity.UploadBatch) {
+	if err := s.batchRepo.Complete(ctx, batch.ID); err != nil {
+		fmt.Printf("Failed to complete batch %s: %v\n", batch.ID, err)
+	}
+}
+
+// cleanup завершает пакеты, которые давно не менялись, и удаляет их оставшиеся файлы
+func (s *BatchService) cleanup(ctx context.Context) {
+	staleBefore := time.Now().Add(-constants.BatchStaleTimeout)
+
+	count, err := s.batchRepo.FailStale(ctx, staleBefore, batchInterruptedMessage)
+	if err != nil {
+		fmt.Printf("Failed to fail stale batches: %v\n", err)
+	} else if count > 0 {
+		fmt.Printf("Failed %d files of interrupted batches\n", count)
+	}
+
+	entries, err := os.ReadDir(s.tempDir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			fmt.Printf("Failed to read batch directory: %v\n", err)
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		dir := filepath.Join(s.tempDir, entry.Name())
+		if _, ok := s.running.Load(dir); ok {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(staleBefore) {
+			continue
+		}
+
+		if err := os.RemoveAll(dir); err != nil {
+			fmt.Printf("Failed to remove batch directory %s: %v\n", dir, err)
+		}
+	}
+}
+
+// writeLimited сохраняет не больше limit+1 байт, чтобы вызывающий заметил превышение лимита
+func writeLimited(filePath string, r io.Reader, limit int64) (int64, error) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create batch file: %w", err)
+	}
+	defer file.Close()
+
+	size, err := io.Copy(file, io.LimitReader(r, limit+1))
+	if err != nil {
+		return 0, fmt.Errorf("%w: failed to receive file: %v", constants.ErrInvalidBatch, err)
+	}
+
+	return size, nil
+}
+
+// batchItemErrorMessage возвращает причину неудачи для пользователя, не раскрывая внутренних ошибок
+func batchItemErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, constants.ErrFileTooLarge),
+		errors.Is(err, constants.ErrUnsupportedMediaType),
+		errors.Is(err, constants.ErrStorageQuotaExceeded),
+		errors.Is(err, constants.ErrDailyUploadQuotaExceeded):
+		return err.Error()
+	}
+	return "failed to upload video"
+}
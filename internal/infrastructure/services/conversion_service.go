@@ -2,29 +2,61 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/mrkbwp/gotube/internal/domain/entity"
 	"github.com/mrkbwp/gotube/internal/domain/repositories"
 	"github.com/mrkbwp/gotube/internal/infrastructure/conversion"
 	"github.com/mrkbwp/gotube/internal/infrastructure/storage"
+	"github.com/mrkbwp/gotube/pkg/config"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"github.com/mrkbwp/gotube/pkg/kafka"
+	"github.com/redis/go-redis/v9"
+	"time"
 )
 
 type ConversionService struct {
-	queue  *conversion.ConversionQueue
-	ffmpeg *conversion.FFmpegService
+	videoRepo repositories.VideoRepository
+	jobRepo   repositories.ConversionJobRepository
+	queue     *conversion.ConversionQueue
+	ffmpeg    *conversion.FFmpegService
 }
 
 func NewConversionService(
 	videoRepo repositories.VideoRepository,
-	storageClient *storage.MinioClient,
+	jobRepo repositories.ConversionJobRepository,
+	thumbnailRepo repositories.VideoThumbnailRepository,
+	subtitleRepo repositories.VideoSubtitleRepository,
+	storageClient storage.ObjectStorage,
+	redisClient *redis.Client,
 	tempDir string,
+	retryPolicies map[string]config.RetryPolicy,
+	maxDuration time.Duration,
+	deadLetterProducer *kafka.Producer,
+	processingProducer *kafka.Producer,
 ) *ConversionService {
 	ffmpeg := conversion.NewFFmpegService(tempDir)
 
 	service := &ConversionService{
-		ffmpeg: ffmpeg,
+		videoRepo: videoRepo,
+		jobRepo:   jobRepo,
+		ffmpeg:    ffmpeg,
 	}
 
-	queue := conversion.NewConversionQueue(videoRepo, storageClient, ffmpeg)
+	queue := conversion.NewConversionQueue(
+		videoRepo,
+		jobRepo,
+		thumbnailRepo,
+		subtitleRepo,
+		storageClient,
+		ffmpeg,
+		conversion.NewProgressTracker(redisClient),
+		retryPolicies,
+		maxDuration,
+		deadLetterProducer,
+		processingProducer,
+	)
 	service.queue = queue
 
 	return service
@@ -38,6 +70,48 @@ func (s *ConversionService) StopConversionQueue() {
 	s.queue.Stop()
 }
 
+func (s *ConversionService) StartLeaseReaper() {
+	s.queue.StartLeaseReaper()
+}
+
+func (s *ConversionService) StopLeaseReaper() {
+	s.queue.Stop()
+}
+
 func (s *ConversionService) ConvertVideo(ctx context.Context, video *entity.Video, quality *entity.VideoQuality) error {
 	return s.queue.ConvertVideo(ctx, video, quality)
 }
+
+func (s *ConversionService) ProcessVideo(ctx context.Context, videoID uuid.UUID) error {
+	return s.queue.ProcessVideo(ctx, videoID)
+}
+
+func (s *ConversionService) GetDeadLetters(ctx context.Context, page, limit int) ([]*entity.ConversionDeadLetter, int64, error) {
+	if page < 1 || limit < 1 {
+		return nil, 0, constants.ErrInvalidPagination
+	}
+
+	return s.jobRepo.GetDeadLetters(ctx, page, limit)
+}
+
+// RedriveDeadLetter возвращает задание в очередь со сброшенными попытками,
+// видео снова ждет конвертации
+func (s *ConversionService) RedriveDeadLetter(ctx context.Context, id uuid.UUID) error {
+	deadLetter, err := s.jobRepo.RedriveDeadLetter(ctx, id)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return constants.ErrDeadLetterNotFound
+		}
+		return fmt.Errorf("failed to redrive dead letter: %w", err)
+	}
+
+	if err := s.videoRepo.UpdateErrorMessage(ctx, deadLetter.VideoID, nil); err != nil {
+		return fmt.Errorf("failed to clear video error: %w", err)
+	}
+
+	if err := s.videoRepo.UpdateStatus(ctx, deadLetter.VideoID, string(constants.VideoStatusUploaded)); err != nil {
+		return fmt.Errorf("failed to update video status: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/repositories"
+	"github.com/mrkbwp/gotube/internal/domain/services"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"github.com/mrkbwp/gotube/pkg/safehttp"
+)
+
+// importLeaseMargin - запас аренды сверх таймаута скачивания на сохранение исходника и статусов
+const importLeaseMargin = 5 * time.Minute
+
+// ImportService реализует интерфейс ImportService. Задания хранятся в базе и арендуются,
+// поэтому импорт продолжится другим процессом, если этот упадет во время скачивания
+type ImportService struct {
+	importRepo   repositories.VideoImportRepository
+	videoRepo    repositories.VideoRepository
+	videoService services.VideoService
+	client       *http.Client
+	timeout      time.Duration
+
+	// active - импорты, которые скачивает этот процесс
+	active   sync.WaitGroup
+	slots    chan struct{}
+	ticker   *time.Ticker
+	stopChan chan struct{}
+}
+
+// NewImportService создает новый экземпляр ImportService
+func NewImportService(
+	importRepo repositories.VideoImportRepository,
+	videoRepo repositories.VideoRepository,
+	videoService services.VideoService,
+	timeout time.Duration,
+) services.ImportService {
+	return &ImportService{
+		importRepo:   importRepo,
+		videoRepo:    videoRepo,
+		videoService: videoService,
+		client:       safehttp.NewClient(timeout, constants.ImportMaxRedirects),
+		timeout:      timeout,
+		slots:        make(chan struct{}, constants.MaxConcurrentImports),
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// CreateImport создает видео в статусе importing и задание на скачивание исходника
+func (s *ImportService) CreateImport(ctx context.Context, userID, categoryID uuid.UUID, sourceURL, title, description string) (*entity.VideoImport, error) {
+	u, err := safehttp.ValidateURL(strings.TrimSpace(sourceURL))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", constants.ErrInvalidImportURL, err)
+	}
+
+	filename := path.Base(u.Path)
+	if filename == "/" || filename == "." {
+		filename = u.Hostname()
+	}
+
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = strings.TrimSuffix(filename, filepath.Ext(filename))
+	}
+
+	// Размер станет известен только при скачивании, там же он проверяется по лимиту файла и квотам
+	video, err := s.videoService.CreatePendingVideo(ctx, userID, categoryID, constants.VideoStatusImporting, 0, filename, title, description)
+	if err != nil {
+		return nil, err
+	}
+
+	videoImport := &entity.VideoImport{
+		VideoID:   video.ID,
+		UserID:    userID,
+		VideoCode: video.VideoCode,
+		SourceURL: u.String(),
+		Status:    constants.ImportStatusPending,
+	}
+
+	if err := s.importRepo.Create(ctx, videoImport); err != nil {
+		_ = s.videoService.DeleteVideo(ctx, video.ID)
+		return nil, err
+	}
+
+	return videoImport, nil
+}
+
+// GetImport возвращает импорт видео
+func (s *ImportService) GetImport(ctx context.Context, video *entity.Video) (*entity.VideoImport, error) {
+	videoImport, err := s.importRepo.GetByVideoID(ctx, video.ID)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, constants.ErrImportNotFound
+		}
+		return nil, err
+	}
+	videoImport.VideoCode = video.VideoCode
+
+	return videoImport, nil
+}
+
+// StartImportQueue запускает периодический поиск заданий импорта
+func (s *ImportService) StartImportQueue() {
+	s.ticker = time.NewTicker(constants.ImportCheckInterval)
+
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				s.processImports(context.Background())
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// StopImportQueue останавливает поиск заданий и ждет начатые скачивания
+func (s *ImportService) StopImportQueue() {
+	if s.ticker != nil {
+		s.ticker.Stop()
+	}
+	close(s.stopChan)
+	s.active.Wait()
+}
+
+// processImports берет задания на свободные места и скачивает их в фоне
+func (s *ImportService) processImports(ctx context.Context) {
+	s.failAbandoned(ctx)
+
+	free := cap(s.slots) - len(s.slots)
+	if free == 0 {
+		return
+	}
+
+	imports, err := s.importRepo.Acquire(ctx, s.timeout+importLeaseMargin, constants.ImportMaxAttempts, free)
+	if err != nil {
+		fmt.Printf("Failed to acquire video imports: %v\n", err)
+		return
+	}
+
+	for _, videoImport := range imports {
+		s.slots <- struct{}{}
+		s.active.Add(1)
+
+		go func(videoImport *entity.VideoImport) {
+			defer func() {
+				<-s.slots
+				s.active.Done()
+			}()
+			s.runImport(context.Background(), videoImport)
+		}(videoImport)
+	}
+}
+
+// runImport скачивает исходник и передает его в обычный путь загрузки и конвертации
+func (s *ImportService) runImport(ctx context.Context, videoImport *entity.VideoImport) {
+	video, err := s.videoService.GetVideoByID(ctx, videoImport.VideoID)
+	if err != nil {
+		fmt.Printf("Failed to get video %s for import %s: %v\n", videoImport.VideoID, videoImport.ID, err)
+		s.failImport(ctx, videoImport, err)
+		return
+	}
+
+	fmt.Printf("Importing video %s from %s (attempt %d)\n", video.ID, videoImport.SourceURL, videoImport.Attempts)
+
+	downloadCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if err := s.download(downloadCtx, videoImport, video); err != nil {
+		fmt.Printf("Failed to import video %s: %v\n", video.ID, err)
+		s.failImport(ctx, videoImport, err)
+		return
+	}
+
+	if err := s.importRepo.Complete(ctx, videoImport.ID, video.OriginalSize); err != nil {
+		fmt.Printf("Failed to complete import %s: %v\n", videoImport.ID, err)
+	}
+}
+
+// download скачивает исходник по ссылке. Размер из Content-Length проверяется по квотам до скачивания,
+// без него скачивание обрывается на лимите размера файла, а квоты проверяются после
+func (s *ImportService) download(ctx context.Context, videoImport *entity.VideoImport, video *entity.Video) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, videoImport.SourceURL, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", constants.ErrInvalidImportURL, err)
+	}
+	req.Header.Set("User-Agent", constants.ImportUserAgent)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: remote server responded %s", constants.ErrImportDownloadFailed, resp.Status)
+	}
+
+	size := resp.ContentLength
+	if size < 0 {
+		size = 0
+	}
+
+	return s.videoService.UploadVideoSource(ctx, video, resp.Body, size)
+}
+
+// failImport сохраняет причину неудачи в импорте и видео
+func (s *ImportService) failImport(ctx context.Context, videoImport *entity.VideoImport, err error) {
+	message := importErrorMessage(err)
+
+	if err := s.importRepo.Fail(ctx, videoImport.ID, message); err != nil {
+		fmt.Printf("Failed to mark import %s as failed: %v\n", videoImport.ID, err)
+	}
+	s.failVideo(ctx, videoImport.VideoID, message)
+}
+
+// failAbandoned завершает импорты, процессы которых падали во время скачивания ImportMaxAttempts раз
+func (s *ImportService) failAbandoned(ctx context.Context) {
+	message := "import was interrupted too many times"
+
+	imports, err := s.importRepo.FailAbandoned(ctx, constants.ImportMaxAttempts, message)
+	if err != nil {
+		fmt.Printf("Failed to fail abandoned imports: %v\n", err)
+		return
+	}
+
+	for _, videoImport := range imports {
+		s.failVideo(ctx, videoImport.VideoID, message)
+	}
+}
+
+func (s *ImportService) failVideo(ctx context.Context, videoID uuid.UUID, message string) {
+	if err := s.videoRepo.UpdateStatus(ctx, videoID, string(constants.VideoStatusImportFailed)); err != nil {
+		fmt.Printf("Failed to update video %s status: %v\n", videoID, err)
+	}
+	if err := s.videoRepo.UpdateErrorMessage(ctx, videoID, &message); err != nil {
+		fmt.Printf("Failed to update video %s error message: %v\n", videoID, err)
+	}
+}
+
+// importErrorMessage возвращает причину неудачи для пользователя, не раскрывая внутренних ошибок
+func importErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, safehttp.ErrBlockedAddress):
+		return "source address is not allowed"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "download timed out"
+	case errors.Is(err, constants.ErrImportDownloadFailed),
+		errors.Is(err, constants.ErrInvalidImportURL),
+		errors.Is(err, constants.ErrFileTooLarge),
+		errors.Is(err, constants.ErrStorageQuotaExceeded),
+		errors.Is(err, constants.ErrDailyUploadQuotaExceeded),
+		errors.Is(err, constants.ErrUnsupportedMediaType):
+		return err.Error()
+	}
+	return constants.ErrImportDownloadFailed.Error()
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/repositories"
+	"github.com/mrkbwp/gotube/internal/domain/services"
+	"github.com/mrkbwp/gotube/pkg/constants"
+)
+
+// QuotaService реализует интерфейс QuotaService. Лимит размера файла общий для всех,
+// квоты хранилища и суточных загрузок задаются в базе для роли или пользователя
+type QuotaService struct {
+	quotaRepo   repositories.UploadQuotaRepository
+	userRepo    repositories.UserRepository
+	maxFileSize int64
+}
+
+// NewQuotaService создает новый экземпляр QuotaService
+func NewQuotaService(quotaRepo repositories.UploadQuotaRepository, userRepo repositories.UserRepository, maxFileSize int64) services.QuotaService {
+	return &QuotaService{
+		quotaRepo:   quotaRepo,
+		userRepo:    userRepo,
+		maxFileSize: maxFileSize,
+	}
+}
+
+// CheckUpload проверяет размер исходника и квоты пользователя
+func (s *QuotaService) CheckUpload(ctx context.Context, userID uuid.UUID, size int64) error {
+	quota, err := s.checkStorage(ctx, userID, size)
+	if err != nil || quota == nil {
+		return err
+	}
+
+	return s.quotaRepo.ReserveDailyUpload(ctx, userID, size, quota.MaxDailyUploads, quota.MaxDailyBytes)
+}
+
+// CheckUploadSize проверяет размер исходника, ставший известным после CheckUpload
+func (s *QuotaService) CheckUploadSize(ctx context.Context, userID uuid.UUID, size int64) error {
+	quota, err := s.checkStorage(ctx, userID, size)
+	if err != nil || quota == nil {
+		return err
+	}
+
+	return s.quotaRepo.ReserveDailyBytes(ctx, userID, size, quota.MaxDailyBytes)
+}
+
+// checkStorage проверяет лимит размера файла и квоту хранилища и возвращает квоту пользователя
+// или nil, если она не задана и загрузки не ограничены
+func (s *QuotaService) checkStorage(ctx context.Context, userID uuid.UUID, size int64) (*entity.UploadQuota, error) {
+	if size > s.maxFileSize {
+		return nil, fmt.Errorf("%w: %d bytes, limit is %d bytes", constants.ErrFileTooLarge, size, s.maxFileSize)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	quota, err := s.quotaRepo.GetQuota(ctx, userID, user.Role)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if quota.MaxStorageBytes != nil {
+		usage, err := s.quotaRepo.GetStorageUsage(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if usage+size > *quota.MaxStorageBytes {
+			return nil, fmt.Errorf("%w: %d of %d bytes used", constants.ErrStorageQuotaExceeded, usage, *quota.MaxStorageBytes)
+		}
+	}
+
+	return quota, nil
+}
+
+// MaxFileSize возвращает максимальный размер исходника
+func (s *QuotaService) MaxFileSize() int64 {
+	return s.maxFileSize
+}
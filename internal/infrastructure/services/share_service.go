@@ -0,0 +1,346 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/repositories"
+	"github.com/mrkbwp/gotube/internal/domain/services"
+	"github.com/mrkbwp/gotube/internal/dto"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"github.com/mrkbwp/gotube/pkg/jwt"
+	"github.com/redis/go-redis/v9"
+)
+
+// ShareService реализует интерфейс ShareService. Токен ссылки подписан и содержит ее ID,
+// отзыв, пароль и лимит просмотров проверяются по записи в базе. Неверные пароли и списанные просмотры отмечаются в Redis
+type ShareService struct {
+	shareRepo       repositories.VideoShareRepository
+	userRepo        repositories.UserRepository
+	tokenService    *jwt.ShareTokenService
+	passwordService *jwt.PasswordService
+	redisClient     *redis.Client
+}
+
+// NewShareService создает новый экземпляр ShareService
+func NewShareService(
+	shareRepo repositories.VideoShareRepository,
+	userRepo repositories.UserRepository,
+	tokenService *jwt.ShareTokenService,
+	passwordService *jwt.PasswordService,
+	redisClient *redis.Client,
+) services.ShareService {
+	return &ShareService{
+		shareRepo:       shareRepo,
+		userRepo:        userRepo,
+		tokenService:    tokenService,
+		passwordService: passwordService,
+		redisClient:     redisClient,
+	}
+}
+
+// CreateShareLink создает ссылку доступа к видео
+func (s *ShareService) CreateShareLink(
+	ctx context.Context,
+	video *entity.Video,
+	userID uuid.UUID,
+	options dto.ShareLinkOptions,
+) (*entity.VideoShareLink, error) {
+	now := time.Now()
+	expiresAt := options.ExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = now.Add(constants.DefaultShareLinkTTL)
+	}
+	if !expiresAt.After(now) || expiresAt.After(now.Add(constants.MaxShareLinkTTL)) {
+		return nil, fmt.Errorf("%w: must be in the future and at most %s away", constants.ErrInvalidShareExpiry, constants.MaxShareLinkTTL)
+	}
+
+	link := &entity.VideoShareLink{
+		VideoID:   video.ID,
+		CreatedBy: userID,
+		MaxViews:  options.MaxViews,
+		ExpiresAt: expiresAt,
+	}
+
+	if options.Password != "" {
+		passwordHash, err := s.passwordService.HashPassword(options.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash share link password: %w", err)
+		}
+		link.PasswordHash = &passwordHash
+	}
+
+	if err := s.shareRepo.CreateLink(ctx, link); err != nil {
+		return nil, err
+	}
+
+	if err := s.fillToken(link); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+// GetShareLinks возвращает ссылки доступа видео
+func (s *ShareService) GetShareLinks(ctx context.Context, video *entity.Video) ([]*entity.VideoShareLink, error) {
+	links, err := s.shareRepo.GetVideoLinks(ctx, video.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, link := range links {
+		if err := s.fillToken(link); err != nil {
+			return nil, err
+		}
+	}
+
+	return links, nil
+}
+
+// RevokeShareLink отзывает ссылку доступа
+func (s *ShareService) RevokeShareLink(ctx context.Context, video *entity.Video, linkID uuid.UUID) error {
+	if err := s.shareRepo.RevokeLink(ctx, video.ID, linkID); err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return constants.ErrShareLinkNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GrantAccess выдает пользователю доступ к видео
+func (s *ShareService) GrantAccess(ctx context.Context, video *entity.Video, userID, grantedBy uuid.UUID) (*entity.VideoAccessGrant, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID.String()); err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	grant := &entity.VideoAccessGrant{
+		VideoID:   video.ID,
+		UserID:    userID,
+		GrantedBy: grantedBy,
+	}
+	if err := s.shareRepo.CreateGrant(ctx, grant); err != nil {
+		return nil, err
+	}
+
+	return grant, nil
+}
+
+// GetAccessGrants возвращает доступы, выданные к видео
+func (s *ShareService) GetAccessGrants(ctx context.Context, video *entity.Video) ([]*entity.VideoAccessGrant, error) {
+	return s.shareRepo.GetVideoGrants(ctx, video.ID)
+}
+
+// RevokeAccess отзывает доступ пользователя к видео
+func (s *ShareService) RevokeAccess(ctx context.Context, video *entity.Video, userID uuid.UUID) error {
+	if err := s.shareRepo.DeleteGrant(ctx, video.ID, userID); err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return constants.ErrAccessGrantNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// CheckAccess проверяет выданный зрителю доступ или его ссылку
+func (s *ShareService) CheckAccess(ctx context.Context, video *entity.Video, viewer dto.VideoViewer, countView bool) error {
+	if viewer.UserID != nil {
+		granted, err := s.shareRepo.HasGrant(ctx, video.ID, *viewer.UserID)
+		if err != nil {
+			return err
+		}
+		if granted {
+			return nil
+		}
+	}
+
+	if viewer.ShareToken == "" {
+		return constants.ErrVideoPrivate
+	}
+
+	link, passwordVerified, err := s.getActiveLink(ctx, video, viewer.ShareToken)
+	if err != nil {
+		return err
+	}
+
+	if !passwordVerified {
+		if err := s.checkPassword(ctx, link, viewer); err != nil {
+			return err
+		}
+	}
+
+	if link.MaxViews == nil {
+		return nil
+	}
+
+	// Просмотр списывается на первом плейлисте или запросе файлов, дальше плеер с того же IP
+	// получает остальные плейлисты без списания, иначе он упирался бы в лимит посреди просмотра
+	viewKey := fmt.Sprintf(constants.ShareViewKey, link.ID, viewer.IP)
+	if s.redisClient != nil {
+		charged, err := s.redisClient.Exists(ctx, viewKey).Result()
+		if err != nil {
+			return fmt.Errorf("failed to check share link view: %w", err)
+		}
+		if charged > 0 {
+			return nil
+		}
+	}
+
+	if !countView {
+		if link.Views >= *link.MaxViews {
+			return constants.ErrShareViewLimitReached
+		}
+		return nil
+	}
+
+	return s.countView(ctx, link, viewKey)
+}
+
+// countView списывает просмотр ссылки и отмечает его для IP зрителя. Отметка ставится до списания,
+// чтобы параллельные запросы плеера не списали несколько просмотров
+func (s *ShareService) countView(ctx context.Context, link *entity.VideoShareLink, viewKey string) error {
+	if s.redisClient != nil {
+		marked, err := s.redisClient.SetNX(ctx, viewKey, 1, constants.ShareViewWindow).Result()
+		if err != nil {
+			return fmt.Errorf("failed to mark share link view: %w", err)
+		}
+		if !marked {
+			return nil
+		}
+	}
+
+	if err := s.shareRepo.CountLinkView(ctx, link.ID); err != nil {
+		if s.redisClient != nil {
+			if delErr := s.redisClient.Del(ctx, viewKey).Err(); delErr != nil {
+				fmt.Printf("Failed to remove share link view mark: %v\n", delErr)
+			}
+		}
+		if errors.Is(err, constants.ErrNotFound) {
+			return constants.ErrShareViewLimitReached
+		}
+		return err
+	}
+
+	return nil
+}
+
+// UnlockShareLink проверяет пароль ссылки один раз и выдает токен зрителя, с которым плеер
+// запрашивает плейлисты и файлы без пароля. Токен действует ShareViewerTokenTTL, но не дольше ссылки
+func (s *ShareService) UnlockShareLink(ctx context.Context, video *entity.Video, viewer dto.VideoViewer) (*dto.ShareViewerToken, error) {
+	link, passwordVerified, err := s.getActiveLink(ctx, video, viewer.ShareToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if !passwordVerified {
+		if err := s.checkPassword(ctx, link, viewer); err != nil {
+			return nil, err
+		}
+	}
+
+	expiresAt := time.Now().Add(constants.ShareViewerTokenTTL)
+	if link.ExpiresAt.Before(expiresAt) {
+		expiresAt = link.ExpiresAt
+	}
+
+	token, err := s.tokenService.GenerateViewerToken(link.ID, link.VideoID, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ShareViewerToken{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// getActiveLink проверяет подпись токена и возвращает действующую ссылку этого видео
+// и признак того, что пароль ссылки зритель уже ввел
+func (s *ShareService) getActiveLink(ctx context.Context, video *entity.Video, token string) (*entity.VideoShareLink, bool, error) {
+	claims, err := s.tokenService.ValidateShareToken(token)
+	if err != nil {
+		if errors.Is(err, jwt.ErrShareTokenExpired) {
+			return nil, false, constants.ErrShareLinkExpired
+		}
+		return nil, false, constants.ErrInvalidShareLink
+	}
+	if claims.VideoID != video.ID {
+		return nil, false, constants.ErrInvalidShareLink
+	}
+
+	link, err := s.shareRepo.GetLink(ctx, claims.LinkID)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, false, constants.ErrInvalidShareLink
+		}
+		return nil, false, err
+	}
+
+	if link.RevokedAt != nil || link.VideoID != video.ID {
+		return nil, false, constants.ErrInvalidShareLink
+	}
+	if !time.Now().Before(link.ExpiresAt) {
+		return nil, false, constants.ErrShareLinkExpired
+	}
+
+	return link, claims.PasswordVerified, nil
+}
+
+// checkPassword сверяет пароль ссылки. После SharePasswordMaxAttempts неверных паролей с одного IP
+// ссылка для этого IP закрывается на SharePasswordAttemptsWindow, bcrypt при этом уже не выполняется
+func (s *ShareService) checkPassword(ctx context.Context, link *entity.VideoShareLink, viewer dto.VideoViewer) error {
+	if link.PasswordHash == nil {
+		return nil
+	}
+	if viewer.SharePassword == "" {
+		return constants.ErrSharePasswordRequired
+	}
+
+	attemptsKey := fmt.Sprintf(constants.SharePasswordAttemptsKey, link.ID, viewer.IP)
+
+	if s.redisClient != nil {
+		attempts, err := s.redisClient.Get(ctx, attemptsKey).Int()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return fmt.Errorf("failed to get share password attempts: %w", err)
+		}
+		if attempts >= constants.SharePasswordMaxAttempts {
+			return constants.ErrTooManyShareAttempts
+		}
+	}
+
+	if s.passwordService.CheckPasswordHash(viewer.SharePassword, *link.PasswordHash) {
+		return nil
+	}
+
+	if s.redisClient != nil {
+		pipe := s.redisClient.TxPipeline()
+		pipe.Incr(ctx, attemptsKey)
+		pipe.Expire(ctx, attemptsKey, constants.SharePasswordAttemptsWindow)
+		if _, err := pipe.Exec(ctx); err != nil {
+			fmt.Printf("Failed to save share password attempt: %v\n", err)
+		}
+	}
+
+	return constants.ErrInvalidSharePassword
+}
+
+// fillToken подписывает токен ссылки, для одной ссылки он всегда одинаковый
+func (s *ShareService) fillToken(link *entity.VideoShareLink) error {
+	token, err := s.tokenService.GenerateShareToken(link.ID, link.VideoID, link.CreatedAt, link.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	link.Token = token
+	link.HasPassword = link.PasswordHash != nil
+	return nil
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/repositories"
+	"github.com/mrkbwp/gotube/internal/dto"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"github.com/mrkbwp/gotube/pkg/jwt"
+)
+
+// fakeShareRepo хранит ссылки и доступы в памяти, остальные методы репозитория не вызываются
+type fakeShareRepo struct {
+	repositories.VideoShareRepository
+	links  map[uuid.UUID]*entity.VideoShareLink
+	grants map[uuid.UUID]bool
+}
+
+func (r *fakeShareRepo) GetLink(ctx context.Context, id uuid.UUID) (*entity.VideoShareLink, error) {
+	link, ok := r.links[id]
+	if !ok {
+		return nil, constants.ErrNotFound
+	}
+	return link, nil
+}
+
+func (r *fakeShareRepo) HasGrant(ctx context.Context, videoID, userID uuid.UUID) (bool, error) {
+	return r.grants[userID], nil
+}
+
+func (r *fakeShareRepo) CountLinkView(ctx context.Context, id uuid.UUID) error {
+	link := r.links[id]
+	if link.MaxViews != nil && link.Views >= *link.MaxViews {
+		return constants.ErrNotFound
+	}
+	link.Views++
+	return nil
+}
+
+func TestShareServiceCheckAccess(t *testing.T) {
+	passwordService := jwt.NewPasswordService()
+	tokenService := jwt.NewShareTokenService("secret")
+
+	video := &entity.Video{ID: uuid.New()}
+	otherVideo := &entity.Video{ID: uuid.New()}
+	granted := uuid.New()
+	stranger := uuid.New()
+
+	passwordHash, err := passwordService.HashPassword("secret-password")
+	if err != nil {
+		t.Fatalf("HashPassword(): %v", err)
+	}
+	revokedAt := time.Now().Add(-time.Minute)
+	maxViews := 1
+
+	newLink := func(videoID uuid.UUID) *entity.VideoShareLink {
+		return &entity.VideoShareLink{ID: uuid.New(), VideoID: videoID, CreatedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+	}
+	open := newLink(video.ID)
+	protected := newLink(video.ID)
+	protected.PasswordHash = &passwordHash
+	revoked := newLink(video.ID)
+	revoked.RevokedAt = &revokedAt
+	expired := newLink(video.ID)
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	limited := newLink(video.ID)
+	limited.MaxViews = &maxViews
+	foreign := newLink(otherVideo.ID)
+
+	repo := &fakeShareRepo{
+		links:  map[uuid.UUID]*entity.VideoShareLink{},
+		grants: map[uuid.UUID]bool{granted: true},
+	}
+	for _, link := range []*entity.VideoShareLink{open, protected, revoked, expired, limited, foreign} {
+		repo.links[link.ID] = link
+	}
+
+	service := NewShareService(repo, nil, tokenService, passwordService, nil)
+
+	token := func(link *entity.VideoShareLink) string {
+		t.Helper()
+		token, err := tokenService.GenerateShareToken(link.ID, link.VideoID, link.CreatedAt, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("GenerateShareToken(): %v", err)
+		}
+		return token
+	}
+	viewerToken, err := tokenService.GenerateViewerToken(protected.ID, video.ID, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GenerateViewerToken(): %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		viewer    dto.VideoViewer
+		countView bool
+		wantErr   error
+	}{
+		{name: "anonymous", viewer: dto.VideoViewer{}, wantErr: constants.ErrVideoPrivate},
+		{name: "user without grant", viewer: dto.VideoViewer{UserID: &stranger}, wantErr: constants.ErrVideoPrivate},
+		{name: "user with grant", viewer: dto.VideoViewer{UserID: &granted}},
+		{name: "open link", viewer: dto.VideoViewer{ShareToken: token(open)}},
+		{name: "garbage token", viewer: dto.VideoViewer{ShareToken: "garbage"}, wantErr: constants.ErrInvalidShareLink},
+		{name: "link of another video", viewer: dto.VideoViewer{ShareToken: token(foreign)}, wantErr: constants.ErrInvalidShareLink},
+		{name: "revoked link", viewer: dto.VideoViewer{ShareToken: token(revoked)}, wantErr: constants.ErrInvalidShareLink},
+		{name: "expired link", viewer: dto.VideoViewer{ShareToken: token(expired)}, wantErr: constants.ErrShareLinkExpired},
+		{name: "password missing", viewer: dto.VideoViewer{ShareToken: token(protected)}, wantErr: constants.ErrSharePasswordRequired},
+		{name: "wrong password", viewer: dto.VideoViewer{ShareToken: token(protected), SharePassword: "guess"}, wantErr: constants.ErrInvalidSharePassword},
+		{name: "right password", viewer: dto.VideoViewer{ShareToken: token(protected), SharePassword: "secret-password"}},
+		{name: "viewer token without password", viewer: dto.VideoViewer{ShareToken: viewerToken}},
+		{name: "view limit not counted", viewer: dto.VideoViewer{ShareToken: token(limited)}},
+		{name: "first counted view", viewer: dto.VideoViewer{ShareToken: token(limited)}, countView: true},
+		{name: "view limit reached", viewer: dto.VideoViewer{ShareToken: token(limited)}, countView: true, wantErr: constants.ErrShareViewLimitReached},
+		{name: "view limit reached without counting", viewer: dto.VideoViewer{ShareToken: token(limited)}, wantErr: constants.ErrShareViewLimitReached},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.CheckAccess(context.Background(), video, tt.viewer, tt.countView)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckAccess() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
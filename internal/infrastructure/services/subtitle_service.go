@@ -0,0 +1,136 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/repositories"
+	"github.com/mrkbwp/gotube/internal/domain/services"
+	"github.com/mrkbwp/gotube/internal/infrastructure/storage"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"github.com/mrkbwp/gotube/pkg/subtitles"
+)
+
+// SubtitleService реализует интерфейс SubtitleService
+type SubtitleService struct {
+	subtitleRepo  repositories.VideoSubtitleRepository
+	storageClient storage.ObjectStorage
+}
+
+// NewSubtitleService создает новый экземпляр SubtitleService
+func NewSubtitleService(subtitleRepo repositories.VideoSubtitleRepository, storageClient storage.ObjectStorage) services.SubtitleService {
+	return &SubtitleService{
+		subtitleRepo:  subtitleRepo,
+		storageClient: storageClient,
+	}
+}
+
+// GetSubtitles возвращает субтитры видео с временными ссылками на WebVTT
+func (s *SubtitleService) GetSubtitles(ctx context.Context, video *entity.Video) ([]*entity.VideoSubtitle, error) {
+	items, err := s.subtitleRepo.GetByVideoID(ctx, video.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if err := s.setURL(ctx, video, item); err != nil {
+			return nil, err
+		}
+	}
+
+	return items, nil
+}
+
+// UploadSubtitle проверяет SRT или WebVTT, сохраняет его в WebVTT и заменяет дорожку того же языка
+func (s *SubtitleService) UploadSubtitle(ctx context.Context, video *entity.Video, lang, label string, file io.Reader) (*entity.VideoSubtitle, error) {
+	lang, err := subtitles.NormalizeLanguage(lang)
+	if err != nil {
+		return nil, constants.ErrInvalidLanguage
+	}
+
+	label = strings.TrimSpace(label)
+	if label == "" {
+		label = lang
+	}
+
+	// Читаем на байт больше лимита, чтобы отличить файл ровно в лимит от большего
+	data, err := io.ReadAll(io.LimitReader(file, constants.MaxSubtitleUploadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subtitles: %w", err)
+	}
+	if len(data) > constants.MaxSubtitleUploadSize {
+		return nil, constants.ErrSubtitlesTooLarge
+	}
+
+	cues, err := subtitles.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", constants.ErrInvalidSubtitles, err)
+	}
+	if err := subtitles.Validate(cues, time.Duration(video.Duration)*time.Second); err != nil {
+		return nil, fmt.Errorf("%w: %v", constants.ErrInvalidSubtitles, err)
+	}
+
+	subtitle := &entity.VideoSubtitle{
+		VideoID:     video.ID,
+		Language:    lang,
+		Label:       label,
+		Source:      constants.SubtitleSourceUpload,
+		StoragePath: video.GetSubtitlePath(lang),
+		CuesCount:   len(cues),
+	}
+
+	vtt := subtitles.WriteVTT(cues)
+	if _, err := s.storageClient.UploadFile(ctx, video.BucketID, subtitle.StoragePath, bytes.NewReader(vtt), storage.UploadOptions{Size: int64(len(vtt))}); err != nil {
+		return nil, fmt.Errorf("failed to upload subtitles: %w", err)
+	}
+
+	if err := s.subtitleRepo.Upsert(ctx, subtitle); err != nil {
+		return nil, err
+	}
+
+	if err := s.setURL(ctx, video, subtitle); err != nil {
+		return nil, err
+	}
+
+	return subtitle, nil
+}
+
+// DeleteSubtitle удаляет дорожку субтитров языка
+func (s *SubtitleService) DeleteSubtitle(ctx context.Context, video *entity.Video, lang string) error {
+	lang, err := subtitles.NormalizeLanguage(lang)
+	if err != nil {
+		return constants.ErrInvalidLanguage
+	}
+
+	subtitle, err := s.subtitleRepo.Delete(ctx, video.ID, lang)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return constants.ErrSubtitlesNotFound
+		}
+		return err
+	}
+
+	// Запись уже удалена, оставшийся файл ни на что не влияет
+	if err := s.storageClient.DeleteFile(ctx, video.BucketID, subtitle.StoragePath); err != nil {
+		fmt.Printf("Failed to delete subtitles file %s: %v\n", subtitle.StoragePath, err)
+	}
+
+	return nil
+}
+
+// setURL формирует временную ссылку на WebVTT, бакет видео закрыт
+func (s *SubtitleService) setURL(ctx context.Context, video *entity.Video, subtitle *entity.VideoSubtitle) error {
+	url, err := s.storageClient.GetFileURL(ctx, video.BucketID, subtitle.StoragePath, int(time.Minute*10))
+	if err != nil {
+		return fmt.Errorf("failed to generate url: %w", err)
+	}
+
+	subtitle.URL = url
+	return nil
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/repositories"
+	"github.com/mrkbwp/gotube/internal/domain/services"
+	"github.com/mrkbwp/gotube/internal/infrastructure/storage"
+	"github.com/mrkbwp/gotube/pkg/constants"
+	"github.com/mrkbwp/gotube/pkg/imaging"
+)
+
+// ThumbnailService реализует интерфейс ThumbnailService
+type ThumbnailService struct {
+	thumbnailRepo repositories.VideoThumbnailRepository
+	storageClient storage.ObjectStorage
+}
+
+// NewThumbnailService создает новый экземпляр ThumbnailService
+func NewThumbnailService(thumbnailRepo repositories.VideoThumbnailRepository, storageClient storage.ObjectStorage) services.ThumbnailService {
+	return &ThumbnailService{
+		thumbnailRepo: thumbnailRepo,
+		storageClient: storageClient,
+	}
+}
+
+// GetThumbnails возвращает варианты обложки видео
+func (s *ThumbnailService) GetThumbnails(ctx context.Context, video *entity.Video) ([]*entity.VideoThumbnail, error) {
+	return s.thumbnailRepo.GetByVideoID(ctx, video.ID)
+}
+
+// SelectThumbnail делает вариант обложкой видео
+func (s *ThumbnailService) SelectThumbnail(ctx context.Context, video *entity.Video, thumbnailID uuid.UUID) (*entity.VideoThumbnail, error) {
+	thumbnail, err := s.thumbnailRepo.Select(ctx, video.ID, thumbnailID)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, constants.ErrThumbnailNotFound
+		}
+		return nil, err
+	}
+
+	return thumbnail, nil
+}
+
+// UploadCustomThumbnail проверяет изображение, сохраняет его в стандартных размерах и делает обложкой видео
+func (s *ThumbnailService) UploadCustomThumbnail(ctx context.Context, video *entity.Video, file io.Reader) (*entity.VideoThumbnail, error) {
+	// Читаем на байт больше лимита, чтобы отличить файл ровно в лимит от большего
+	data, err := io.ReadAll(io.LimitReader(file, constants.MaxThumbnailUploadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read thumbnail: %w", err)
+	}
+	if len(data) > constants.MaxThumbnailUploadSize {
+		return nil, constants.ErrThumbnailTooLarge
+	}
+
+	// Размеры проверяем до полного декодирования
+	config, _, err := imaging.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, constants.ErrInvalidThumbnail
+	}
+	if config.Width < constants.MinThumbnailWidth || config.Height < constants.MinThumbnailHeight {
+		return nil, constants.ErrThumbnailTooSmall
+	}
+
+	img, _, err := imaging.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, constants.ErrInvalidThumbnail
+	}
+
+	// Каждая загрузка хранится отдельно, чтобы к ней можно было вернуться
+	uploadID := uuid.New()
+	sizes := entity.Metadata{}
+	var url string
+	for _, size := range constants.ThumbnailSizes {
+		// Не увеличиваем изображение больше исходного
+		if size.Width > config.Width || size.Height > config.Height {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := imaging.EncodeJPEG(&buf, imaging.Cover(img, size.Width, size.Height), constants.ThumbnailJPEGQuality); err != nil {
+			return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+		}
+
+		objectName := fmt.Sprintf("%s/custom_%s_%s.jpg", video.GetThumbnailsPath(), uploadID, size.Name)
+		if _, err := s.storageClient.UploadFile(ctx, constants.ThumbnailsBucket, objectName, &buf, storage.UploadOptions{Size: int64(buf.Len())}); err != nil {
+			return nil, fmt.Errorf("failed to upload thumbnail: %w", err)
+		}
+
+		sizeURL := s.storageClient.GetPublicURL(constants.ThumbnailsBucket, objectName)
+		sizes[size.Name] = sizeURL
+		// Обложкой видео становится самый большой размер
+		if url == "" {
+			url = sizeURL
+		}
+	}
+
+	thumbnail := &entity.VideoThumbnail{
+		VideoID:    video.ID,
+		Source:     constants.ThumbnailSourceCustom,
+		URL:        url,
+		Sizes:      sizes,
+		IsSelected: true,
+	}
+
+	if err := s.thumbnailRepo.Create(ctx, thumbnail); err != nil {
+		return nil, fmt.Errorf("failed to save thumbnail: %w", err)
+	}
+
+	return thumbnail, nil
+}
@@ -0,0 +1,571 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/internal/domain/repositories"
+	"github.com/mrkbwp/gotube/internal/domain/services"
+	"github.com/mrkbwp/gotube/internal/dto"
+	"github.com/mrkbwp/gotube/internal/infrastructure/storage"
+	"github.com/mrkbwp/gotube/pkg/constants"
+)
+
+// expiredUploadsBatch - сколько просроченных загрузок удаляется за один проход
+const expiredUploadsBatch = 100
+
+// partBufferPool переиспользует буферы частей UploadPartSize между запросами tus,
+// чтобы каждый PATCH не выделял заново 16 МБ
+var partBufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, constants.UploadPartSize)
+		return &buf
+	},
+}
+
+// UploadService реализует интерфейс UploadService поверх составной загрузки хранилища.
+// Куски tus произвольного размера копятся в хвосте до части UploadPartSize
+type UploadService struct {
+	uploadRepo    repositories.VideoUploadRepository
+	videoService  services.VideoService
+	storageClient storage.ObjectStorage
+	ticker        *time.Ticker
+	stopChan      chan struct{}
+}
+
+// NewUploadService создает новый экземпляр UploadService
+func NewUploadService(uploadRepo repositories.VideoUploadRepository, videoService services.VideoService, storageClient storage.ObjectStorage) services.UploadService {
+	return &UploadService{
+		uploadRepo:    uploadRepo,
+		videoService:  videoService,
+		storageClient: storageClient,
+		stopChan:      make(chan struct{}),
+	}
+}
+
+// CreateUpload создает видео в статусе uploading и загрузку его исходника
+func (s *UploadService) CreateUpload(ctx context.Context, userID uuid.UUID, length int64, metadata map[string]string) (*entity.VideoUpload, error) {
+	return s.createUpload(ctx, userID, constants.UploadKindTus, length, metadata)
+}
+
+// CreateDirectUpload создает загрузку исходника и форму для нее. Форма пропускает только файл заявленных
+// размера и типа, поэтому API не проксирует байты исходника
+func (s *UploadService) CreateDirectUpload(ctx context.Context, userID uuid.UUID, length int64, metadata map[string]string) (*dto.DirectUploadResponse, error) {
+	contentType := strings.ToLower(strings.TrimSpace(metadata["content_type"]))
+	if !strings.HasPrefix(contentType, "video/") {
+		return nil, fmt.Errorf("%w: content_type must be video/*", constants.ErrInvalidUpload)
+	}
+
+	checksum := strings.ToLower(strings.TrimSpace(metadata["checksum"]))
+	if checksum != "" {
+		if raw, err := hex.DecodeString(checksum); err != nil || len(raw) != sha256.Size {
+			return nil, fmt.Errorf("%w: checksum must be hex SHA-256", constants.ErrInvalidUpload)
+		}
+	}
+
+	// Сохраняем нормализованные значения, с ними исходник сверяется при завершении
+	uploadMetadata := map[string]string{}
+	for key, value := range metadata {
+		uploadMetadata[key] = value
+	}
+	uploadMetadata["content_type"] = contentType
+	uploadMetadata["checksum"] = checksum
+
+	upload, err := s.createUpload(ctx, userID, constants.UploadKindDirect, length, uploadMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	video, err := s.videoService.GetVideoByID(ctx, upload.VideoID)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.storageClient.PresignedPostPolicy(ctx, video.BucketID, video.GetStorageFilePath(constants.VideoQualityOriginal), storage.PostPolicy{
+		ContentType: contentType,
+		MinSize:     length,
+		MaxSize:     length,
+		Checksum:    checksum,
+		Expires:     constants.DirectUploadPolicyExpiration,
+	})
+	if err != nil {
+		_ = s.discardUpload(ctx, upload, constants.UploadStatusTerminated)
+		return nil, err
+	}
+
+	return &dto.DirectUploadResponse{
+		Upload:    upload,
+		URL:       post.URL,
+		FormData:  post.FormData,
+		ExpiresAt: post.ExpiresAt,
+	}, nil
+}
+
+// CompleteDirectUpload сверяет загруженный в хранилище исходник с заявленным размером и сигнатурой видео
+// и переводит видео в uploaded. Контрольную сумму проверило хранилище по условиям формы
+func (s *UploadService) CompleteDirectUpload(ctx context.Context, upload *entity.VideoUpload) (*entity.VideoUpload, error) {
+	unlock, err := s.uploadRepo.Lock(ctx, upload.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	upload, err = s.GetUpload(ctx, upload.ID, upload.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Kind != constants.UploadKindDirect {
+		return nil, constants.ErrUploadNotFound
+	}
+	if upload.Status == constants.UploadStatusCompleted {
+		return nil, constants.ErrUploadCompleted
+	}
+
+	video, err := s.videoService.GetVideoByID(ctx, upload.VideoID)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := s.storageClient.StatFile(ctx, video.BucketID, video.GetStorageFilePath(constants.VideoQualityOriginal))
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, constants.ErrUploadNotReceived
+		}
+		return nil, err
+	}
+	if object.Size != upload.Length {
+		return nil, fmt.Errorf("%w: uploaded %d bytes, expected %d", constants.ErrInvalidUpload, object.Size, upload.Length)
+	}
+
+	// Видео уходит на обработку, даже если клиент не дождался ответа
+	ctx = context.WithoutCancel(ctx)
+
+	// Хранилище проверило только заявленный тип, поэтому сигнатуру видео сверяем сами, как у частей tus
+	head, err := s.storageClient.ReadFileHead(ctx, video.BucketID, video.GetStorageFilePath(constants.VideoQualityOriginal), storage.SniffLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	container, ok := storage.DetectVideoContainer(head)
+	if !ok {
+		if err := s.storageClient.DeleteFile(ctx, video.BucketID, video.GetStorageFilePath(constants.VideoQualityOriginal)); err != nil {
+			fmt.Printf("Failed to delete uploaded file %s: %v\n", upload.ID, err)
+		}
+		_ = s.discardUpload(ctx, upload, constants.UploadStatusTerminated)
+		return nil, constants.ErrUnsupportedMediaType
+	}
+
+	upload.Offset = object.Size
+	if err := s.uploadRepo.UpdateProgress(ctx, upload); err != nil {
+		return nil, err
+	}
+	if err := s.uploadRepo.UpdateStatus(ctx, upload.ID, constants.UploadStatusCompleted); err != nil {
+		return nil, fmt.Errorf("failed to complete upload: %w", err)
+	}
+	upload.Status = constants.UploadStatusCompleted
+	upload.VideoCode = video.VideoCode
+
+	video.OriginalSize = object.Size
+	video.OriginalContentType = container.ContentType
+	video.OriginalChecksum, _ = upload.Metadata["checksum"].(string)
+
+	if err := s.videoService.CompleteVideoUpload(ctx, video); err != nil {
+		return nil, err
+	}
+
+	return upload, nil
+}
+
+// createUpload создает видео в статусе uploading и запись о загрузке его исходника
+func (s *UploadService) createUpload(ctx context.Context, userID uuid.UUID, kind string, length int64, metadata map[string]string) (*entity.VideoUpload, error) {
+	if length <= 0 {
+		return nil, fmt.Errorf("%w: upload length must be positive", constants.ErrInvalidUpload)
+	}
+
+	categoryID, err := uuid.Parse(metadata["category_id"])
+	if err != nil {
+		return nil, fmt.Errorf("%w: category_id is required", constants.ErrInvalidUpload)
+	}
+
+	filename := strings.TrimSpace(metadata["filename"])
+	if filename == "" {
+		return nil, fmt.Errorf("%w: filename is required", constants.ErrInvalidUpload)
+	}
+
+	title := strings.TrimSpace(metadata["title"])
+	if title == "" {
+		title = strings.TrimSuffix(filename, filepath.Ext(filename))
+	}
+
+	video, err := s.videoService.CreatePendingVideo(ctx, userID, categoryID, constants.VideoStatusUploading, length, filename, title, metadata["description"])
+	if err != nil {
+		return nil, err
+	}
+
+	uploadMetadata := entity.Metadata{}
+	for key, value := range metadata {
+		uploadMetadata[key] = value
+	}
+
+	upload := &entity.VideoUpload{
+		UserID:    userID,
+		VideoID:   video.ID,
+		VideoCode: video.VideoCode,
+		Kind:      kind,
+		Length:    length,
+		Metadata:  uploadMetadata,
+		Status:    constants.UploadStatusUploading,
+		ExpiresAt: time.Now().Add(constants.UploadExpiration),
+	}
+
+	if err := s.uploadRepo.Create(ctx, upload); err != nil {
+		_ = s.videoService.DeleteVideo(ctx, video.ID)
+		return nil, err
+	}
+
+	return upload, nil
+}
+
+// GetUpload возвращает загрузку пользователя, чужие загрузки не отличаются от несуществующих
+func (s *UploadService) GetUpload(ctx context.Context, id, userID uuid.UUID) (*entity.VideoUpload, error) {
+	upload, err := s.uploadRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, constants.ErrUploadNotFound
+		}
+		return nil, err
+	}
+
+	if upload.UserID != userID {
+		return nil, constants.ErrUploadNotFound
+	}
+
+	switch {
+	case upload.Status == constants.UploadStatusExpired || upload.IsExpired():
+		return nil, constants.ErrUploadExpired
+	case upload.Status == constants.UploadStatusTerminated:
+		return nil, constants.ErrUploadNotFound
+	}
+
+	return upload, nil
+}
+
+// WriteChunk дописывает кусок загрузки. Полные части сразу уходят в составную загрузку хранилища,
+// остаток меньше части сохраняется хвостом и дополняется следующим куском.
+// После каждой части или хвоста прогресс сохраняется, поэтому при обрыве соединения принятые байты не теряются
+func (s *UploadService) WriteChunk(ctx context.Context, upload *entity.VideoUpload, offset int64, chunk io.Reader) (*entity.VideoUpload, error) {
+	unlock, err := s.uploadRepo.Lock(ctx, upload.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Под блокировкой перечитываем загрузку: смещение мог сдвинуть предыдущий запрос
+	upload, err = s.GetUpload(ctx, upload.ID, upload.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Kind != constants.UploadKindTus {
+		return nil, constants.ErrUploadNotFound
+	}
+	if upload.Status == constants.UploadStatusCompleted {
+		return nil, constants.ErrUploadCompleted
+	}
+	if upload.Offset != offset {
+		return nil, constants.ErrUploadOffsetMismatch
+	}
+
+	// Принятые данные сохраняем, даже если клиент оборвал соединение и контекст запроса отменен
+	ctx = context.WithoutCancel(ctx)
+
+	video, err := s.videoService.GetVideoByID(ctx, upload.VideoID)
+	if err != nil {
+		return nil, err
+	}
+
+	hasher, err := restoreHash(upload.HashState)
+	if err != nil {
+		return nil, err
+	}
+
+	var tail []byte
+	if upload.TailSize > 0 {
+		tail, err = s.storageClient.ReadFile(ctx, constants.UploadsBucket, upload.GetTailPath())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read upload tail: %w", err)
+		}
+		if int64(len(tail)) != upload.TailSize {
+			return nil, fmt.Errorf("upload tail has %d bytes, expected %d", len(tail), upload.TailSize)
+		}
+	}
+
+	body := io.TeeReader(io.LimitReader(chunk, upload.Length-upload.Offset), hasher)
+	bufPtr := partBufferPool.Get().(*[]byte)
+	defer partBufferPool.Put(bufPtr)
+	buf := *bufPtr
+
+	for {
+		hadTail := upload.TailSize > 0
+		size := copy(buf, tail)
+		tail = nil
+
+		read, readErr := io.ReadFull(body, buf[size:])
+		size += read
+		upload.Offset += int64(read)
+		final := upload.Offset == upload.Length
+
+		switch {
+		case size == len(buf) || (final && size > 0):
+			if err := s.uploadPart(ctx, upload, video, buf[:size]); err != nil {
+				if errors.Is(err, constants.ErrUnsupportedMediaType) {
+					_ = s.discardUpload(ctx, upload, constants.UploadStatusTerminated)
+				}
+				return nil, err
+			}
+		case read > 0:
+			if err := s.saveTail(ctx, upload, buf[:size]); err != nil {
+				return nil, err
+			}
+		}
+
+		if read > 0 {
+			if err := s.saveProgress(ctx, upload, hasher); err != nil {
+				return nil, err
+			}
+			// Хвост вошел в часть и больше не нужен
+			if hadTail && upload.TailSize == 0 {
+				_ = s.storageClient.DeleteFile(ctx, constants.UploadsBucket, upload.GetTailPath())
+			}
+		}
+
+		if final {
+			if err := s.completeUpload(ctx, upload, video, hasher); err != nil {
+				return nil, err
+			}
+			return upload, nil
+		}
+
+		if readErr != nil {
+			if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+				return upload, nil
+			}
+			return nil, fmt.Errorf("failed to read chunk: %w", readErr)
+		}
+	}
+}
+
+// TerminateUpload прерывает загрузку и удаляет видео
+func (s *UploadService) TerminateUpload(ctx context.Context, upload *entity.VideoUpload) error {
+	unlock, err := s.uploadRepo.Lock(ctx, upload.ID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return s.discardUpload(ctx, upload, constants.UploadStatusTerminated)
+}
+
+// ExpireUploads удаляет просроченные незавершенные загрузки. Загрузки, в которые сейчас пишут, пропускаются
+func (s *UploadService) ExpireUploads(ctx context.Context) (int, error) {
+	uploads, err := s.uploadRepo.GetExpired(ctx, expiredUploadsBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, upload := range uploads {
+		unlock, err := s.uploadRepo.Lock(ctx, upload.ID)
+		if err != nil {
+			continue
+		}
+
+		err = s.discardUpload(ctx, upload, constants.UploadStatusExpired)
+		unlock()
+		if err != nil {
+			fmt.Printf("Failed to expire upload %s: %v\n", upload.ID, err)
+			continue
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// StartCleanup запускает периодическое удаление просроченных загрузок
+func (s *UploadService) StartCleanup() {
+	s.ticker = time.NewTicker(constants.UploadCleanupInterval)
+
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				count, err := s.ExpireUploads(context.Background())
+				if err != nil {
+					fmt.Printf("Failed to expire uploads: %v\n", err)
+				} else if count > 0 {
+					fmt.Printf("Expired %d uploads\n", count)
+				}
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// StopCleanup останавливает удаление просроченных загрузок
+func (s *UploadService) StopCleanup() {
+	if s.ticker != nil {
+		s.ticker.Stop()
+	}
+	close(s.stopChan)
+}
+
+// uploadPart загружает часть исходника. Первая часть начинает составную загрузку,
+// контейнер исходника определяется по ее первым байтам, файл без сигнатуры видео отклоняется
+func (s *UploadService) uploadPart(ctx context.Context, upload *entity.VideoUpload, video *entity.Video, data []byte) error {
+	objectName := video.GetStorageFilePath(constants.VideoQualityOriginal)
+
+	if upload.StorageUploadID == "" {
+		container, ok := storage.DetectVideoContainer(data[:min(len(data), storage.SniffLength)])
+		if !ok {
+			return constants.ErrUnsupportedMediaType
+		}
+
+		uploadID, err := s.storageClient.NewMultipartUpload(ctx, video.BucketID, objectName, storage.UploadOptions{
+			Size:        upload.Length,
+			ContentType: container.ContentType,
+		})
+		if err != nil {
+			return err
+		}
+		upload.StorageUploadID = uploadID
+	}
+
+	part, err := s.storageClient.UploadPart(ctx, video.BucketID, objectName, upload.StorageUploadID, len(upload.Parts)+1, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+
+	upload.Parts = append(upload.Parts, entity.UploadPart{
+		Number: part.Number,
+		ETag:   part.ETag,
+		Size:   part.Size,
+	})
+	upload.TailSize = 0
+
+	return nil
+}
+
+// saveTail сохраняет принятые байты, которых пока не хватает на часть
+func (s *UploadService) saveTail(ctx context.Context, upload *entity.VideoUpload, data []byte) error {
+	_, err := s.storageClient.UploadFile(ctx, constants.UploadsBucket, upload.GetTailPath(), bytes.NewReader(data), storage.UploadOptions{
+		Size:        int64(len(data)),
+		ContentType: "application/octet-stream",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save upload tail: %w", err)
+	}
+
+	upload.TailSize = int64(len(data))
+	return nil
+}
+
+// saveProgress сохраняет смещение вместе с состоянием SHA-256 и продлевает срок загрузки
+func (s *UploadService) saveProgress(ctx context.Context, upload *entity.VideoUpload, hasher hash.Hash) error {
+	state, err := hasher.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to save checksum state: %w", err)
+	}
+
+	upload.HashState = state
+	upload.ExpiresAt = time.Now().Add(constants.UploadExpiration)
+
+	return s.uploadRepo.UpdateProgress(ctx, upload)
+}
+
+// completeUpload собирает исходник из частей и передает видео на обработку
+func (s *UploadService) completeUpload(ctx context.Context, upload *entity.VideoUpload, video *entity.Video, hasher hash.Hash) error {
+	objectName := video.GetStorageFilePath(constants.VideoQualityOriginal)
+
+	parts := make([]storage.Part, 0, len(upload.Parts))
+	for _, part := range upload.Parts {
+		parts = append(parts, storage.Part{Number: part.Number, ETag: part.ETag, Size: part.Size})
+	}
+
+	object, err := s.storageClient.CompleteMultipartUpload(ctx, video.BucketID, objectName, upload.StorageUploadID, parts)
+	if err != nil {
+		// Сборка могла пройти в прерванном запросе, тогда объект уже на месте
+		stat, statErr := s.storageClient.StatFile(ctx, video.BucketID, objectName)
+		if statErr != nil || stat.Size != upload.Length {
+			return err
+		}
+		object = stat
+	}
+
+	if err := s.uploadRepo.UpdateStatus(ctx, upload.ID, constants.UploadStatusCompleted); err != nil {
+		return fmt.Errorf("failed to complete upload: %w", err)
+	}
+	upload.Status = constants.UploadStatusCompleted
+
+	video.OriginalSize = object.Size
+	video.OriginalContentType = object.ContentType
+	video.OriginalChecksum = hex.EncodeToString(hasher.Sum(nil))
+
+	return s.videoService.CompleteVideoUpload(ctx, video)
+}
+
+// discardUpload отменяет составную загрузку, удаляет хвост и видео
+func (s *UploadService) discardUpload(ctx context.Context, upload *entity.VideoUpload, status string) error {
+	if err := s.uploadRepo.UpdateStatus(ctx, upload.ID, status); err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return constants.ErrUploadCompleted
+		}
+		return err
+	}
+
+	video, err := s.videoService.GetVideoByID(ctx, upload.VideoID)
+	if err != nil {
+		return err
+	}
+
+	if upload.StorageUploadID != "" {
+		objectName := video.GetStorageFilePath(constants.VideoQualityOriginal)
+		if err := s.storageClient.AbortMultipartUpload(ctx, video.BucketID, objectName, upload.StorageUploadID); err != nil {
+			fmt.Printf("Failed to abort multipart upload %s: %v\n", upload.ID, err)
+		}
+	}
+
+	if upload.TailSize > 0 {
+		if err := s.storageClient.DeleteFile(ctx, constants.UploadsBucket, upload.GetTailPath()); err != nil {
+			fmt.Printf("Failed to delete upload tail %s: %v\n", upload.ID, err)
+		}
+	}
+
+	return s.videoService.DeleteVideo(ctx, video.ID)
+}
+
+// restoreHash восстанавливает SHA-256 принятых ранее байт
+func restoreHash(state []byte) (hash.Hash, error) {
+	hasher := sha256.New()
+	if len(state) == 0 {
+		return hasher, nil
+	}
+
+	if err := hasher.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		return nil, fmt.Errorf("failed to restore checksum state: %w", err)
+	}
+
+	return hasher, nil
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"bufio"
 	"context"
 	"crypto/rand"
 	"encoding/base64"
@@ -10,8 +11,10 @@ import (
 	"github.com/mrkbwp/gotube/internal/dto"
 	"github.com/mrkbwp/gotube/pkg/constants"
 	"github.com/mrkbwp/gotube/pkg/kafka"
+	"html"
 	"io"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -25,117 +28,165 @@ import (
 	"github.com/mrkbwp/gotube/internal/infrastructure/storage"
 )
 
+// dashSegmentAttr находит ссылки на init и media сегменты в SegmentList манифеста
+var dashSegmentAttr = regexp.MustCompile(`(sourceURL|media)="([^"]+)"`)
+
 // VideoService реализует интерфейс VideoService
 type VideoService struct {
 	videoRepo     repositories.VideoRepository
-	storageClient *storage.MinioClient
+	userRepo      repositories.UserRepository
+	storageClient storage.ObjectStorage
 	kafkaProducer *kafka.Producer
 	redisClient   *redis.Client
+	quotaService  services.QuotaService
+	shareService  services.ShareService
 	shardCount    int
+
+	publishTicker *time.Ticker
+	stopChan      chan struct{}
 }
 
 // NewVideoService создает новый экземпляр VideoService
 func NewVideoService(
 	videoRepo repositories.VideoRepository,
-	storageClient *storage.MinioClient,
+	userRepo repositories.UserRepository,
+	storageClient storage.ObjectStorage,
 	kafkaProducer *kafka.Producer,
 	redisClient *redis.Client,
+	quotaService services.QuotaService,
+	shareService services.ShareService,
 	shardCount int,
 ) services.VideoService {
 	return &VideoService{
 		videoRepo:     videoRepo,
+		userRepo:      userRepo,
 		storageClient: storageClient,
 		kafkaProducer: kafkaProducer,
 		redisClient:   redisClient,
+		quotaService:  quotaService,
+		shareService:  shareService,
 		shardCount:    shardCount,
+		stopChan:      make(chan struct{}),
 	}
 }
 
-// UploadVideo загружает новое видео
+// UploadVideo загружает новое видео. Файл без сигнатуры видео и файл сверх квот не попадают в хранилище
 func (s *VideoService) UploadVideo(
 	ctx context.Context,
-	userID, categoryID uuid.UUID,
+	userID uuid.UUID,
 	file io.Reader,
-	originalFilename, title, description string,
+	size int64,
+	originalFilename string,
+	details dto.VideoDetails,
 ) (*entity.Video, error) {
-	// Генерируем уникальный код для видео
-	var videoCode string
-	// Проверка уникальности генерации кода
-	for {
-		videoCodeGen, err := s.generateUniquePublicID()
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate video code: %w", err)
-		}
-		_, err = s.videoRepo.GetByCode(ctx, videoCodeGen)
-		if err != nil {
-			if errors.Is(err, constants.ErrNotFound) {
-				videoCode = videoCodeGen
-				break
-			}
-			return nil, fmt.Errorf("failed to generate video code: %w", err)
-		}
+	source, container, err := sniffSource(file)
+	if err != nil {
+		return nil, err
 	}
 
-	filename := generateFilename(originalFilename)
-
-	// Генерируем пути для хранения
-	bucketID, shardID, segment1, segment2 := s.generateStoragePath(filename)
-
-	var video *entity.Video
-	// Создаем запись о видео
-	video = &entity.Video{
-		ID:          uuid.New(),
-		VideoCode:   videoCode,
-		UserID:      userID,
-		Title:       title,
-		Description: description,
-		CategoryID:  categoryID,
-
-		BucketID:         bucketID,
-		ShardID:          shardID,
-		PathSegment1:     segment1,
-		PathSegment2:     segment2,
-		Filename:         filename,
-		OriginalFilename: originalFilename,
-
-		Status:    string(constants.VideoStatusUploaded),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	if err := s.quotaService.CheckUpload(ctx, userID, size); err != nil {
+		return nil, err
 	}
 
-	storagePath := video.GetStoragePath(constants.VideoQualityOriginal)
+	video, err := s.newVideo(ctx, userID, originalFilename, container.Extension, details)
+	if err != nil {
+		return nil, err
+	}
 
-	// Сохраняем файл в хранилище
-	objectName := filepath.Join(storagePath, filename)
-	if err := s.storageClient.UploadFile(ctx, bucketID, objectName, file); err != nil {
-		return nil, fmt.Errorf("failed to upload file: %w", err)
+	if err := s.storeSource(ctx, video, source, container, size); err != nil {
+		return nil, err
 	}
 
 	// Сохраняем метаданные в БД
 	if err := s.videoRepo.Create(ctx, video); err != nil {
 		// В случае ошибки удаляем загруженный файл
-		_ = s.storageClient.DeleteFile(ctx, bucketID, objectName)
+		_ = s.storageClient.DeleteFile(ctx, video.BucketID, video.GetStorageFilePath(constants.VideoQualityOriginal))
 		return nil, fmt.Errorf("failed to create video record: %w", err)
 	}
 
-	// Отправляем сообщение в Kafka для обработки
-	message := kafka.VideoProcessingMessage{
-		VideoID:      video.ID,
-		BucketID:     bucketID,
-		ShardID:      shardID,
-		PathSegment1: segment1,
-		PathSegment2: segment2,
-		Filename:     filename,
+	s.sendProcessingMessage(ctx, video)
+
+	return video, nil
+}
+
+// UploadVideoSource загружает исходник видео, созданного CreatePendingVideo, с теми же проверками, что и UploadVideo
+func (s *VideoService) UploadVideoSource(ctx context.Context, video *entity.Video, file io.Reader, size int64) error {
+	source, container, err := sniffSource(file)
+	if err != nil {
+		return err
 	}
 
-	if err := s.kafkaProducer.SendVideoProcessingMessage(ctx, message); err != nil {
-		// Логируем ошибку, но не отменяем загрузку
-		fmt.Printf("Failed to send processing message: %v\n", err)
+	// Видео создано без размера исходника: известный размер проверяется по квотам до загрузки, неизвестный - после
+	if size > 0 {
+		if err := s.quotaService.CheckUploadSize(ctx, video.UserID, size); err != nil {
+			return err
+		}
+	}
+
+	if err := s.storeSource(ctx, video, source, container, size); err != nil {
+		return err
+	}
+
+	if size <= 0 {
+		if err := s.quotaService.CheckUploadSize(ctx, video.UserID, video.OriginalSize); err != nil {
+			_ = s.storageClient.DeleteFile(ctx, video.BucketID, video.GetStorageFilePath(constants.VideoQualityOriginal))
+			return err
+		}
+	}
+
+	if err := s.CompleteVideoUpload(ctx, video); err != nil {
+		_ = s.storageClient.DeleteFile(ctx, video.BucketID, video.GetStorageFilePath(constants.VideoQualityOriginal))
+		return err
+	}
+
+	return nil
+}
+
+// CreatePendingVideo создает видео в статусе status, исходник которого загружается отдельно.
+// Контейнер до загрузки неизвестен, поэтому файл исходника хранится без расширения
+func (s *VideoService) CreatePendingVideo(
+	ctx context.Context,
+	userID, categoryID uuid.UUID,
+	status constants.VideoStatus,
+	size int64,
+	originalFilename, title, description string,
+) (*entity.Video, error) {
+	if err := s.quotaService.CheckUpload(ctx, userID, size); err != nil {
+		return nil, err
+	}
+
+	video, err := s.newVideo(ctx, userID, originalFilename, "", dto.VideoDetails{
+		CategoryID:  categoryID,
+		Title:       title,
+		Description: description,
+	})
+	if err != nil {
+		return nil, err
+	}
+	video.Status = string(status)
+
+	if err := s.videoRepo.Create(ctx, video); err != nil {
+		return nil, fmt.Errorf("failed to create video record: %w", err)
 	}
 
 	return video, nil
 }
 
+// CompleteVideoUpload сохраняет сведения о загруженном исходнике и отправляет видео на обработку
+func (s *VideoService) CompleteVideoUpload(ctx context.Context, video *entity.Video) error {
+	if err := s.videoRepo.CompleteUpload(ctx, video); err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return constants.ErrVideoNotFound
+		}
+		return fmt.Errorf("failed to complete video upload: %w", err)
+	}
+	video.Status = string(constants.VideoStatusUploaded)
+
+	s.sendProcessingMessage(ctx, video)
+
+	return nil
+}
+
 // GetVideoByID возвращает информацию о видео по ID
 func (s *VideoService) GetVideoByID(ctx context.Context, id uuid.UUID) (*entity.Video, error) {
 	// Если нет в кеше, получаем из БД
@@ -147,11 +198,6 @@ func (s *VideoService) GetVideoByID(ctx context.Context, id uuid.UUID) (*entity.
 		return nil, fmt.Errorf("failed to get video: %w", err)
 	}
 
-	// Проверяем статус и доступность
-	//if err := s.validateVideoAccess(video); err != nil {
-	//	return nil, err
-	//}
-
 	return video, nil
 }
 
@@ -171,6 +217,10 @@ func (s *VideoService) GetVideoUserInfoByCode(ctx context.Context, code string,
 		return nil, fmt.Errorf("failed to get video: %w", err)
 	}
 
+	if err := s.CheckVideoAccess(ctx, video, dto.VideoViewer{UserID: &userID}); err != nil {
+		return nil, err
+	}
+
 	reaction, _ := s.videoRepo.GetUserReaction(ctx, video.ID, userID)
 	resp := &dto.VideoUserResponse{
 		Video:    *video,
@@ -181,7 +231,12 @@ func (s *VideoService) GetVideoUserInfoByCode(ctx context.Context, code string,
 	return resp, nil
 }
 
-func (s *VideoService) GetVideoFiles(ctx context.Context, video *entity.Video) ([]*entity.VideoFile, error) {
+func (s *VideoService) GetVideoFiles(ctx context.Context, video *entity.Video, viewer dto.VideoViewer) ([]*entity.VideoFile, error) {
+	// Ссылки на файлы подписываются только для тех, кому видео доступно, просмотр по ссылке доступа списывается здесь
+	if err := s.checkVideoAccess(ctx, video, viewer, true); err != nil {
+		return nil, err
+	}
+
 	files, err := s.videoRepo.GetVideoFiles(ctx, video.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get video files: %w", err)
@@ -189,10 +244,20 @@ func (s *VideoService) GetVideoFiles(ctx context.Context, video *entity.Video) (
 
 	// Для каждого файла генерируем временную ссылку
 	for _, file := range files {
+		// Манифесты стриминга отдаются через API, ссылку формирует обработчик
+		if file.Format == constants.StreamingFormatDASH {
+			continue
+		}
+
+		storagePath := video.GetStorageFilePath(file.QualityName)
+		if file.StoragePath != nil {
+			storagePath = *file.StoragePath
+		}
+
 		url, err := s.storageClient.GetFileURL(
 			ctx,
 			video.BucketID,
-			video.GetStorageFilePath(file.QualityName),
+			storagePath,
 			int(time.Minute*10),
 		)
 		if err != nil {
@@ -217,6 +282,126 @@ func (s *VideoService) GetVideoFiles(ctx context.Context, video *entity.Video) (
 	return files, nil
 }
 
+// GetHLSMasterPlaylist возвращает мастер-плейлист HLS.
+// Плейлисты качеств в нем относительные и отдаются через API
+func (s *VideoService) GetHLSMasterPlaylist(ctx context.Context, video *entity.Video) ([]byte, error) {
+	playlist, err := s.storageClient.ReadFile(ctx, video.BucketID, video.GetHLSPath()+"/"+constants.HLSMasterPlaylist)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, constants.ErrStreamNotFound
+		}
+		return nil, fmt.Errorf("failed to get hls master playlist: %w", err)
+	}
+
+	return playlist, nil
+}
+
+// GetHLSVariantPlaylist возвращает плейлист качества, заменяя сегменты временными ссылками
+func (s *VideoService) GetHLSVariantPlaylist(ctx context.Context, video *entity.Video, quality string) ([]byte, error) {
+	// Название качества попадает в путь объекта, поэтому принимаем только известные качества
+	qualities, err := s.videoRepo.GetVideoQualities(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get qualities: %w", err)
+	}
+
+	known := false
+	for _, q := range qualities {
+		if q.Name == quality {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return nil, constants.ErrStreamNotFound
+	}
+
+	qualityPath := video.GetHLSPath() + "/" + quality
+	playlist, err := s.storageClient.ReadFile(ctx, video.BucketID, qualityPath+"/"+constants.HLSVariantPlaylist)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, constants.ErrStreamNotFound
+		}
+		return nil, fmt.Errorf("failed to get hls playlist: %w", err)
+	}
+
+	segmentURL := func(segment string) (string, error) {
+		url, err := s.storageClient.GetFileURL(
+			ctx,
+			video.BucketID,
+			qualityPath+"/"+segment,
+			int(time.Hour*6),
+		)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate segment url: %w", err)
+		}
+		return url, nil
+	}
+
+	lines := strings.Split(string(playlist), "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		// Из тегов ссылку содержит только EXT-X-MAP - инициализирующий сегмент fMP4
+		if strings.HasPrefix(line, "#") {
+			segment, ok := hlsMapURI(line)
+			if !ok {
+				continue
+			}
+			url, err := segmentURL(segment)
+			if err != nil {
+				return nil, err
+			}
+			lines[i] = strings.Replace(line, `URI="`+segment+`"`, `URI="`+url+`"`, 1)
+			continue
+		}
+
+		url, err := segmentURL(line)
+		if err != nil {
+			return nil, err
+		}
+		lines[i] = url
+	}
+
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// GetDASHManifest возвращает DASH манифест, заменяя сегменты временными ссылками
+func (s *VideoService) GetDASHManifest(ctx context.Context, video *entity.Video) ([]byte, error) {
+	dashPath := video.GetDASHPath()
+	manifest, err := s.storageClient.ReadFile(ctx, video.BucketID, dashPath+"/"+constants.DASHManifest)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, constants.ErrStreamNotFound
+		}
+		return nil, fmt.Errorf("failed to get dash manifest: %w", err)
+	}
+
+	var urlErr error
+	manifest = dashSegmentAttr.ReplaceAllFunc(manifest, func(match []byte) []byte {
+		parts := dashSegmentAttr.FindSubmatch(match)
+		url, err := s.storageClient.GetFileURL(
+			ctx,
+			video.BucketID,
+			dashPath+"/"+string(parts[2]),
+			int(time.Hour*6),
+		)
+		if err != nil {
+			urlErr = err
+			return match
+		}
+
+		return []byte(fmt.Sprintf(`%s="%s"`, parts[1], html.EscapeString(url)))
+	})
+	if urlErr != nil {
+		return nil, fmt.Errorf("failed to generate segment url: %w", urlErr)
+	}
+
+	return manifest, nil
+}
+
 // GetNewVideos возвращает список новых видео с пагинацией
 func (s *VideoService) GetNewVideos(ctx context.Context, page, limit int) ([]*entity.Video, int64, error) {
 	if err := s.validatePagination(page, limit); err != nil {
@@ -246,12 +431,15 @@ func (s *VideoService) GetPopularVideos(ctx context.Context, page, limit int) ([
 }
 
 // GetUserVideos возвращает видео пользователя с пагинацией
-func (s *VideoService) GetUserVideos(ctx context.Context, userID uuid.UUID, page, limit int) ([]*entity.Video, int64, error) {
+func (s *VideoService) GetUserVideos(ctx context.Context, userID uuid.UUID, viewerID *uuid.UUID, page, limit int) ([]*entity.Video, int64, error) {
 	if err := s.validatePagination(page, limit); err != nil {
 		return nil, 0, err
 	}
 
-	videos, total, err := s.videoRepo.GetUserVideos(ctx, userID, page, limit)
+	// Владелец видит все свои видео, остальные - только публичные
+	listedOnly := viewerID == nil || *viewerID != userID
+
+	videos, total, err := s.videoRepo.GetUserVideos(ctx, userID, listedOnly, page, limit)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get user videos: %w", err)
 	}
@@ -260,7 +448,12 @@ func (s *VideoService) GetUserVideos(ctx context.Context, userID uuid.UUID, page
 }
 
 // UpdateVideo обновляет информацию о видео
-func (s *VideoService) UpdateVideo(ctx context.Context, id, categoryID uuid.UUID, title, description string) (*entity.Video, error) {
+func (s *VideoService) UpdateVideo(
+	ctx context.Context,
+	id, categoryID uuid.UUID,
+	title, description, visibility string,
+	publishAt *time.Time,
+) (*entity.Video, error) {
 	video, err := s.videoRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, constants.ErrNotFound) {
@@ -273,6 +466,14 @@ func (s *VideoService) UpdateVideo(ctx context.Context, id, categoryID uuid.UUID
 	video.Description = description
 	video.CategoryID = categoryID
 
+	// Без видимости сохраняется текущая
+	if visibility != "" {
+		video.Visibility, video.PublishAt, err = resolveVisibility(visibility, publishAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.videoRepo.Update(ctx, video); err != nil {
 		return nil, fmt.Errorf("failed to update video: %w", err)
 	}
@@ -419,9 +620,295 @@ func (s *VideoService) ViewVideo(ctx context.Context, videoID uuid.UUID, viewerI
 	return nil
 }
 
+// GetProcessingStatus собирает прогресс обработки: готовые качества из БД, текущие из Redis
+func (s *VideoService) GetProcessingStatus(ctx context.Context, video *entity.Video) (*dto.ProcessingResponse, error) {
+	qualities, err := s.videoRepo.GetVideoQualities(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get qualities: %w", err)
+	}
+
+	files, err := s.videoRepo.GetVideoFiles(ctx, video.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video files: %w", err)
+	}
+
+	// Качества выше исходника не конвертируются и в прогрессе не показываются
+	sourceWidth, sourceHeight := video.SourceSize()
+	qualities = entity.SelectRenditions(qualities, sourceWidth, sourceHeight)
+
+	ready := make(map[string]bool, len(files))
+	for _, file := range files {
+		if file.Format != constants.StreamingFormatDASH {
+			ready[file.QualityName] = true
+		}
+	}
+
+	progress := map[string]string{}
+	if s.redisClient != nil {
+		progress, err = s.redisClient.HGetAll(ctx, fmt.Sprintf(constants.VideoProcessingKey, video.ID)).Result()
+		if err != nil {
+			// Без Redis отдаем хотя бы готовые качества
+			fmt.Printf("Failed to get processing progress: %v\n", err)
+		}
+	}
+
+	resp := &dto.ProcessingResponse{
+		VideoCode:    video.VideoCode,
+		Status:       video.Status,
+		ErrorMessage: video.ErrorMessage,
+		Finished:     video.Status == string(constants.VideoStatusError),
+		Qualities:    make([]dto.QualityProgress, 0, len(qualities)),
+	}
+
+	done := 0
+	total := 0
+	for _, quality := range qualities {
+		item := dto.QualityProgress{
+			Quality: quality.Name,
+			Status:  constants.QualityStatusPending,
+		}
+
+		value, inProgress := progress[quality.Name]
+		switch {
+		case ready[quality.Name]:
+			item.Status = constants.QualityStatusReady
+			item.Progress = 100
+		case value == constants.QualityStatusFailed || video.Status == string(constants.VideoStatusError):
+			item.Status = constants.QualityStatusFailed
+		case inProgress:
+			item.Status = constants.QualityStatusProcessing
+			item.Progress, _ = strconv.Atoi(value)
+		}
+
+		if item.Status == constants.QualityStatusReady || item.Status == constants.QualityStatusFailed {
+			done++
+		}
+		total += item.Progress
+		resp.Qualities = append(resp.Qualities, item)
+	}
+
+	if len(qualities) > 0 {
+		resp.Progress = total / len(qualities)
+		if done == len(qualities) {
+			resp.Finished = true
+		}
+	}
+
+	return resp, nil
+}
+
+// WatchProcessing отправляет прогресс при каждом уведомлении из Redis и не реже
+// ProcessingStreamPollInterval, пока обработка не завершится
+func (s *VideoService) WatchProcessing(ctx context.Context, video *entity.Video) (<-chan *dto.ProcessingResponse, error) {
+	if s.redisClient == nil {
+		return nil, errors.New("redis client not initialized")
+	}
+
+	pubsub := s.redisClient.Subscribe(ctx, fmt.Sprintf(constants.VideoProcessingChannel, video.ID))
+	// Дожидаемся подписки, чтобы не пропустить уведомления между снимком и подпиской
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to processing updates: %w", err)
+	}
+
+	updates := make(chan *dto.ProcessingResponse)
+	go func() {
+		defer close(updates)
+		defer pubsub.Close()
+
+		ticker := time.NewTicker(constants.ProcessingStreamPollInterval)
+		defer ticker.Stop()
+
+		messages := pubsub.Channel()
+		for {
+			current, err := s.videoRepo.GetByID(ctx, video.ID)
+			if err != nil {
+				return
+			}
+
+			status, err := s.GetProcessingStatus(ctx, current)
+			if err != nil {
+				return
+			}
+
+			select {
+			case updates <- status:
+			case <-ctx.Done():
+				return
+			}
+
+			if status.Finished {
+				return
+			}
+
+			select {
+			case <-messages:
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return updates, nil
+}
+
+// PublishScheduledVideos публикует запланированные видео, время публикации которых наступило
+func (s *VideoService) PublishScheduledVideos(ctx context.Context) (int64, error) {
+	count, err := s.videoRepo.PublishScheduled(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to publish scheduled videos: %w", err)
+	}
+
+	return count, nil
+}
+
+// StartPublishScheduler запускает периодическую публикацию запланированных видео
+func (s *VideoService) StartPublishScheduler() {
+	s.publishTicker = time.NewTicker(constants.VideoPublishCheckInterval)
+
+	go func() {
+		for {
+			select {
+			case <-s.publishTicker.C:
+				count, err := s.PublishScheduledVideos(context.Background())
+				if err != nil {
+					fmt.Printf("Failed to publish scheduled videos: %v\n", err)
+				} else if count > 0 {
+					fmt.Printf("Published %d scheduled videos\n", count)
+				}
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// StopPublishScheduler останавливает публикацию запланированных видео
+func (s *VideoService) StopPublishScheduler() {
+	if s.publishTicker != nil {
+		s.publishTicker.Stop()
+	}
+	close(s.stopChan)
+}
+
 // Вспомогательные методы
 
-func (s *VideoService) validateVideoAccess(video *entity.Video) error {
+// newVideo подготавливает запись о видео с уникальным кодом и путем в хранилище, не сохраняя ее
+func (s *VideoService) newVideo(
+	ctx context.Context,
+	userID uuid.UUID,
+	originalFilename, extension string,
+	details dto.VideoDetails,
+) (*entity.Video, error) {
+	visibility, publishAt, err := resolveVisibility(details.Visibility, details.PublishAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Генерируем уникальный код для видео
+	var videoCode string
+	// Проверка уникальности генерации кода
+	for {
+		videoCodeGen, err := s.generateUniquePublicID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate video code: %w", err)
+		}
+		_, err = s.videoRepo.GetByCode(ctx, videoCodeGen)
+		if err != nil {
+			if errors.Is(err, constants.ErrNotFound) {
+				videoCode = videoCodeGen
+				break
+			}
+			return nil, fmt.Errorf("failed to generate video code: %w", err)
+		}
+	}
+
+	filename := generateFilename(extension)
+
+	// Генерируем пути для хранения
+	bucketID, shardID, segment1, segment2 := s.generateStoragePath(filename)
+
+	return &entity.Video{
+		ID:          uuid.New(),
+		VideoCode:   videoCode,
+		UserID:      userID,
+		Title:       details.Title,
+		Description: details.Description,
+		CategoryID:  details.CategoryID,
+		Tags:        normalizeTags(details.Tags),
+		Visibility:  visibility,
+		PublishAt:   publishAt,
+
+		BucketID:         bucketID,
+		ShardID:          shardID,
+		PathSegment1:     segment1,
+		PathSegment2:     segment2,
+		Filename:         filename,
+		OriginalFilename: originalFilename,
+
+		Status:    string(constants.VideoStatusUploaded),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+// sendProcessingMessage отправляет видео на обработку через Kafka
+func (s *VideoService) sendProcessingMessage(ctx context.Context, video *entity.Video) {
+	message := kafka.VideoProcessingMessage{
+		VideoID:      video.ID,
+		BucketID:     video.BucketID,
+		ShardID:      video.ShardID,
+		PathSegment1: video.PathSegment1,
+		PathSegment2: video.PathSegment2,
+		Filename:     video.Filename,
+	}
+
+	if err := s.kafkaProducer.SendVideoProcessingMessage(ctx, message); err != nil {
+		// Логируем ошибку, но не отменяем загрузку
+		fmt.Printf("Failed to send processing message: %v\n", err)
+	}
+}
+
+// CheckVideoAccess проверяет, что зритель может смотреть видео, не списывая просмотр ссылки доступа
+func (s *VideoService) CheckVideoAccess(ctx context.Context, video *entity.Video, viewer dto.VideoViewer) error {
+	return s.checkVideoAccess(ctx, video, viewer, false)
+}
+
+// CheckVideoPlayback проверяет, что зритель может смотреть видео, и списывает просмотр ссылки доступа
+func (s *VideoService) CheckVideoPlayback(ctx context.Context, video *entity.Video, viewer dto.VideoViewer) error {
+	return s.checkVideoAccess(ctx, video, viewer, true)
+}
+
+// checkVideoAccess проверяет доступ зрителя. Владельцу и администраторам доступны любые видео,
+// остальным - незаблокированные готовые видео с видимостью public или unlisted. Готовые неопубликованные
+// видео открываются еще по выданному доступу или ссылке
+func (s *VideoService) checkVideoAccess(ctx context.Context, video *entity.Video, viewer dto.VideoViewer, countView bool) error {
+	err := validateVideoAccess(video)
+	if err == nil {
+		return nil
+	}
+
+	if viewer.UserID != nil {
+		canManage, manageErr := s.canManageVideo(ctx, video, *viewer.UserID)
+		if manageErr != nil {
+			return manageErr
+		}
+		if canManage {
+			return nil
+		}
+	}
+
+	// Пока видео не готово, ссылку не проверяем, чтобы не списать просмотр
+	if errors.Is(err, constants.ErrVideoPrivate) && validateVideoStatus(video) == nil {
+		return s.shareService.CheckAccess(ctx, video, viewer, countView)
+	}
+
+	return err
+}
+
+// validateVideoAccess проверяет, что видео доступно любому зрителю
+func validateVideoAccess(video *entity.Video) error {
 	if video.IsBlocked {
 		return constants.ErrVideoBlocked
 	}
@@ -430,6 +917,11 @@ func (s *VideoService) validateVideoAccess(video *entity.Video) error {
 		return constants.ErrVideoPrivate
 	}
 
+	return validateVideoStatus(video)
+}
+
+// validateVideoStatus проверяет, что видео готово к просмотру
+func validateVideoStatus(video *entity.Video) error {
 	if video.Status != string(constants.VideoStatusReady) {
 		if video.Status == string(constants.VideoStatusUploaded) || video.Status == string(constants.VideoStatusProcessing) {
 			return constants.ErrVideoProcessing
@@ -440,6 +932,41 @@ func (s *VideoService) validateVideoAccess(video *entity.Video) error {
 	return nil
 }
 
+// canManageVideo сообщает, что пользователь - владелец видео или администратор
+func (s *VideoService) canManageVideo(ctx context.Context, video *entity.Video, userID uuid.UUID) (bool, error) {
+	if video.UserID == userID {
+		return true, nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID.String())
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user.Role == constants.RoleAdmin, nil
+}
+
+// resolveVisibility проверяет видимость: пустая означает public, время публикации хранится только у scheduled.
+// Запланированное на прошедшее время видео опубликует ближайший запуск планировщика
+func resolveVisibility(visibility string, publishAt *time.Time) (string, *time.Time, error) {
+	switch visibility {
+	case "":
+		return constants.VideoVisibilityPublic, nil, nil
+	case constants.VideoVisibilityPublic, constants.VideoVisibilityUnlisted, constants.VideoVisibilityPrivate:
+		return visibility, nil, nil
+	case constants.VideoVisibilityScheduled:
+		if publishAt == nil {
+			return "", nil, fmt.Errorf("%w: publish_at is required for scheduled videos", constants.ErrInvalidVisibility)
+		}
+		return visibility, publishAt, nil
+	}
+
+	return "", nil, fmt.Errorf("%w: %s", constants.ErrInvalidVisibility, visibility)
+}
+
 func (s *VideoService) validatePagination(page, limit int) error {
 	if page < 1 {
 		return constants.ErrInvalidPagination
@@ -474,12 +1001,96 @@ func (s *VideoService) generateUniquePublicID() (string, error) {
 	return id, nil
 }
 
-func generateFilename(originalFilename string) string {
-	ext := filepath.Ext(originalFilename)
+// sniffSource определяет контейнер исходника по первым байтам, расширению из имени файла не доверяем
+func sniffSource(file io.Reader) (*bufio.Reader, *storage.VideoContainer, error) {
+	buffered := bufio.NewReaderSize(file, storage.SniffLength)
+	head, err := buffered.Peek(storage.SniffLength)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	container, ok := storage.DetectVideoContainer(head)
+	if !ok {
+		return nil, nil, constants.ErrUnsupportedMediaType
+	}
+
+	return buffered, container, nil
+}
+
+// storeSource сохраняет исходник в хранилище и заполняет его размер, тип и контрольную сумму
+func (s *VideoService) storeSource(ctx context.Context, video *entity.Video, source io.Reader, container *storage.VideoContainer, size int64) error {
+	maxFileSize := s.quotaService.MaxFileSize()
+	if size > maxFileSize {
+		return fmt.Errorf("%w: %d bytes, limit is %d bytes", constants.ErrFileTooLarge, size, maxFileSize)
+	}
+
+	// Размер неизвестен - читаем на байт больше лимита, чтобы заметить превышение
+	if size <= 0 {
+		source = io.LimitReader(source, maxFileSize+1)
+	}
+
+	objectName := video.GetStorageFilePath(constants.VideoQualityOriginal)
+	object, err := s.storageClient.UploadFile(ctx, video.BucketID, objectName, source, storage.UploadOptions{
+		Size:        size,
+		ContentType: container.ContentType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+	if object.Size > maxFileSize {
+		_ = s.storageClient.DeleteFile(ctx, video.BucketID, objectName)
+		return fmt.Errorf("%w: limit is %d bytes", constants.ErrFileTooLarge, maxFileSize)
+	}
+
+	video.OriginalSize = object.Size
+	video.OriginalContentType = object.ContentType
+	video.OriginalChecksum = object.Checksum
+
+	return nil
+}
+
+// hlsMapURI возвращает путь инициализирующего сегмента из тега EXT-X-MAP
+func hlsMapURI(line string) (string, bool) {
+	attributes, ok := strings.CutPrefix(line, "#EXT-X-MAP:")
+	if !ok {
+		return "", false
+	}
+
+	_, uri, ok := strings.Cut(attributes, `URI="`)
+	if !ok {
+		return "", false
+	}
+	uri, _, ok = strings.Cut(uri, `"`)
+	if !ok {
+		return "", false
+	}
+
+	return uri, true
+}
+
+// normalizeTags приводит теги к нижнему регистру и убирает пустые и повторяющиеся
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
+
+// generateFilename генерирует имя файла исходника, extension - расширение распознанного контейнера с точкой
+func generateFilename(extension string) string {
 	timestamp := time.Now().UnixNano()
 	randomStr := make([]byte, 8)
 	rand.Read(randomStr)
-	return fmt.Sprintf("%d_%x%s", timestamp, randomStr, ext)
+	return fmt.Sprintf("%d_%x%s", timestamp, randomStr, extension)
 }
 
 func (s *VideoService) generateStoragePath(filename string) (string, string, string, string) {
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mrkbwp/gotube/internal/domain/entity"
+	"github.com/mrkbwp/gotube/pkg/constants"
+)
+
+func TestResolveVisibility(t *testing.T) {
+	publishAt := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name           string
+		visibility     string
+		publishAt      *time.Time
+		wantVisibility string
+		wantPublishAt  *time.Time
+		wantErr        error
+	}{
+		{name: "empty is public", visibility: "", wantVisibility: constants.VideoVisibilityPublic},
+		{name: "public", visibility: constants.VideoVisibilityPublic, wantVisibility: constants.VideoVisibilityPublic},
+		{name: "unlisted", visibility: constants.VideoVisibilityUnlisted, wantVisibility: constants.VideoVisibilityUnlisted},
+		{name: "private", visibility: constants.VideoVisibilityPrivate, wantVisibility: constants.VideoVisibilityPrivate},
+		{name: "publish_at dropped without schedule", visibility: constants.VideoVisibilityPrivate, publishAt: &publishAt, wantVisibility: constants.VideoVisibilityPrivate},
+		{name: "scheduled", visibility: constants.VideoVisibilityScheduled, publishAt: &publishAt, wantVisibility: constants.VideoVisibilityScheduled, wantPublishAt: &publishAt},
+		{name: "scheduled without publish_at", visibility: constants.VideoVisibilityScheduled, wantErr: constants.ErrInvalidVisibility},
+		{name: "unknown", visibility: "friends", wantErr: constants.ErrInvalidVisibility},
+		{name: "case sensitive", visibility: "Public", wantErr: constants.ErrInvalidVisibility},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			visibility, publishAt, err := resolveVisibility(tt.visibility, tt.publishAt)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("resolveVisibility() error = %v, want %v", err, tt.wantErr)
+			}
+			if visibility != tt.wantVisibility {
+				t.Errorf("resolveVisibility() visibility = %q, want %q", visibility, tt.wantVisibility)
+			}
+			if publishAt != tt.wantPublishAt {
+				t.Errorf("resolveVisibility() publishAt = %v, want %v", publishAt, tt.wantPublishAt)
+			}
+		})
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{name: "nil", tags: nil, want: []string{}},
+		{name: "lower case", tags: []string{"Go", "VIDEO"}, want: []string{"go", "video"}},
+		{name: "trimmed", tags: []string{"  go ", "\tvideo\n"}, want: []string{"go", "video"}},
+		{name: "empty dropped", tags: []string{"", "  ", "go"}, want: []string{"go"}},
+		{name: "duplicates dropped in order", tags: []string{"b", "A", "a", "B ", "c"}, want: []string{"b", "a", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeTags(tt.tags); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normalizeTags(%q) = %q, want %q", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateVideoAccess(t *testing.T) {
+	ready := string(constants.VideoStatusReady)
+
+	tests := []struct {
+		name       string
+		visibility string
+		status     string
+		blocked    bool
+		wantErr    error
+	}{
+		{name: "public", visibility: constants.VideoVisibilityPublic, status: ready},
+		{name: "unlisted", visibility: constants.VideoVisibilityUnlisted, status: ready},
+		{name: "private", visibility: constants.VideoVisibilityPrivate, status: ready, wantErr: constants.ErrVideoPrivate},
+		{name: "scheduled", visibility: constants.VideoVisibilityScheduled, status: ready, wantErr: constants.ErrVideoPrivate},
+		{name: "blocked", visibility: constants.VideoVisibilityPublic, status: ready, blocked: true, wantErr: constants.ErrVideoBlocked},
+		{name: "processing", visibility: constants.VideoVisibilityPublic, status: string(constants.VideoStatusProcessing), wantErr: constants.ErrVideoProcessing},
+		{name: "uploaded", visibility: constants.VideoVisibilityPublic, status: string(constants.VideoStatusUploaded), wantErr: constants.ErrVideoProcessing},
+		{name: "failed", visibility: constants.VideoVisibilityPublic, status: string(constants.VideoStatusError), wantErr: constants.ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			video := &entity.Video{
+				Visibility: tt.visibility,
+				IsPrivate:  entity.IsPrivateVisibility(tt.visibility),
+				Status:     tt.status,
+				IsBlocked:  tt.blocked,
+			}
+			if err := validateVideoAccess(video); !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateVideoAccess() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHLSMapURI(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   string
+		wantOK bool
+	}{
+		{name: "map", line: `#EXT-X-MAP:URI="init.mp4"`, want: "init.mp4", wantOK: true},
+		{name: "map with byte range", line: `#EXT-X-MAP:URI="init.mp4",BYTERANGE="720@0"`, want: "init.mp4", wantOK: true},
+		{name: "other tag", line: "#EXTINF:6.000000,"},
+		{name: "map without uri", line: `#EXT-X-MAP:BYTERANGE="720@0"`},
+		{name: "unterminated uri", line: `#EXT-X-MAP:URI="init.mp4`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := hlsMapURI(tt.line)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("hlsMapURI(%q) = %q, %v, want %q, %v", tt.line, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"bytes"
+)
+
+// VideoContainer контейнер видео, распознанный по сигнатуре
+type VideoContainer struct {
+	ContentType string
+	// Extension - расширение файла с точкой, под которым исходник хранится
+	Extension string
+}
+
+// Размер пакета MPEG-TS и M2TS (TS с 4-байтовой меткой времени перед пакетом)
+const (
+	tsPacketSize   = 188
+	m2tsPacketSize = 192
+	tsSyncByte     = 0x47
+)
+
+var (
+	ebmlSignature = []byte{0x1a, 0x45, 0xdf, 0xa3}
+	asfSignature  = []byte{0x30, 0x26, 0xb2, 0x75, 0x8e, 0x66, 0xcf, 0x11, 0xa6, 0xd9, 0x00, 0xaa, 0x00, 0x62, 0xce, 0x6c}
+	mpegPSPack    = []byte{0x00, 0x00, 0x01, 0xba}
+)
+
+// DetectVideoContainer распознает контейнер видео по первым байтам файла (SniffLength достаточно).
+// Расширению и типу, заявленным клиентом, не доверяем: файл без известной сигнатуры видео не принимается
+func DetectVideoContainer(head []byte) (*VideoContainer, bool) {
+	switch {
+	case len(head) >= 12 && bytes.Equal(head[4:8], []byte("ftyp")):
+		brand := string(head[8:12])
+		switch {
+		case brand == "qt  ":
+			return &VideoContainer{ContentType: "video/quicktime", Extension: ".mov"}, true
+		case brand == "M4A " || brand == "M4B " || brand == "M4P ":
+			// Звук в контейнере MP4
+			return nil, false
+		case brand[:3] == "3gp" || brand[:3] == "3g2":
+			return &VideoContainer{ContentType: "video/3gpp", Extension: ".3gp"}, true
+		}
+		return &VideoContainer{ContentType: "video/mp4", Extension: ".mp4"}, true
+
+	case bytes.HasPrefix(head, ebmlSignature):
+		if bytes.Contains(head, []byte("webm")) {
+			return &VideoContainer{ContentType: "video/webm", Extension: ".webm"}, true
+		}
+		if bytes.Contains(head, []byte("matroska")) {
+			return &VideoContainer{ContentType: "video/x-matroska", Extension: ".mkv"}, true
+		}
+
+	case len(head) >= 12 && bytes.Equal(head[:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("AVI ")):
+		return &VideoContainer{ContentType: "video/x-msvideo", Extension: ".avi"}, true
+
+	case bytes.HasPrefix(head, []byte("FLV\x01")):
+		return &VideoContainer{ContentType: "video/x-flv", Extension: ".flv"}, true
+
+	case bytes.HasPrefix(head, asfSignature):
+		return &VideoContainer{ContentType: "video/x-ms-asf", Extension: ".wmv"}, true
+
+	case bytes.HasPrefix(head, mpegPSPack):
+		return &VideoContainer{ContentType: "video/mpeg", Extension: ".mpg"}, true
+
+	case hasSyncBytes(head, 0, tsPacketSize):
+		return &VideoContainer{ContentType: "video/mp2t", Extension: ".ts"}, true
+
+	case hasSyncBytes(head, 4, m2tsPacketSize):
+		return &VideoContainer{ContentType: "video/mp2t", Extension: ".m2ts"}, true
+	}
+
+	return nil, false
+}
+
+// hasSyncBytes проверяет байт синхронизации в первых трех пакетах транспортного потока
+func hasSyncBytes(head []byte, offset, packetSize int) bool {
+	const packets = 3
+	if len(head) < offset+packetSize*(packets-1)+1 {
+		return false
+	}
+
+	for i := 0; i < packets; i++ {
+		if head[offset+packetSize*i] != tsSyncByte {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"testing"
+)
+
+// transportStream собирает packets пакетов транспортного потока с меткой времени длиной offset перед каждым
+func transportStream(offset, packetSize, packets int) []byte {
+	head := make([]byte, packetSize*packets)
+	for i := 0; i < packets; i++ {
+		head[i*packetSize+offset] = tsSyncByte
+	}
+	return head
+}
+
+func TestDetectVideoContainer(t *testing.T) {
+	tests := []struct {
+		name          string
+		head          []byte
+		wantOK        bool
+		wantExtension string
+	}{
+		{name: "mp4", head: []byte("\x00\x00\x00\x20ftypisom\x00\x00\x02\x00"), wantOK: true, wantExtension: ".mp4"},
+		{name: "quicktime", head: []byte("\x00\x00\x00\x14ftypqt  \x00\x00\x00\x00"), wantOK: true, wantExtension: ".mov"},
+		{name: "3gp", head: []byte("\x00\x00\x00\x18ftyp3gp4\x00\x00\x00\x00"), wantOK: true, wantExtension: ".3gp"},
+		{name: "mp4 audio", head: []byte("\x00\x00\x00\x20ftypM4A \x00\x00\x00\x00")},
+		{name: "webm", head: append([]byte{0x1a, 0x45, 0xdf, 0xa3, 0x9f, 0x42, 0x82, 0x84}, "webm"...), wantOK: true, wantExtension: ".webm"},
+		{name: "matroska", head: append([]byte{0x1a, 0x45, 0xdf, 0xa3, 0x9f, 0x42, 0x82, 0x88}, "matroska"...), wantOK: true, wantExtension: ".mkv"},
+		{name: "ebml without doctype", head: []byte{0x1a, 0x45, 0xdf, 0xa3, 0x00, 0x00}},
+		{name: "avi", head: []byte("RIFF\x00\x00\x00\x00AVI LIST"), wantOK: true, wantExtension: ".avi"},
+		{name: "wav", head: []byte("RIFF\x00\x00\x00\x00WAVEfmt ")},
+		{name: "flv", head: []byte("FLV\x01\x05\x00\x00\x00\x09"), wantOK: true, wantExtension: ".flv"},
+		{name: "asf", head: append(append([]byte{}, asfSignature...), 0x00), wantOK: true, wantExtension: ".wmv"},
+		{name: "mpeg program stream", head: []byte{0x00, 0x00, 0x01, 0xba, 0x44}, wantOK: true, wantExtension: ".mpg"},
+		{name: "transport stream", head: transportStream(0, tsPacketSize, 3), wantOK: true, wantExtension: ".ts"},
+		{name: "m2ts", head: transportStream(4, m2tsPacketSize, 3), wantOK: true, wantExtension: ".m2ts"},
+		{name: "single ts packet", head: transportStream(0, tsPacketSize, 1)},
+		{name: "png", head: []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")},
+		{name: "text", head: []byte("#EXTM3U\n#EXT-X-VERSION:3\n")},
+		{name: "empty", head: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			container, ok := DetectVideoContainer(tt.head)
+			if ok != tt.wantOK {
+				t.Fatalf("DetectVideoContainer() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if container.Extension != tt.wantExtension {
+				t.Errorf("DetectVideoContainer() extension = %q, want %q", container.Extension, tt.wantExtension)
+			}
+		})
+	}
+}
@@ -0,0 +1,551 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrkbwp/gotube/pkg/constants"
+)
+
+// LocalStoragePrefix - путь API, по которому LocalStorage отдает объекты
+const LocalStoragePrefix = "/api/v1/storage"
+
+// localMultipartDir - папка частей составных загрузок. Имя бакета не может начинаться с точки
+const localMultipartDir = ".multipart"
+
+// LocalStorage хранит объекты в папках бакетов на диске, ссылки ведут на API и подписываются HMAC
+type LocalStorage struct {
+	rootDir   string
+	publicURL string
+	secret    []byte
+}
+
+func NewLocalStorage(rootDir, publicURL, secret string) (*LocalStorage, error) {
+	if secret == "" {
+		return nil, errors.New("storage signing secret is required for local storage")
+	}
+
+	if err := os.MkdirAll(rootDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	return &LocalStorage{
+		rootDir:   rootDir,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+		secret:    []byte(secret),
+	}, nil
+}
+
+// EnsureBucketExists создает папку бакета
+func (l *LocalStorage) EnsureBucketExists(ctx context.Context, bucketName string) error {
+	dir, err := l.objectPath(bucketName, "")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create bucket: %w", err)
+	}
+
+	return nil
+}
+
+// UploadFile пишет объект во временный файл и переименовывает его, чтобы читатели не видели недописанный объект
+func (l *LocalStorage) UploadFile(ctx context.Context, bucketName, objectName string, reader io.Reader, opts UploadOptions) (*ObjectInfo, error) {
+	filePath, err := l.objectPath(bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	upload, err := prepareUpload(objectName, reader, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, upload.reader); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	// Как и S3, не сохраняем объект, размер которого не совпал с заявленным
+	if opts.Size > 0 && upload.size != opts.Size {
+		return nil, fmt.Errorf("failed to upload file: read %d bytes, expected %d", upload.size, opts.Size)
+	}
+
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	object := objectInfo(objectName, info)
+	object.ContentType = upload.contentType
+	object.Checksum = upload.checksum()
+
+	return object, nil
+}
+
+// DownloadFile копирует объект в локальный файл
+func (l *LocalStorage) DownloadFile(ctx context.Context, bucketName, objectName string, filePath string) error {
+	src, err := l.Open(bucketName, objectName)
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+
+	return nil
+}
+
+// ReadFile читает объект целиком
+func (l *LocalStorage) ReadFile(ctx context.Context, bucketName, objectName string) ([]byte, error) {
+	filePath, err := l.objectPath(bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, constants.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return data, nil
+}
+
+// ReadFileHead читает начало файла объекта
+func (l *LocalStorage) ReadFileHead(ctx context.Context, bucketName, objectName string, length int64) ([]byte, error) {
+	filePath, err := l.objectPath(bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, constants.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, length))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return data, nil
+}
+
+// DeleteFile удаляет объект, отсутствие объекта ошибкой не считается, как и в S3
+func (l *LocalStorage) DeleteFile(ctx context.Context, bucketName, objectName string) error {
+	filePath, err := l.objectPath(bucketName, objectName)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
+
+// GetFileURL возвращает ссылку на API, подписанную до момента истечения. expires - time.Duration, как у MinIO
+func (l *LocalStorage) GetFileURL(ctx context.Context, bucketName, objectName string, expires int) (string, error) {
+	if _, err := l.objectPath(bucketName, objectName); err != nil {
+		return "", err
+	}
+
+	expiresAt := time.Now().Add(time.Duration(expires)).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt, 10))
+	query.Set("signature", l.sign(bucketName, objectName, expiresAt))
+
+	return l.GetPublicURL(bucketName, objectName) + "?" + query.Encode(), nil
+}
+
+// GetPublicURL возвращает ссылку на API без подписи, она работает только для публичных бакетов
+func (l *LocalStorage) GetPublicURL(bucketName, objectName string) string {
+	return l.publicURL + LocalStoragePrefix + "/" + bucketName + "/" + objectName
+}
+
+// StatFile возвращает сведения об объекте
+func (l *LocalStorage) StatFile(ctx context.Context, bucketName, objectName string) (*ObjectInfo, error) {
+	filePath, err := l.objectPath(bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, constants.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, constants.ErrNotFound
+	}
+
+	return objectInfo(objectName, info), nil
+}
+
+// ListFiles обходит папку бакета и возвращает объекты с префиксом
+func (l *LocalStorage) ListFiles(ctx context.Context, bucketName, prefix string) ([]*ObjectInfo, error) {
+	bucketDir, err := l.objectPath(bucketName, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []*ObjectInfo
+	err = filepath.WalkDir(bucketDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(bucketDir, filePath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, objectInfo(key, info))
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	return objects, nil
+}
+
+// NewMultipartUpload создает папку для частей составной загрузки
+func (l *LocalStorage) NewMultipartUpload(ctx context.Context, bucketName, objectName string, opts UploadOptions) (string, error) {
+	if _, err := l.objectPath(bucketName, objectName); err != nil {
+		return "", err
+	}
+
+	uploadID := uuid.NewString()
+	if err := os.MkdirAll(l.multipartDir(uploadID), 0755); err != nil {
+		return "", fmt.Errorf("failed to start multipart upload: %w", err)
+	}
+
+	return uploadID, nil
+}
+
+// UploadPart сохраняет часть в папку составной загрузки
+func (l *LocalStorage) UploadPart(ctx context.Context, bucketName, objectName, uploadID string, number int, reader io.Reader, size int64) (*Part, error) {
+	dir, err := l.existingMultipartDir(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	partPath := filepath.Join(dir, strconv.Itoa(number))
+	file, err := os.Create(partPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload part: %w", err)
+	}
+	defer file.Close()
+
+	hasher := md5.New()
+	written, err := io.Copy(io.MultiWriter(file, hasher), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload part: %w", err)
+	}
+	if size > 0 && written != size {
+		return nil, fmt.Errorf("failed to upload part: read %d bytes, expected %d", written, size)
+	}
+
+	return &Part{
+		Number: number,
+		ETag:   hex.EncodeToString(hasher.Sum(nil)),
+		Size:   written,
+	}, nil
+}
+
+// CompleteMultipartUpload склеивает части в объект и удаляет папку составной загрузки
+func (l *LocalStorage) CompleteMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string, parts []Part) (*ObjectInfo, error) {
+	dir, err := l.existingMultipartDir(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	readers := make([]io.Reader, 0, len(parts))
+	for _, part := range parts {
+		file, err := os.Open(filepath.Join(dir, strconv.Itoa(part.Number)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open part %d: %w", part.Number, err)
+		}
+		defer file.Close()
+		readers = append(readers, file)
+	}
+
+	object, err := l.UploadFile(ctx, bucketName, objectName, io.MultiReader(readers...), UploadOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("failed to remove multipart upload: %w", err)
+	}
+
+	return object, nil
+}
+
+// AbortMultipartUpload удаляет папку составной загрузки
+func (l *LocalStorage) AbortMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string) error {
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return fmt.Errorf("invalid upload id %q", uploadID)
+	}
+
+	if err := os.RemoveAll(l.multipartDir(uploadID)); err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+
+	return nil
+}
+
+// multipartDir возвращает папку частей составной загрузки, она лежит вне папок бакетов
+func (l *LocalStorage) multipartDir(uploadID string) string {
+	return filepath.Join(l.rootDir, localMultipartDir, uploadID)
+}
+
+func (l *LocalStorage) existingMultipartDir(uploadID string) (string, error) {
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return "", fmt.Errorf("invalid upload id %q", uploadID)
+	}
+
+	dir := l.multipartDir(uploadID)
+	if _, err := os.Stat(dir); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", constants.ErrNotFound
+		}
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// Open открывает объект для отдачи через API
+func (l *LocalStorage) Open(bucketName, objectName string) (*os.File, error) {
+	filePath, err := l.objectPath(bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, constants.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// localPostPolicy условия формы загрузки, подписываются целиком
+type localPostPolicy struct {
+	Bucket      string `json:"bucket"`
+	Key         string `json:"key"`
+	ContentType string `json:"content_type"`
+	MinSize     int64  `json:"min_size"`
+	MaxSize     int64  `json:"max_size"`
+	Checksum    string `json:"checksum,omitempty"`
+	Expires     int64  `json:"expires"`
+}
+
+// PresignedPostPolicy возвращает форму загрузки на API. Условия передаются в поле policy в base64 и подписываются HMAC
+func (l *LocalStorage) PresignedPostPolicy(ctx context.Context, bucketName, objectName string, policy PostPolicy) (*PresignedPost, error) {
+	if _, err := l.objectPath(bucketName, objectName); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(policy.Expires)
+	data, err := json.Marshal(localPostPolicy{
+		Bucket:      bucketName,
+		Key:         objectName,
+		ContentType: policy.ContentType,
+		MinSize:     policy.MinSize,
+		MaxSize:     policy.MaxSize,
+		Checksum:    policy.Checksum,
+		Expires:     expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode post policy: %w", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	return &PresignedPost{
+		URL: l.publicURL + LocalStoragePrefix + "/" + bucketName,
+		FormData: map[string]string{
+			"key":          objectName,
+			"Content-Type": policy.ContentType,
+			"policy":       encoded,
+			"signature":    l.signPolicy(encoded),
+		},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// PostObject сохраняет файл формы загрузки, если он удовлетворяет подписанным условиям из fields.
+// Файл вне диапазона размеров или с другой контрольной суммой удаляется
+func (l *LocalStorage) PostObject(ctx context.Context, bucketName string, fields map[string]string, file io.Reader) (*ObjectInfo, error) {
+	encoded := fields["policy"]
+	if !hmac.Equal([]byte(l.signPolicy(encoded)), []byte(fields["signature"])) {
+		return nil, constants.ErrInvalidSignature
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, constants.ErrInvalidSignature
+	}
+	var policy localPostPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, constants.ErrInvalidSignature
+	}
+
+	if time.Now().Unix() > policy.Expires {
+		return nil, constants.ErrInvalidSignature
+	}
+	if policy.Bucket != bucketName || policy.Key != fields["key"] {
+		return nil, fmt.Errorf("%w: bucket or key does not match", constants.ErrPolicyViolation)
+	}
+	if policy.ContentType != fields["Content-Type"] {
+		return nil, fmt.Errorf("%w: content type does not match", constants.ErrPolicyViolation)
+	}
+
+	// Лишний байт сверх максимума показывает, что файл больше разрешенного
+	object, err := l.UploadFile(ctx, bucketName, policy.Key, io.LimitReader(file, policy.MaxSize+1), UploadOptions{
+		ContentType: policy.ContentType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var violation error
+	switch {
+	case object.Size < policy.MinSize || object.Size > policy.MaxSize:
+		violation = fmt.Errorf("%w: size is out of range", constants.ErrPolicyViolation)
+	case policy.Checksum != "" && object.Checksum != policy.Checksum:
+		violation = fmt.Errorf("%w: checksum does not match", constants.ErrPolicyViolation)
+	}
+	if violation != nil {
+		_ = l.DeleteFile(ctx, bucketName, policy.Key)
+		return nil, violation
+	}
+
+	return object, nil
+}
+
+// VerifySignature проверяет подпись ссылки и срок ее действия
+func (l *LocalStorage) VerifySignature(bucketName, objectName, expires, signature string) bool {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return false
+	}
+
+	expected := l.sign(bucketName, objectName, expiresAt)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func (l *LocalStorage) sign(bucketName, objectName string, expiresAt int64) string {
+	mac := hmac.New(sha256.New, l.secret)
+	fmt.Fprintf(mac, "%s/%s:%d", bucketName, objectName, expiresAt)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (l *LocalStorage) signPolicy(policy string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	fmt.Fprintf(mac, "post:%s", policy)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// objectPath возвращает путь к объекту на диске и не дает выйти за папку бакета
+func (l *LocalStorage) objectPath(bucketName, objectName string) (string, error) {
+	if bucketName == "" || strings.ContainsAny(bucketName, `/\`) || strings.HasPrefix(bucketName, ".") {
+		return "", fmt.Errorf("invalid bucket name %q", bucketName)
+	}
+
+	cleaned := path.Clean("/" + objectName)
+	if objectName != "" && (cleaned == "/" || cleaned != "/"+strings.TrimPrefix(objectName, "/")) {
+		return "", fmt.Errorf("invalid object name %q", objectName)
+	}
+
+	return filepath.Join(l.rootDir, bucketName, filepath.FromSlash(cleaned)), nil
+}
+
+func objectInfo(key string, info fs.FileInfo) *ObjectInfo {
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &ObjectInfo{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  contentType,
+		ETag:         fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size()),
+		LastModified: info.ModTime(),
+	}
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrkbwp/gotube/pkg/constants"
+)
+
+func TestLocalStorageObjectPath(t *testing.T) {
+	root := t.TempDir()
+	l, err := NewLocalStorage(root, "http://localhost:8080", "secret")
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		bucket  string
+		object  string
+		want    string
+		wantErr bool
+	}{
+		{name: "object", bucket: "videos", object: "a/b/video.mp4", want: filepath.Join(root, "videos", "a", "b", "video.mp4")},
+		{name: "leading slash", bucket: "videos", object: "/a/video.mp4", want: filepath.Join(root, "videos", "a", "video.mp4")},
+		{name: "bucket only", bucket: "videos", object: "", want: filepath.Join(root, "videos")},
+		{name: "dotted file name", bucket: "videos", object: "a/..video.mp4", want: filepath.Join(root, "videos", "a", "..video.mp4")},
+
+		{name: "parent directory", bucket: "videos", object: "../secret", wantErr: true},
+		{name: "escape after segment", bucket: "videos", object: "a/../../secret", wantErr: true},
+		{name: "parent inside path", bucket: "videos", object: "a/../b", wantErr: true},
+		{name: "current directory", bucket: "videos", object: "a/./b", wantErr: true},
+		{name: "double slash", bucket: "videos", object: "a//b", wantErr: true},
+		{name: "trailing slash", bucket: "videos", object: "a/", wantErr: true},
+		{name: "root", bucket: "videos", object: "/", wantErr: true},
+		{name: "dot", bucket: "videos", object: ".", wantErr: true},
+
+		{name: "empty bucket", bucket: "", object: "a", wantErr: true},
+		{name: "parent bucket", bucket: "..", object: "a", wantErr: true},
+		{name: "hidden bucket", bucket: ".videos", object: "a", wantErr: true},
+		{name: "bucket with slash", bucket: "videos/../x", object: "a", wantErr: true},
+		{name: "bucket with backslash", bucket: `videos\..`, object: "a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := l.objectPath(tt.bucket, tt.object)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("objectPath(%q, %q) = %q, want error", tt.bucket, tt.object, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("objectPath(%q, %q): %v", tt.bucket, tt.object, err)
+			}
+			if got != tt.want {
+				t.Errorf("objectPath(%q, %q) = %q, want %q", tt.bucket, tt.object, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalStorageVerifySignature(t *testing.T) {
+	l, err := NewLocalStorage(t.TempDir(), "http://localhost:8080", "secret")
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+
+	expiresAt := time.Now().Add(time.Hour).Unix()
+	expires := strconv.FormatInt(expiresAt, 10)
+	signature := l.sign("videos", "a/video.mp4", expiresAt)
+
+	expired := time.Now().Add(-time.Minute).Unix()
+
+	tests := []struct {
+		name      string
+		bucket    string
+		object    string
+		expires   string
+		signature string
+		want      bool
+	}{
+		{name: "valid", bucket: "videos", object: "a/video.mp4", expires: expires, signature: signature, want: true},
+		{name: "other object", bucket: "videos", object: "a/other.mp4", expires: expires, signature: signature},
+		{name: "other bucket", bucket: "thumbnails", object: "a/video.mp4", expires: expires, signature: signature},
+		{name: "extended expiry", bucket: "videos", object: "a/video.mp4", expires: strconv.FormatInt(expiresAt+1, 10), signature: signature},
+		{name: "expired", bucket: "videos", object: "a/video.mp4", expires: strconv.FormatInt(expired, 10), signature: l.sign("videos", "a/video.mp4", expired)},
+		{name: "malformed expiry", bucket: "videos", object: "a/video.mp4", expires: "soon", signature: signature},
+		{name: "empty signature", bucket: "videos", object: "a/video.mp4", expires: expires},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := l.VerifySignature(tt.bucket, tt.object, tt.expires, tt.signature); got != tt.want {
+				t.Errorf("VerifySignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalStorageReadFileHead(t *testing.T) {
+	l, err := NewLocalStorage(t.TempDir(), "http://localhost:8080", "secret")
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := l.UploadFile(ctx, "videos", "a/video.mp4", strings.NewReader("0123456789"), UploadOptions{Size: 10}); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		object  string
+		length  int64
+		want    string
+		wantErr error
+	}{
+		{name: "head", object: "a/video.mp4", length: 4, want: "0123"},
+		{name: "shorter object", object: "a/video.mp4", length: 512, want: "0123456789"},
+		{name: "missing object", object: "a/missing.mp4", length: 4, wantErr: constants.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := l.ReadFileHead(ctx, "videos", tt.object, tt.length)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReadFileHead() error = %v, want %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadFileHead() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,12 +2,14 @@ package storage
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/mrkbwp/gotube/pkg/constants"
 )
 
 type MinioClient struct {
@@ -15,9 +17,17 @@ type MinioClient struct {
 	internalClient *minio.Client
 	publicEndpoint string
 	publicURL      string
+	// partSize - размер части multipart загрузки
+	partSize uint64
 }
 
-func NewMinioClient(internalEndpoint, publicEndpoint, accessKey, secretKey string, useSSL, storageUseSSL bool) (*MinioClient, error) {
+// Ограничения multipart загрузки в S3
+const (
+	minUploadPartSize = 5 << 20
+	maxUploadParts    = 10000
+)
+
+func NewMinioClient(internalEndpoint, publicEndpoint, accessKey, secretKey string, useSSL, storageUseSSL bool, partSize int64) (*MinioClient, error) {
 	// Клиент для внутренних операций (загрузка, удаление и т.д.)
 	internalClient, err := minio.New(internalEndpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
@@ -36,6 +46,10 @@ func NewMinioClient(internalEndpoint, publicEndpoint, accessKey, secretKey strin
 		return nil, fmt.Errorf("failed to create public MinIO client: %w", err)
 	}
 
+	if partSize < minUploadPartSize {
+		partSize = minUploadPartSize
+	}
+
 	publicURL := fmt.Sprintf("https://%s", publicEndpoint)
 	if !storageUseSSL {
 		publicURL = fmt.Sprintf("http://%s", publicEndpoint)
@@ -46,6 +60,7 @@ func NewMinioClient(internalEndpoint, publicEndpoint, accessKey, secretKey strin
 		internalClient: internalClient,
 		publicEndpoint: publicEndpoint,
 		publicURL:      publicURL,
+		partSize:       uint64(partSize),
 	}, nil
 }
 
@@ -66,20 +81,54 @@ func (m *MinioClient) EnsureBucketExists(ctx context.Context, bucketName string)
 	return nil
 }
 
-// UploadFile использует внутренний клиент для загрузки
-func (m *MinioClient) UploadFile(ctx context.Context, bucketName, objectName string, reader io.Reader) error {
+// UploadFile использует внутренний клиент для загрузки. С известным размером объект
+// передается потоком по частям без буферизации, SHA-256 считается по ходу передачи
+func (m *MinioClient) UploadFile(ctx context.Context, bucketName, objectName string, reader io.Reader, opts UploadOptions) (*ObjectInfo, error) {
 	if err := m.EnsureBucketExists(ctx, bucketName); err != nil {
-		return err
+		return nil, err
+	}
+
+	upload, err := prepareUpload(objectName, reader, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	size := opts.Size
+	if size <= 0 {
+		size = -1
 	}
 
-	_, err := m.internalClient.PutObject(ctx, bucketName, objectName, reader, -1, minio.PutObjectOptions{
-		ContentType: "application/octet-stream",
+	info, err := m.internalClient.PutObject(ctx, bucketName, objectName, upload.reader, size, minio.PutObjectOptions{
+		ContentType: upload.contentType,
+		PartSize:    m.uploadPartSize(size),
 	})
 	if err != nil {
-		return fmt.Errorf("failed to upload file: %w", err)
+		return nil, fmt.Errorf("failed to upload file: %w", err)
 	}
 
-	return nil
+	return &ObjectInfo{
+		Key:          objectName,
+		Size:         info.Size,
+		ContentType:  upload.contentType,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+		Checksum:     upload.checksum(),
+	}, nil
+}
+
+// uploadPartSize возвращает размер части для объекта. Объекты меньше части SDK загружает одним запросом,
+// для очень больших файлов часть увеличивается, чтобы уложиться в 10000 частей
+func (m *MinioClient) uploadPartSize(size int64) uint64 {
+	if size > 0 && size < int64(m.partSize) {
+		return 0
+	}
+
+	if size > int64(m.partSize)*maxUploadParts {
+		// Округляем вверх до мегабайта
+		return uint64((size/maxUploadParts)>>20+1) << 20
+	}
+
+	return m.partSize
 }
 
 // GetFileURL использует публичный клиент для генерации URL
@@ -114,6 +163,49 @@ func (m *MinioClient) DownloadFile(ctx context.Context, bucketName, objectName s
 	return nil
 }
 
+// ReadFile использует внутренний клиент для чтения небольших объектов (плейлисты, манифесты)
+func (m *MinioClient) ReadFile(ctx context.Context, bucketName, objectName string) ([]byte, error) {
+	object, err := m.internalClient.GetObject(ctx, bucketName, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	defer object.Close()
+
+	data, err := io.ReadAll(object)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, constants.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return data, nil
+}
+
+// ReadFileHead запрашивает у хранилища только начало объекта
+func (m *MinioClient) ReadFileHead(ctx context.Context, bucketName, objectName string, length int64) ([]byte, error) {
+	opts := minio.GetObjectOptions{}
+	if err := opts.SetRange(0, length-1); err != nil {
+		return nil, fmt.Errorf("failed to set range: %w", err)
+	}
+
+	object, err := m.internalClient.GetObject(ctx, bucketName, objectName, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	defer object.Close()
+
+	data, err := io.ReadAll(io.LimitReader(object, length))
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, constants.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return data, nil
+}
+
 // GetPermanentURL использует публичный клиент для генерации постоянного URL
 func (m *MinioClient) GetPermanentURL(ctx context.Context, bucketName, objectName string) (string, error) {
 	url, err := m.client.PresignedGetObject(ctx, bucketName, objectName, time.Hour*24*365*10, nil)
@@ -122,3 +214,155 @@ func (m *MinioClient) GetPermanentURL(ctx context.Context, bucketName, objectNam
 	}
 	return url.String(), nil
 }
+
+// StatFile использует внутренний клиент для получения сведений об объекте
+func (m *MinioClient) StatFile(ctx context.Context, bucketName, objectName string) (*ObjectInfo, error) {
+	info, err := m.internalClient.StatObject(ctx, bucketName, objectName, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, constants.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	return &ObjectInfo{
+		Key:          info.Key,
+		Size:         info.Size,
+		ContentType:  info.ContentType,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+	}, nil
+}
+
+// ListFiles использует внутренний клиент для рекурсивного обхода объектов с префиксом
+func (m *MinioClient) ListFiles(ctx context.Context, bucketName, prefix string) ([]*ObjectInfo, error) {
+	var objects []*ObjectInfo
+	for object := range m.internalClient.ListObjects(ctx, bucketName, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", object.Err)
+		}
+
+		objects = append(objects, &ObjectInfo{
+			Key:          object.Key,
+			Size:         object.Size,
+			ContentType:  object.ContentType,
+			ETag:         object.ETag,
+			LastModified: object.LastModified,
+		})
+	}
+
+	return objects, nil
+}
+
+// NewMultipartUpload использует внутренний клиент для начала составной загрузки
+func (m *MinioClient) NewMultipartUpload(ctx context.Context, bucketName, objectName string, opts UploadOptions) (string, error) {
+	if err := m.EnsureBucketExists(ctx, bucketName); err != nil {
+		return "", err
+	}
+
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = DetectContentType(objectName, nil)
+	}
+
+	core := minio.Core{Client: m.internalClient}
+	uploadID, err := core.NewMultipartUpload(ctx, bucketName, objectName, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to start multipart upload: %w", err)
+	}
+
+	return uploadID, nil
+}
+
+// UploadPart использует внутренний клиент для загрузки части
+func (m *MinioClient) UploadPart(ctx context.Context, bucketName, objectName, uploadID string, number int, reader io.Reader, size int64) (*Part, error) {
+	core := minio.Core{Client: m.internalClient}
+	part, err := core.PutObjectPart(ctx, bucketName, objectName, uploadID, number, reader, size, minio.PutObjectPartOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload part: %w", err)
+	}
+
+	return &Part{
+		Number: part.PartNumber,
+		ETag:   part.ETag,
+		Size:   part.Size,
+	}, nil
+}
+
+// CompleteMultipartUpload использует внутренний клиент для сборки объекта из частей
+func (m *MinioClient) CompleteMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string, parts []Part) (*ObjectInfo, error) {
+	completeParts := make([]minio.CompletePart, 0, len(parts))
+	for _, part := range parts {
+		completeParts = append(completeParts, minio.CompletePart{
+			PartNumber: part.Number,
+			ETag:       part.ETag,
+		})
+	}
+
+	core := minio.Core{Client: m.internalClient}
+	if _, err := core.CompleteMultipartUpload(ctx, bucketName, objectName, uploadID, completeParts, minio.PutObjectOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+
+	return m.StatFile(ctx, bucketName, objectName)
+}
+
+// AbortMultipartUpload использует внутренний клиент для отмены составной загрузки
+func (m *MinioClient) AbortMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string) error {
+	core := minio.Core{Client: m.internalClient}
+	if err := core.AbortMultipartUpload(ctx, bucketName, objectName, uploadID); err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchUpload" {
+			return nil
+		}
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+
+	return nil
+}
+
+// PresignedPostPolicy использует публичный клиент, форма отправляется браузером прямо в MinIO
+func (m *MinioClient) PresignedPostPolicy(ctx context.Context, bucketName, objectName string, policy PostPolicy) (*PresignedPost, error) {
+	expiresAt := time.Now().Add(policy.Expires)
+
+	post := minio.NewPostPolicy()
+	if err := post.SetBucket(bucketName); err != nil {
+		return nil, err
+	}
+	if err := post.SetKey(objectName); err != nil {
+		return nil, err
+	}
+	if err := post.SetExpires(expiresAt); err != nil {
+		return nil, err
+	}
+	if err := post.SetContentType(policy.ContentType); err != nil {
+		return nil, err
+	}
+	if err := post.SetContentLengthRange(policy.MinSize, policy.MaxSize); err != nil {
+		return nil, err
+	}
+	if policy.Checksum != "" {
+		checksum, err := hex.DecodeString(policy.Checksum)
+		if err != nil {
+			return nil, fmt.Errorf("invalid checksum: %w", err)
+		}
+		if err := post.SetChecksum(minio.NewChecksum(minio.ChecksumSHA256, checksum)); err != nil {
+			return nil, err
+		}
+	}
+
+	url, formData, err := m.client.PresignedPostPolicy(ctx, post)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate presigned post policy: %w", err)
+	}
+
+	return &PresignedPost{
+		URL:       url.String(),
+		FormData:  formData,
+		ExpiresAt: expiresAt,
+	}, nil
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mrkbwp/gotube/pkg/config"
+	"github.com/mrkbwp/gotube/pkg/constants"
+)
+
+// ObjectStorage хранилище объектов, разложенных по бакетам
+type ObjectStorage interface {
+	// EnsureBucketExists создает бакет, если его нет
+	EnsureBucketExists(ctx context.Context, bucketName string) error
+
+	// UploadFile загружает объект из reader и возвращает его размер, тип и SHA-256
+	UploadFile(ctx context.Context, bucketName, objectName string, reader io.Reader, opts UploadOptions) (*ObjectInfo, error)
+
+	// DownloadFile скачивает объект в локальный файл
+	DownloadFile(ctx context.Context, bucketName, objectName string, filePath string) error
+
+	// ReadFile читает небольшой объект целиком (плейлисты, манифесты), ErrNotFound если его нет
+	ReadFile(ctx context.Context, bucketName, objectName string) ([]byte, error)
+
+	// ReadFileHead читает первые length байт объекта или весь объект, если он короче, ErrNotFound если его нет
+	ReadFileHead(ctx context.Context, bucketName, objectName string, length int64) ([]byte, error)
+
+	// DeleteFile удаляет объект
+	DeleteFile(ctx context.Context, bucketName, objectName string) error
+
+	// GetFileURL возвращает временную подписанную ссылку на объект
+	GetFileURL(ctx context.Context, bucketName, objectName string, expires int) (string, error)
+
+	// GetPublicURL возвращает постоянную ссылку на объект публичного бакета
+	GetPublicURL(bucketName, objectName string) string
+
+	// StatFile возвращает сведения об объекте, ErrNotFound если его нет
+	StatFile(ctx context.Context, bucketName, objectName string) (*ObjectInfo, error)
+
+	// ListFiles возвращает объекты бакета, имена которых начинаются с prefix
+	ListFiles(ctx context.Context, bucketName, prefix string) ([]*ObjectInfo, error)
+
+	// NewMultipartUpload начинает составную загрузку объекта и возвращает ее идентификатор
+	NewMultipartUpload(ctx context.Context, bucketName, objectName string, opts UploadOptions) (string, error)
+
+	// UploadPart загружает часть number (с 1) составной загрузки. Все части, кроме последней, не меньше 5 МБ
+	UploadPart(ctx context.Context, bucketName, objectName, uploadID string, number int, reader io.Reader, size int64) (*Part, error)
+
+	// CompleteMultipartUpload собирает объект из частей по порядку номеров
+	CompleteMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string, parts []Part) (*ObjectInfo, error)
+
+	// AbortMultipartUpload отменяет составную загрузку и удаляет загруженные части
+	AbortMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string) error
+
+	// PresignedPostPolicy возвращает подписанную форму, которой клиент загружает объект в хранилище напрямую
+	PresignedPostPolicy(ctx context.Context, bucketName, objectName string, policy PostPolicy) (*PresignedPost, error)
+}
+
+// PostPolicy ограничения загрузки объекта формой POST
+type PostPolicy struct {
+	// ContentType - MIME тип, который клиент обязан передать в форме
+	ContentType string
+	MinSize     int64
+	MaxSize     int64
+	// Checksum - SHA-256 содержимого в hex, хранилище отклонит файл с другой суммой. Пустой - не проверяется
+	Checksum string
+	Expires  time.Duration
+}
+
+// PresignedPost подписанная форма загрузки: поля FormData отправляются multipart POST на URL,
+// файл передается последним полем file
+type PresignedPost struct {
+	URL       string            `json:"url"`
+	FormData  map[string]string `json:"form_data"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// Part загруженная часть составной загрузки
+type Part struct {
+	Number int
+	ETag   string
+	Size   int64
+}
+
+// ObjectInfo сведения об объекте хранилища
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+	// Checksum - SHA-256 содержимого в hex, известен только после загрузки
+	Checksum string
+}
+
+// NewObjectStorage создает хранилище, выбранное в STORAGE_DRIVER
+func NewObjectStorage(cfg *config.Config) (ObjectStorage, error) {
+	switch cfg.Storage.Driver {
+	case constants.StorageDriverMinio:
+		return NewMinioClient(
+			cfg.Minio.Endpoint,
+			cfg.Storage.BaseURL,
+			cfg.Minio.AccessKey,
+			cfg.Minio.SecretKey,
+			cfg.Minio.UseSSL,
+			cfg.Storage.UseSSL,
+			cfg.Storage.UploadPartSize,
+		)
+	case constants.StorageDriverLocal:
+		return NewLocalStorage(cfg.Storage.LocalPath, cfg.Storage.LocalPublicURL, cfg.Storage.SigningSecret)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+}
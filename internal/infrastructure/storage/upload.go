@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// UploadOptions параметры загрузки объекта
+type UploadOptions struct {
+	// Size - размер объекта в байтах, 0 если неизвестен. С известным размером MinIO не буферизует поток
+	Size int64
+	// ContentType - MIME тип, пустой определяется по расширению и первым байтам
+	ContentType string
+}
+
+// SniffLength - сколько первых байт нужно для определения типа по сигнатуре
+const SniffLength = 512
+
+// contentTypes - типы, которых нет в системной базе mime или которые там определены иначе
+var contentTypes = map[string]string{
+	".m3u8": "application/vnd.apple.mpegurl",
+	".mpd":  "application/dash+xml",
+	".vtt":  "text/vtt; charset=utf-8",
+	".ts":   "video/mp2t",
+	".m4s":  "video/iso.segment",
+	".mp4":  "video/mp4",
+	".m4v":  "video/mp4",
+	".m4a":  "audio/mp4",
+	".webm": "video/webm",
+	".mkv":  "video/x-matroska",
+	".mov":  "video/quicktime",
+	".avi":  "video/x-msvideo",
+	".opus": "audio/ogg",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".webp": "image/webp",
+	".json": "application/json",
+}
+
+// DetectContentType определяет MIME тип объекта по расширению имени, а если оно неизвестно - по первым байтам
+func DetectContentType(objectName string, head []byte) string {
+	ext := strings.ToLower(path.Ext(objectName))
+	if contentType, ok := contentTypes[ext]; ok {
+		return contentType
+	}
+	if contentType := mime.TypeByExtension(ext); contentType != "" && contentType != "application/octet-stream" {
+		return contentType
+	}
+
+	return SniffContentType(head)
+}
+
+// SniffContentType определяет MIME тип по сигнатуре в первых байтах.
+// Кроме сигнатур http.DetectContentType различает QuickTime и Matroska
+func SniffContentType(head []byte) string {
+	if len(head) == 0 {
+		return "application/octet-stream"
+	}
+	if len(head) >= 12 && bytes.Equal(head[4:8], []byte("ftyp")) {
+		switch string(head[8:12]) {
+		case "qt  ":
+			return "video/quicktime"
+		case "M4A ":
+			return "audio/mp4"
+		}
+		return "video/mp4"
+	}
+	if len(head) >= 4 && bytes.Equal(head[:4], []byte{0x1a, 0x45, 0xdf, 0xa3}) &&
+		!bytes.Contains(head, []byte("webm")) && bytes.Contains(head, []byte("matroska")) {
+		return "video/x-matroska"
+	}
+
+	return http.DetectContentType(head)
+}
+
+// preparedUpload поток загрузки, который по мере чтения считает SHA-256
+type preparedUpload struct {
+	reader      io.Reader
+	contentType string
+	hash        hash.Hash
+	size        int64
+}
+
+// prepareUpload определяет тип содержимого и оборачивает поток подсчетом SHA-256 и размера
+func prepareUpload(objectName string, reader io.Reader, opts UploadOptions) (*preparedUpload, error) {
+	upload := &preparedUpload{
+		contentType: opts.ContentType,
+		hash:        sha256.New(),
+	}
+
+	if upload.contentType == "" {
+		buffered := bufio.NewReaderSize(reader, SniffLength)
+		head, err := buffered.Peek(SniffLength)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		upload.contentType = DetectContentType(objectName, head)
+		reader = buffered
+	}
+
+	upload.reader = io.TeeReader(reader, upload)
+	return upload, nil
+}
+
+func (u *preparedUpload) Write(p []byte) (int, error) {
+	u.size += int64(len(p))
+	return u.hash.Write(p)
+}
+
+// checksum возвращает SHA-256 прочитанных данных в hex
+func (u *preparedUpload) checksum() string {
+	return hex.EncodeToString(u.hash.Sum(nil))
+}
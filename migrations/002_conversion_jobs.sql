@@ -0,0 +1,34 @@
+-- migrations/002_conversion_jobs.sql
+
+-- +goose Up
+-- Задания конвертации: одно на видео (quality_id IS NULL) и по одному на каждое качество
+CREATE TABLE IF NOT EXISTS conversion_jobs (
+                                               id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                               video_id UUID NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+                                               quality_id UUID REFERENCES video_qualities(id) ON DELETE CASCADE,
+
+                                               status VARCHAR(20) NOT NULL DEFAULT 'pending',
+                                               attempts INTEGER NOT NULL DEFAULT 0,
+
+    -- Аренда задания воркером
+                                               lease_owner VARCHAR(255),
+                                               lease_expires_at TIMESTAMP WITH TIME ZONE,
+                                               heartbeat_at TIMESTAMP WITH TIME ZONE,
+
+                                               last_error TEXT,
+
+                                               created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                               updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS udx__conversion_jobs__video ON conversion_jobs(video_id) WHERE quality_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS udx__conversion_jobs__video__quality ON conversion_jobs(video_id, quality_id) WHERE quality_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_conversion_jobs_status ON conversion_jobs(status);
+CREATE INDEX IF NOT EXISTS idx_conversion_jobs_lease_expires_at ON conversion_jobs(lease_expires_at);
+
+-- Видео, зависшие в обработке до появления заданий, возвращаем в очередь
+INSERT INTO conversion_jobs (video_id, status, created_at, updated_at)
+SELECT id, 'pending', NOW(), NOW() FROM videos
+WHERE status IN ('uploaded', 'processing')
+  AND deleted_at IS NULL
+ON CONFLICT (video_id) WHERE quality_id IS NULL DO NOTHING;
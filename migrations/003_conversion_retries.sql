@@ -0,0 +1,23 @@
+-- migrations/003_conversion_retries.sql
+
+-- +goose Up
+-- Повторы заданий конвертации с паузой
+ALTER TABLE conversion_jobs ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE conversion_jobs ADD COLUMN IF NOT EXISTS failure_class VARCHAR(20);
+
+CREATE INDEX IF NOT EXISTS idx_conversion_jobs_next_attempt_at ON conversion_jobs(next_attempt_at);
+
+-- Задания, исчерпавшие попытки
+CREATE TABLE IF NOT EXISTS conversion_dead_letters (
+                                                       id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                                       job_id UUID NOT NULL REFERENCES conversion_jobs(id) ON DELETE CASCADE,
+                                                       video_id UUID NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+                                                       failure_class VARCHAR(20) NOT NULL,
+                                                       error TEXT NOT NULL,
+                                                       attempts INTEGER NOT NULL,
+                                                       created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                                       redriven_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversion_dead_letters_video_id ON conversion_dead_letters(video_id);
+CREATE INDEX IF NOT EXISTS idx_conversion_dead_letters_redriven_at ON conversion_dead_letters(redriven_at);
@@ -0,0 +1,56 @@
+-- migrations/004_transcode_profiles.sql
+
+-- +goose Up
+-- Профили транскодирования: каждое активное качество конвертируется каждым активным профилем
+CREATE TABLE IF NOT EXISTS transcode_profiles (
+                                                  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                                  name VARCHAR(50) NOT NULL UNIQUE,
+
+                                                  video_codec VARCHAR(10) NOT NULL CHECK (video_codec IN ('h264', 'h265', 'vp9', 'av1')),
+                                                  container VARCHAR(10) NOT NULL CHECK (container IN ('mp4', 'webm')),
+
+    -- crf - постоянное качество, bitrate - целевой битрейт качества (video_qualities.target_bitrate)
+                                                  rate_control VARCHAR(10) NOT NULL DEFAULT 'bitrate' CHECK (rate_control IN ('crf', 'bitrate')),
+                                                  crf INTEGER,
+                                                  preset VARCHAR(20),
+    -- maxrate и bufsize задаются множителями целевого битрейта качества
+                                                  max_rate_ratio NUMERIC(4, 2),
+                                                  buf_size_ratio NUMERIC(4, 2),
+                                                  gop_seconds INTEGER NOT NULL DEFAULT 2 CHECK (gop_seconds > 0),
+
+                                                  audio_codec VARCHAR(10) NOT NULL DEFAULT 'aac' CHECK (audio_codec IN ('aac', 'opus')),
+                                                  audio_bitrate INTEGER NOT NULL DEFAULT 128000,
+
+    -- Профиль по умолчанию нарезается в HLS и DASH, поэтому только mp4
+                                                  is_default BOOLEAN NOT NULL DEFAULT false,
+                                                  is_active BOOLEAN NOT NULL DEFAULT true,
+
+                                                  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                                  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+                                                  UNIQUE(video_codec, container),
+                                                  CHECK (rate_control <> 'crf' OR crf IS NOT NULL),
+                                                  CHECK (container <> 'webm' OR (video_codec IN ('vp9', 'av1') AND audio_codec = 'opus')),
+                                                  CHECK (NOT is_default OR (container = 'mp4' AND is_active))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS udx__transcode_profiles__default ON transcode_profiles(is_default) WHERE is_default;
+
+-- Базовые профили, дополнительные включаются через is_active
+INSERT INTO transcode_profiles (name, video_codec, container, rate_control, crf, preset, max_rate_ratio, buf_size_ratio, gop_seconds, audio_codec, audio_bitrate, is_default, is_active) VALUES
+                                                                                                                                                                                   ('h264-mp4', 'h264', 'mp4', 'bitrate', NULL, 'medium', 1.5, 2.0, 2, 'aac', 128000, true, true),
+                                                                                                                                                                                   ('h265-mp4', 'h265', 'mp4', 'crf', 28, 'medium', 1.5, 2.0, 2, 'aac', 128000, false, false),
+                                                                                                                                                                                   ('vp9-webm', 'vp9', 'webm', 'crf', 32, 'good', 1.5, 2.0, 2, 'opus', 128000, false, false),
+                                                                                                                                                                                   ('av1-webm', 'av1', 'webm', 'crf', 35, '8', 1.5, 2.0, 2, 'opus', 128000, false, false)
+ON CONFLICT (name) DO NOTHING;
+
+-- Файлы видео различаются кодеком, путь хранится явно для файлов дополнительных профилей
+ALTER TABLE video_files ADD COLUMN IF NOT EXISTS profile_id UUID REFERENCES transcode_profiles(id) ON DELETE SET NULL;
+ALTER TABLE video_files ADD COLUMN IF NOT EXISTS codec VARCHAR(10) NOT NULL DEFAULT 'h264';
+ALTER TABLE video_files ADD COLUMN IF NOT EXISTS storage_path VARCHAR(500);
+
+-- Раньше формат записывался расширением исходника (.mp4, .mov), файлы при этом были h264/aac в mp4
+UPDATE video_files SET file_format = 'mp4' WHERE file_format LIKE '.%';
+
+ALTER TABLE video_files DROP CONSTRAINT IF EXISTS video_files_video_id_quality_id_file_format_key;
+CREATE UNIQUE INDEX IF NOT EXISTS udx__video_files__video__quality__format__codec ON video_files(video_id, quality_id, file_format, codec);
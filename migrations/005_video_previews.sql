@@ -0,0 +1,5 @@
+-- migrations/005_video_previews.sql
+
+-- +goose Up
+-- WebVTT с превью для перемотки, ссылается на спрайты в бакете миниатюр
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS previews_vtt_url VARCHAR(500);
@@ -0,0 +1,29 @@
+-- migrations/006_video_thumbnails.sql
+
+-- +goose Up
+-- Варианты обложки видео: кадры из видео и загруженные владельцем
+CREATE TABLE IF NOT EXISTS video_thumbnails (
+                                                id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                                video_id UUID NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+
+    -- frame - кадр по позиции, scene - кадр смены сцены, custom - загружен владельцем
+                                                source VARCHAR(10) NOT NULL CHECK (source IN ('frame', 'scene', 'custom')),
+                                                position_seconds DOUBLE PRECISION,
+                                                url VARCHAR(500) NOT NULL,
+    -- Ссылки на стандартные размеры загруженной обложки
+                                                sizes JSONB,
+                                                is_selected BOOLEAN NOT NULL DEFAULT false,
+
+                                                created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_thumbnails_video_id ON video_thumbnails(video_id);
+CREATE UNIQUE INDEX IF NOT EXISTS udx__video_thumbnails__video__selected ON video_thumbnails(video_id) WHERE is_selected;
+
+-- Обложки, созданные до появления вариантов
+INSERT INTO video_thumbnails (video_id, source, url, is_selected)
+SELECT id, 'frame', thumbnail_url, true
+FROM videos
+WHERE thumbnail_url IS NOT NULL
+AND thumbnail_url <> ''
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,5 @@
+-- migrations/007_video_preview_clips.sql
+
+-- +goose Up
+-- Короткий ролик без звука для проигрывания в карточке видео при наведении
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS preview_clip_url VARCHAR(500);
@@ -0,0 +1,7 @@
+-- migrations/008_audio_renditions.sql
+
+-- +goose Up
+-- Служебное качество для звуковых дорожек без видео. Неактивно, чтобы не попадать в лестницу качеств
+INSERT INTO video_qualities (name, width, height, target_bitrate, is_active) VALUES
+    ('audio', 0, 0, 128000, false)
+ON CONFLICT (name) DO NOTHING;
@@ -0,0 +1,22 @@
+-- migrations/009_video_subtitles.sql
+
+-- +goose Up
+-- Субтитры видео в WebVTT, по одной дорожке на язык
+CREATE TABLE IF NOT EXISTS video_subtitles (
+                                               id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                               video_id UUID NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+
+    -- Язык в формате BCP 47 (en, ru, pt-BR)
+                                               language VARCHAR(35) NOT NULL,
+                                               label VARCHAR(100) NOT NULL,
+    -- Путь к WebVTT в бакете видео
+                                               storage_path VARCHAR(500) NOT NULL,
+                                               cues_count INTEGER NOT NULL DEFAULT 0,
+
+                                               created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                               updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+                                               UNIQUE(video_id, language)
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_subtitles_video_id ON video_subtitles(video_id);
@@ -0,0 +1,11 @@
+-- migrations/010_media_tracks.sql
+
+-- +goose Up
+-- Субтитры загружаются владельцем (upload) или извлекаются из контейнера при конвертации (embedded)
+ALTER TABLE video_subtitles ADD COLUMN IF NOT EXISTS source VARCHAR(10) NOT NULL DEFAULT 'upload' CHECK (source IN ('upload', 'embedded'));
+
+-- Язык звуковой дорожки файла в формате BCP 47, пустой если не указан или файл без звука
+ALTER TABLE video_files ADD COLUMN IF NOT EXISTS language VARCHAR(35) NOT NULL DEFAULT '';
+
+DROP INDEX IF EXISTS udx__video_files__video__quality__format__codec;
+CREATE UNIQUE INDEX IF NOT EXISTS udx__video_files__video__quality__format__codec__language ON video_files(video_id, quality_id, file_format, codec, language);
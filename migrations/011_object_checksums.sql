@@ -0,0 +1,10 @@
+-- migrations/011_object_checksums.sql
+
+-- +goose Up
+-- Размер, MIME тип и SHA-256 загруженного исходника
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS original_size BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS original_content_type VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS original_checksum VARCHAR(64) NOT NULL DEFAULT '';
+
+-- SHA-256 файла качества, пустой у файлов, загруженных до подсчета контрольных сумм
+ALTER TABLE video_files ADD COLUMN IF NOT EXISTS checksum VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,32 @@
+-- migrations/012_video_uploads.sql
+
+-- +goose Up
+-- Возобновляемые загрузки исходников по протоколу tus. Исходник пишется составной загрузкой
+-- хранилища прямо по пути видео, видео до завершения загрузки находится в статусе uploading
+CREATE TABLE IF NOT EXISTS video_uploads (
+                                             id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                             user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                             video_id UUID NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+
+                                             upload_length BIGINT NOT NULL CHECK (upload_length > 0),
+                                             upload_offset BIGINT NOT NULL DEFAULT 0,
+    -- Upload-Metadata клиента
+                                             metadata JSONB,
+
+    -- Идентификатор составной загрузки хранилища, пустой до первой части
+                                             storage_upload_id VARCHAR(255) NOT NULL DEFAULT '',
+    -- Загруженные части: номер, ETag, размер
+                                             parts JSONB NOT NULL DEFAULT '[]',
+    -- Размер хвоста меньше части, сохраненного в бакете uploads
+                                             tail_size BIGINT NOT NULL DEFAULT 0,
+    -- Состояние SHA-256 принятых байт, чтобы досчитать контрольную сумму в следующих запросах
+                                             hash_state BYTEA,
+
+                                             status VARCHAR(20) NOT NULL DEFAULT 'uploading' CHECK (status IN ('uploading', 'completed', 'terminated', 'expired')),
+                                             expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                             created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                             updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_uploads_user_id ON video_uploads(user_id);
+CREATE INDEX IF NOT EXISTS idx_video_uploads_expires_at ON video_uploads(expires_at) WHERE status = 'uploading';
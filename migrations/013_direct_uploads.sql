@@ -0,0 +1,7 @@
+-- migrations/013_direct_uploads.sql
+
+-- +goose Up
+-- Загрузка исходника формой прямо в хранилище. Запись нужна, чтобы незавершенные загрузки
+-- удалялись вместе с видео так же, как просроченные загрузки tus
+ALTER TABLE video_uploads
+    ADD COLUMN IF NOT EXISTS kind VARCHAR(10) NOT NULL DEFAULT 'tus' CHECK (kind IN ('tus', 'direct'));
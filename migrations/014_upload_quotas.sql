@@ -0,0 +1,35 @@
+-- migrations/014_upload_quotas.sql
+
+-- +goose Up
+-- Квоты загрузки. Строка с role задает квоту роли, строка с user_id - индивидуальную квоту пользователя,
+-- которая заменяет квоту роли целиком. NULL в лимите - без ограничения
+CREATE TABLE IF NOT EXISTS upload_quotas (
+                                             id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                             role VARCHAR(20) UNIQUE,
+                                             user_id UUID UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+
+    -- Суммарный размер исходников пользователя, включая незавершенные загрузки
+                                             max_storage_bytes BIGINT CHECK (max_storage_bytes >= 0),
+    -- Загрузки за сутки UTC: количество и суммарный размер
+                                             max_daily_uploads INTEGER CHECK (max_daily_uploads >= 0),
+                                             max_daily_bytes BIGINT CHECK (max_daily_bytes >= 0),
+
+                                             created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                             updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                             CHECK ((role IS NULL) <> (user_id IS NULL))
+);
+
+INSERT INTO upload_quotas (role, max_storage_bytes, max_daily_uploads, max_daily_bytes) VALUES
+    ('user', 53687091200, 20, 21474836480),
+    ('moderator', 214748364800, 100, 107374182400),
+    ('admin', NULL, NULL, NULL)
+ON CONFLICT (role) DO NOTHING;
+
+-- Начатые загрузки пользователя по суткам UTC. Счетчик не уменьшается при удалении видео
+CREATE TABLE IF NOT EXISTS user_upload_usage (
+                                                 user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                                 day DATE NOT NULL,
+                                                 uploads INTEGER NOT NULL DEFAULT 0,
+                                                 bytes BIGINT NOT NULL DEFAULT 0,
+                                                 PRIMARY KEY (user_id, day)
+);
@@ -0,0 +1,25 @@
+-- migrations/015_video_imports.sql
+
+-- +goose Up
+-- Импорт видео по ссылке. Видео создается в статусе importing, исходник скачивается в фоне
+-- процессом, арендовавшим задание, и дальше обрабатывается как обычная загрузка
+CREATE TABLE IF NOT EXISTS video_imports (
+                                             id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                             video_id UUID NOT NULL UNIQUE REFERENCES videos(id) ON DELETE CASCADE,
+                                             user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                             source_url TEXT NOT NULL,
+
+                                             status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'downloading', 'completed', 'failed')),
+                                             attempts INTEGER NOT NULL DEFAULT 0,
+                                             error TEXT,
+    -- Размер скачанного исходника
+                                             size BIGINT NOT NULL DEFAULT 0,
+    -- Аренда истекает, если процесс упал во время скачивания, тогда задание берется снова
+                                             lease_expires_at TIMESTAMP WITH TIME ZONE,
+
+                                             created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                             updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                             completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_imports_status ON video_imports(status) WHERE status IN ('pending', 'downloading');
@@ -0,0 +1,46 @@
+-- migrations/016_upload_batches.sql
+
+-- +goose Up
+-- Теги видео
+ALTER TABLE videos
+    ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_videos_tags ON videos USING GIN (tags);
+
+-- Пакетная загрузка: ZIP или набор файлов с манифестом. Файлы ждут во временной папке процесса,
+-- принявшего пакет, и по очереди загружаются как обычные видео
+CREATE TABLE IF NOT EXISTS upload_batches (
+                                              id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                              user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                              status VARCHAR(20) NOT NULL DEFAULT 'processing' CHECK (status IN ('processing', 'completed')),
+                                              total_items INTEGER NOT NULL,
+
+                                              created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    -- Меняется с каждым файлом, давно не менявшийся пакет считается прерванным
+                                              updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                              completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_batches_processing ON upload_batches(updated_at) WHERE status = 'processing';
+
+-- Файлы пакета со сведениями о видео из манифеста
+CREATE TABLE IF NOT EXISTS upload_batch_items (
+                                                  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                                  batch_id UUID NOT NULL REFERENCES upload_batches(id) ON DELETE CASCADE,
+                                                  position INTEGER NOT NULL,
+                                                  filename VARCHAR(255) NOT NULL,
+
+                                                  title VARCHAR(100) NOT NULL,
+                                                  description TEXT NOT NULL DEFAULT '',
+                                                  category_id UUID NOT NULL REFERENCES categories(id),
+                                                  privacy VARCHAR(20) NOT NULL DEFAULT 'public',
+                                                  tags TEXT[] NOT NULL DEFAULT '{}',
+
+                                                  status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+                                                  video_id UUID REFERENCES videos(id) ON DELETE SET NULL,
+                                                  error TEXT,
+
+                                                  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                                  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                                  UNIQUE (batch_id, position)
+);
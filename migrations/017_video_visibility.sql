@@ -0,0 +1,24 @@
+-- migrations/017_video_visibility.sql
+
+-- +goose Up
+-- Видимость видео: public - в списках, unlisted - только по ссылке,
+-- private - только владельцу и администраторам, scheduled - как private до publish_at, затем public
+ALTER TABLE videos
+    ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public' CHECK (visibility IN ('public', 'unlisted', 'private', 'scheduled')),
+    ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE videos SET visibility = 'private' WHERE is_private;
+
+-- is_private остается флагом, производным от видимости: private и scheduled - приватные
+UPDATE videos SET is_private = visibility IN ('private', 'scheduled');
+
+ALTER TABLE videos
+    ADD CONSTRAINT videos_scheduled_publish_at CHECK (visibility <> 'scheduled' OR publish_at IS NOT NULL),
+    ADD CONSTRAINT videos_is_private_visibility CHECK (is_private = (visibility IN ('private', 'scheduled')));
+
+CREATE INDEX IF NOT EXISTS idx_videos_visibility ON videos(visibility);
+CREATE INDEX IF NOT EXISTS idx_videos_publish_at ON videos(publish_at) WHERE visibility = 'scheduled';
+
+-- Время публикации видео пакетной загрузки с видимостью scheduled
+ALTER TABLE upload_batch_items
+    ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,32 @@
+-- migrations/018_video_shares.sql
+
+-- +goose Up
+-- Ссылки доступа к неопубликованным видео. Токен ссылки подписан и содержит ее ID,
+-- поэтому ссылку можно отозвать, а просмотры - посчитать
+CREATE TABLE IF NOT EXISTS video_share_links (
+                                                 id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                                 video_id UUID NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+                                                 created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- Хеш bcrypt пароля ссылки, NULL - без пароля
+                                                 password_hash VARCHAR(255),
+    -- Сколько раз можно открыть видео по ссылке, NULL - без ограничения
+                                                 max_views INTEGER CHECK (max_views > 0),
+                                                 views INTEGER NOT NULL DEFAULT 0,
+
+                                                 expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                                 revoked_at TIMESTAMP WITH TIME ZONE,
+                                                 created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_share_links_video_id ON video_share_links(video_id);
+
+-- Доступ к неопубликованному видео, выданный владельцем конкретному пользователю
+CREATE TABLE IF NOT EXISTS video_access_grants (
+                                                   video_id UUID NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+                                                   user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                                   granted_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                                   created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                                   PRIMARY KEY (video_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_access_grants_user_id ON video_access_grants(user_id);
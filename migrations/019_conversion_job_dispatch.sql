@@ -0,0 +1,8 @@
+-- migrations/019_conversion_job_dispatch.sql
+
+-- +goose Up
+-- Когда ожидающее задание последний раз отправлено воркерам через Kafka.
+-- NULL - задание еще не отправлялось или вернулось в очередь после сбоя
+ALTER TABLE conversion_jobs ADD COLUMN IF NOT EXISTS dispatched_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_conversion_jobs_dispatched_at ON conversion_jobs(dispatched_at);
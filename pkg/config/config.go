@@ -7,17 +7,20 @@ import (
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/mrkbwp/gotube/pkg/constants"
 )
 
 // Config содержит все настройки приложения
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	Minio    MinioConfig
-	Kafka    KafkaConfig
-	Auth     AuthConfig
-	Storage  StorageConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Redis      RedisConfig
+	Minio      MinioConfig
+	Kafka      KafkaConfig
+	Auth       AuthConfig
+	Storage    StorageConfig
+	Upload     UploadConfig
+	Conversion ConversionConfig
 }
 
 // ServerConfig настройки сервера
@@ -61,6 +64,8 @@ type MinioConfig struct {
 type KafkaConfig struct {
 	Brokers              []string
 	VideoProcessingTopic string
+	ConsumerGroup        string
+	DeadLetterTopic      string
 }
 
 // AuthConfig настройки аутентификации
@@ -69,6 +74,8 @@ type AuthConfig struct {
 	RefreshTokenSecret   string
 	AccessTokenDuration  time.Duration
 	RefreshTokenDuration time.Duration
+	// ShareTokenSecret - ключ подписи ссылок доступа к видео
+	ShareTokenSecret string
 }
 
 // StorageConfig настройки хранилища
@@ -76,6 +83,59 @@ type StorageConfig struct {
 	ShardCount int
 	BaseURL    string
 	UseSSL     bool
+	// Driver - minio или local
+	Driver string
+	// LocalPath - папка с бакетами для local
+	LocalPath string
+	// LocalPublicURL - адрес API, через который local отдает объекты
+	LocalPublicURL string
+	// SigningSecret - ключ подписи ссылок local
+	SigningSecret string
+	// UploadPartSize - размер части multipart загрузки в MinIO в байтах
+	UploadPartSize int64
+}
+
+// UploadConfig ограничения загружаемых видео, квоты пользователей хранятся в базе
+type UploadConfig struct {
+	// MaxFileSize - максимальный размер исходника в байтах
+	MaxFileSize int64
+	// MaxDuration - максимальная длительность видео, проверяется после ffprobe. 0 - без ограничения
+	MaxDuration time.Duration
+	// ImportTimeout - сколько может длиться скачивание видео по ссылке
+	ImportTimeout time.Duration
+	// MaxBatchSize - максимальный суммарный размер файлов пакетной загрузки в байтах
+	MaxBatchSize int64
+	// BatchTempDir - папка, где файлы пакета ждут загрузки в хранилище
+	BatchTempDir string
+}
+
+// ConversionConfig настройки конвертации видео
+type ConversionConfig struct {
+	TempDir string
+	// EmbeddedQueue запускает очередь конвертации внутри API. По умолчанию выключена:
+	// конвертацией занимаются воркеры (cmd/worker), включается только для запуска без них
+	EmbeddedQueue bool
+	// RetryPolicies политики повторов по классу сбоя (download, probe, ffmpeg, upload, internal, invalid_media, lease)
+	RetryPolicies map[string]RetryPolicy
+}
+
+// RetryPolicy политика повторов конвертации
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Backoff возвращает паузу перед следующей попыткой: InitialBackoff * 2^(attempt-1), не больше MaxBackoff
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	return backoff
 }
 
 // Load загружает конфигурацию из переменных окружения
@@ -118,17 +178,51 @@ func Load() (*Config, error) {
 		Kafka: KafkaConfig{
 			Brokers:              getEnvAsSlice("KAFKA_BROKERS", []string{"localhost:9092"}),
 			VideoProcessingTopic: getEnv("KAFKA_VIDEO_PROCESSING_TOPIC", "video-processing"),
+			ConsumerGroup:        getEnv("KAFKA_CONSUMER_GROUP", "video-conversion-workers"),
+			DeadLetterTopic:      getEnv("KAFKA_CONVERSION_DEAD_LETTER_TOPIC", "video-processing-dead-letter"),
 		},
 		Auth: AuthConfig{
 			AccessTokenSecret:    getEnv("AUTH_ACCESS_TOKEN_SECRET", "your_access_token_secret_key"),
 			RefreshTokenSecret:   getEnv("AUTH_REFRESH_TOKEN_SECRET", "your_refresh_token_secret_key"),
 			AccessTokenDuration:  getEnvAsDuration("AUTH_ACCESS_TOKEN_DURATION", 15*time.Minute),
 			RefreshTokenDuration: getEnvAsDuration("AUTH_REFRESH_TOKEN_DURATION", 7*24*time.Hour),
+			ShareTokenSecret:     getEnv("AUTH_SHARE_TOKEN_SECRET", "your_share_token_secret_key"),
 		},
 		Storage: StorageConfig{
 			ShardCount: getEnvAsInt("STORAGE_SHARD_COUNT", 64),
 			BaseURL:    getEnv("STORAGE_BASE_URL", "http://localhost:9000"),
 			UseSSL:     getEnvAsBool("STORAGE_BASE_USE_SSL", false),
+
+			Driver:         getEnv("STORAGE_DRIVER", constants.StorageDriverMinio),
+			LocalPath:      getEnv("STORAGE_LOCAL_PATH", "./data/storage"),
+			LocalPublicURL: getEnv("STORAGE_LOCAL_PUBLIC_URL", "http://localhost:8080"),
+			SigningSecret:  getEnv("STORAGE_SIGNING_SECRET", "your_storage_signing_secret_key"),
+			UploadPartSize: int64(getEnvAsInt("STORAGE_UPLOAD_PART_SIZE_MB", 64)) << 20,
+		},
+		Upload: UploadConfig{
+			MaxFileSize: int64(getEnvAsInt("UPLOAD_MAX_FILE_SIZE_MB", 10240)) << 20,
+			MaxDuration: getEnvAsDuration("UPLOAD_MAX_DURATION", 4*time.Hour),
+
+			ImportTimeout: getEnvAsDuration("UPLOAD_IMPORT_TIMEOUT", time.Hour),
+			MaxBatchSize:  int64(getEnvAsInt("UPLOAD_MAX_BATCH_SIZE_MB", 51200)) << 20,
+			BatchTempDir:  getEnv("UPLOAD_BATCH_TEMP_DIR", "/tmp/video-batches"),
+		},
+		Conversion: ConversionConfig{
+			TempDir:       getEnv("CONVERSION_TEMP_DIR", "/tmp/video-conversion"),
+			EmbeddedQueue: getEnvAsBool("CONVERSION_EMBEDDED_QUEUE", false),
+			RetryPolicies: map[string]RetryPolicy{
+				// Сетевые сбои хранилища обычно временные, повторяем чаще
+				constants.FailureClassDownload: getRetryPolicy("DOWNLOAD", RetryPolicy{5, 30 * time.Second, 10 * time.Minute}),
+				constants.FailureClassUpload:   getRetryPolicy("UPLOAD", RetryPolicy{5, 30 * time.Second, 10 * time.Minute}),
+				// Битый файл повторно не прочитается, но ffprobe может упасть по ресурсам
+				constants.FailureClassProbe:    getRetryPolicy("PROBE", RetryPolicy{2, time.Minute, 5 * time.Minute}),
+				constants.FailureClassFFmpeg:   getRetryPolicy("FFMPEG", RetryPolicy{3, 2 * time.Minute, 30 * time.Minute}),
+				constants.FailureClassInternal: getRetryPolicy("INTERNAL", RetryPolicy{3, time.Minute, 15 * time.Minute}),
+				// Файл без видеопотока не исправится, сразу отправляем в dead letter
+				constants.FailureClassInvalidMedia: getRetryPolicy("INVALID_MEDIA", RetryPolicy{1, time.Minute, time.Minute}),
+				// Воркер пропал, не продлив аренду, например упал по памяти на тяжелом файле
+				constants.FailureClassLease: getRetryPolicy("LEASE", RetryPolicy{3, time.Minute, 15 * time.Minute}),
+			},
 		},
 	}
 
@@ -178,6 +272,16 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	return value
 }
 
+// getRetryPolicy получает политику повторов из переменных CONVERSION_RETRY_<CLASS>_*
+func getRetryPolicy(class string, defaultValue RetryPolicy) RetryPolicy {
+	prefix := "CONVERSION_RETRY_" + class
+	return RetryPolicy{
+		MaxAttempts:    getEnvAsInt(prefix+"_MAX_ATTEMPTS", defaultValue.MaxAttempts),
+		InitialBackoff: getEnvAsDuration(prefix+"_INITIAL_BACKOFF", defaultValue.InitialBackoff),
+		MaxBackoff:     getEnvAsDuration(prefix+"_MAX_BACKOFF", defaultValue.MaxBackoff),
+	}
+}
+
 // getEnvAsSlice получает срез строк из переменной окружения
 func getEnvAsSlice(key string, defaultValue []string) []string {
 	valueStr := getEnv(key, "")
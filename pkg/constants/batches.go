@@ -0,0 +1,30 @@
+package constants
+
+import "time"
+
+// Статусы пакетной загрузки
+const (
+	BatchStatusProcessing = "processing"
+	BatchStatusCompleted  = "completed"
+)
+
+// Статусы файла пакетной загрузки
+const (
+	BatchItemStatusPending    = "pending"
+	BatchItemStatusProcessing = "processing"
+	BatchItemStatusCompleted  = "completed"
+	BatchItemStatusFailed     = "failed"
+)
+
+const (
+	// MaxBatchItems - сколько видео можно загрузить одним пакетом
+	MaxBatchItems = 100
+	// MaxBatchManifestSize - максимальный размер манифеста пакета
+	MaxBatchManifestSize = 1 << 20
+	// BatchStaleTimeout - пакет, не менявшийся так долго, считается прерванным: обрабатывавший его процесс упал
+	BatchStaleTimeout = 2 * time.Hour
+	// BatchCleanupInterval - как часто завершаются прерванные пакеты
+	BatchCleanupInterval = 10 * time.Minute
+	// MaxVideoTags - сколько тегов может быть у видео
+	MaxVideoTags = 20
+)
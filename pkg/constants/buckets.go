@@ -5,4 +5,18 @@ const (
 	VideoBucket      = "videos"
 	UserPhotoBucket  = "users"
 	ThumbnailsBucket = "thumbnails"
+	// UploadsBucket - служебный бакет незавершенных загрузок
+	UploadsBucket = "uploads"
 )
+
+// Реализации хранилища объектов
+const (
+	StorageDriverMinio = "minio"
+	// StorageDriverLocal хранит объекты на диске и отдает их через API по подписанным ссылкам
+	StorageDriverLocal = "local"
+)
+
+// PublicBuckets - бакеты, объекты которых доступны без подписи
+var PublicBuckets = map[string]bool{
+	ThumbnailsBucket: true,
+}
@@ -9,10 +9,175 @@ const (
 	MaxConcurrentConversions = 5
 	ConversionCheckInterval  = 30 * time.Second
 
+	// Аренда задания конвертации: воркер продлевает ее, пока жив,
+	// просроченные задания возвращаются в очередь
+	ConversionLeaseDuration     = 5 * time.Minute
+	ConversionHeartbeatInterval = 1 * time.Minute
+	MaxConversionAttempts       = 3
+
+	// Ожидающие задания воркерам отправляет через Kafka сборщик аренд. Если сообщение потерялось
+	// и задание так и не арендовали, через ConversionRedispatchInterval оно отправляется снова
+	ConversionRedispatchInterval = 5 * time.Minute
+	ConversionDispatchBatchSize  = 100
+
 	VideoQuality240p     = "240p"
 	VideoQuality480p     = "480p"
 	VideoQuality720p     = "720p"
 	VideoQuality1080p    = "1080p"
 	VideoQuality4k       = "4k"
 	VideoQualityOriginal = "original"
+	// VideoQualityAudio - служебное качество звуковых дорожек без видео
+	VideoQualityAudio = "audio"
+)
+
+// Адаптивный стриминг
+const (
+	StreamingFormatHLS  = "hls"
+	StreamingFormatDASH = "dash"
+
+	HLSMasterPlaylist  = "master.m3u8"
+	HLSVariantPlaylist = "index.m3u8"
+	HLSSegmentPattern  = "segment_%03d.m4s"
+	HLSInitSegment     = "init.mp4"
+	HLSSegmentDuration = 6
+
+	DASHManifest        = "manifest.mpd"
+	DASHSegmentDuration = 6
+
+	// Интервал ключевых кадров в секундах, должен делить HLSSegmentDuration
+	KeyframeInterval = 2
+)
+
+// Классы сбоев конвертации, для каждого своя политика повторов
+const (
+	FailureClassDownload = "download"
+	FailureClassProbe    = "probe"
+	FailureClassFFmpeg   = "ffmpeg"
+	FailureClassUpload   = "upload"
+	FailureClassInternal = "internal"
+	// FailureClassInvalidMedia - исходник не подходит для конвертации, например нет видеопотока
+	FailureClassInvalidMedia = "invalid_media"
+
+	// FailureClassLease - воркер пропал, не продлив аренду
+	FailureClassLease = "lease"
+)
+
+// Прогресс конвертации в Redis
+const (
+	// VideoProcessingKey - хэш качество -> процент готовности
+	VideoProcessingKey = "video:processing:%s"
+	// VideoProcessingChannel - канал уведомлений об изменении прогресса видео
+	VideoProcessingChannel = "video:processing:updates:%s"
+	VideoProcessingTTL     = 24 * time.Hour
+
+	// ProcessingStreamPollInterval - как часто SSE поток перечитывает статус без уведомлений
+	ProcessingStreamPollInterval = 15 * time.Second
+
+	QualityStatusPending    = "pending"
+	QualityStatusProcessing = "processing"
+	QualityStatusReady      = "ready"
+	QualityStatusFailed     = "failed"
+)
+
+// Профили транскодирования
+const (
+	VideoCodecH264 = "h264"
+	VideoCodecH265 = "h265"
+	VideoCodecVP9  = "vp9"
+	VideoCodecAV1  = "av1"
+
+	ContainerMP4  = "mp4"
+	ContainerWebM = "webm"
+	// ContainerM4A - mp4 только со звуком
+	ContainerM4A = "m4a"
+
+	RateControlCRF     = "crf"
+	RateControlBitrate = "bitrate"
+
+	AudioCodecAAC  = "aac"
+	AudioCodecOpus = "opus"
+)
+
+// Превью для перемотки: кадры через равные интервалы, сложенные в спрайты
+const (
+	SpriteInterval = 5
+	// SpriteMaxFrames - для длинных видео интервал увеличивается, чтобы кадров было не больше
+	SpriteMaxFrames    = 400
+	SpriteColumns      = 10
+	SpriteRows         = 10
+	SpriteFrameWidth   = 160
+	SpriteImagePattern = "sprite_%03d.jpg"
+	SpriteVTTFile      = "previews.vtt"
+)
+
+// Варианты обложки
+const (
+	ThumbnailSourceFrame  = "frame"
+	ThumbnailSourceScene  = "scene"
+	ThumbnailSourceCustom = "custom"
+
+	// ThumbnailSelectedPercent - кадр, выбранный обложкой по умолчанию
+	ThumbnailSelectedPercent = 50
+	// ThumbnailSceneThreshold - порог ffmpeg scene для поиска смены сцены
+	ThumbnailSceneThreshold = 0.4
+
+	// Загружаемая обложка
+	MaxThumbnailUploadSize = 5 * 1024 * 1024
+	MinThumbnailWidth      = 640
+	MinThumbnailHeight     = 360
+	ThumbnailJPEGQuality   = 85
+)
+
+// ThumbnailCandidatePercents - позиции кадров-кандидатов в процентах длительности
+var ThumbnailCandidatePercents = []int{25, 50, 75}
+
+// ThumbnailSize стандартный размер обложки
+type ThumbnailSize struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+// ThumbnailSizes - размеры загруженной обложки, от большего к меньшему
+var ThumbnailSizes = []ThumbnailSize{
+	{Name: "large", Width: 1280, Height: 720},
+	{Name: "medium", Width: 640, Height: 360},
+	{Name: "small", Width: 320, Height: 180},
+}
+
+// Превью-ролик для карточек видео
+const (
+	// PreviewClipSegments - количество фрагментов, равномерно взятых из видео
+	PreviewClipSegments = 4
+	// PreviewClipSegmentSeconds - длительность одного фрагмента
+	PreviewClipSegmentSeconds = 1.5
+	PreviewClipWidth          = 320
+	PreviewClipFPS            = 24
+	PreviewClipCRF            = 30
+	PreviewClipSuffix         = "_preview.mp4"
+)
+
+// Нормализация громкости по EBU R128
+const (
+	// LoudnessTargetIntegrated - целевая интегральная громкость, LUFS
+	LoudnessTargetIntegrated = -23.0
+	// LoudnessTargetTruePeak - максимальный истинный пик, dBTP
+	LoudnessTargetTruePeak = -1.0
+	// LoudnessTargetRange - целевой диапазон громкости, LU
+	LoudnessTargetRange = 7.0
+	// AudioSampleRate - loudnorm передискретизирует звук, возвращаем стандартную частоту
+	AudioSampleRate = 48000
+)
+
+// Субтитры
+const (
+	MaxSubtitleUploadSize = 2 * 1024 * 1024
+	// SubtitlesStoragePath - папка субтитров рядом с качествами видео
+	SubtitlesStoragePath = "subtitles"
+)
+
+// Источники субтитров
+const (
+	SubtitleSourceUpload   = "upload"
+	SubtitleSourceEmbedded = "embedded"
 )
@@ -0,0 +1,18 @@
+package constants
+
+// ConversionJobStatus определяет возможные статусы задания конвертации
+type ConversionJobStatus string
+
+const (
+	// ConversionJobStatusPending - задание ожидает воркера
+	ConversionJobStatusPending ConversionJobStatus = "pending"
+
+	// ConversionJobStatusProcessing - задание арендовано воркером
+	ConversionJobStatusProcessing ConversionJobStatus = "processing"
+
+	// ConversionJobStatusCompleted - задание выполнено
+	ConversionJobStatusCompleted ConversionJobStatus = "completed"
+
+	// ConversionJobStatusFailed - задание завершилось ошибкой
+	ConversionJobStatusFailed ConversionJobStatus = "failed"
+)
@@ -5,6 +5,9 @@ import "errors"
 // ErrNotFound ошибка, когда объект не найден
 var ErrNotFound = errors.New("not found")
 
+// ErrAlreadyExists ошибка, когда объект уже существует и не может быть заменен
+var ErrAlreadyExists = errors.New("already exists")
+
 // Ошибки видео сервиса
 var (
 	ErrVideoNotFound     = errors.New("video not found")
@@ -13,6 +16,8 @@ var (
 	ErrInvalidStatus     = errors.New("invalid video status")
 	ErrVideoProcessing   = errors.New("video is still processing")
 	ErrInvalidPagination = errors.New("invalid pagination parameters")
+	ErrStreamNotFound    = errors.New("stream not found")
+	ErrInvalidVisibility = errors.New("invalid video visibility")
 )
 
 // Ошибки сервиса аутентификации
@@ -22,3 +27,78 @@ var (
 	ErrInvalidToken       = errors.New("invalid token")
 	ErrTokenExpired       = errors.New("token expired")
 )
+
+// Ошибки конвертации
+var (
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	ErrNoVideoStream      = errors.New("file has no video stream")
+)
+
+// Ошибки обложек
+var (
+	ErrThumbnailNotFound = errors.New("thumbnail not found")
+	ErrInvalidThumbnail  = errors.New("invalid thumbnail image")
+	ErrThumbnailTooLarge = errors.New("thumbnail image is too large")
+	ErrThumbnailTooSmall = errors.New("thumbnail image is too small")
+)
+
+// Ошибки субтитров
+var (
+	ErrSubtitlesNotFound = errors.New("subtitles not found")
+	ErrInvalidSubtitles  = errors.New("invalid subtitles")
+	ErrSubtitlesTooLarge = errors.New("subtitles file is too large")
+	ErrInvalidLanguage   = errors.New("invalid subtitles language")
+)
+
+// Ошибки возобновляемой загрузки
+var (
+	ErrUploadNotFound       = errors.New("upload not found")
+	ErrUploadExpired        = errors.New("upload expired")
+	ErrUploadLocked         = errors.New("upload is locked by another request")
+	ErrUploadOffsetMismatch = errors.New("upload offset mismatch")
+	ErrUploadCompleted      = errors.New("upload already completed")
+	ErrInvalidUpload        = errors.New("invalid upload")
+	ErrUploadNotReceived    = errors.New("upload data not received")
+)
+
+// Ошибки хранилища
+var (
+	ErrInvalidSignature = errors.New("invalid or expired signature")
+	ErrPolicyViolation  = errors.New("upload violates post policy")
+)
+
+// Ошибки ограничений загрузки
+var (
+	ErrFileTooLarge             = errors.New("file is too large")
+	ErrVideoTooLong             = errors.New("video is too long")
+	ErrUnsupportedMediaType     = errors.New("file is not a supported video container")
+	ErrStorageQuotaExceeded     = errors.New("storage quota exceeded")
+	ErrDailyUploadQuotaExceeded = errors.New("daily upload quota exceeded")
+)
+
+// Ошибки импорта по ссылке
+var (
+	ErrImportNotFound       = errors.New("import not found")
+	ErrInvalidImportURL     = errors.New("invalid import URL")
+	ErrImportDownloadFailed = errors.New("failed to download video")
+)
+
+// Ошибки пакетной загрузки
+var (
+	ErrBatchNotFound = errors.New("batch not found")
+	ErrInvalidBatch  = errors.New("invalid batch")
+	ErrBatchTooLarge = errors.New("batch is too large")
+)
+
+// Ошибки ссылок и выдачи доступа к видео
+var (
+	ErrShareLinkNotFound     = errors.New("share link not found")
+	ErrInvalidShareExpiry    = errors.New("invalid share link expiry")
+	ErrInvalidShareLink      = errors.New("invalid share link")
+	ErrShareLinkExpired      = errors.New("share link expired")
+	ErrShareViewLimitReached = errors.New("share link view limit reached")
+	ErrSharePasswordRequired = errors.New("share link password required")
+	ErrInvalidSharePassword  = errors.New("invalid share link password")
+	ErrTooManyShareAttempts  = errors.New("too many share link password attempts")
+	ErrAccessGrantNotFound   = errors.New("access grant not found")
+)
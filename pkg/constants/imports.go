@@ -0,0 +1,24 @@
+package constants
+
+import "time"
+
+// Статусы импорта видео по ссылке
+const (
+	ImportStatusPending     = "pending"
+	ImportStatusDownloading = "downloading"
+	ImportStatusCompleted   = "completed"
+	ImportStatusFailed      = "failed"
+)
+
+const (
+	// MaxConcurrentImports - сколько ссылок один процесс скачивает одновременно
+	MaxConcurrentImports = 4
+	// ImportCheckInterval - как часто очередь импорта ищет новые задания
+	ImportCheckInterval = 5 * time.Second
+	// ImportMaxAttempts - сколько раз берется задание, аренда которого истекла (процесс упал во время скачивания)
+	ImportMaxAttempts = 3
+	// ImportMaxRedirects - сколько перенаправлений проходит скачивание
+	ImportMaxRedirects = 5
+	// ImportUserAgent - User-Agent запросов импорта
+	ImportUserAgent = "GoTube-Importer/1.0"
+)
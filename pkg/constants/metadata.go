@@ -0,0 +1,52 @@
+package constants
+
+// Ключи метаданных видео (videos.metadata), заполняются по результатам ffprobe
+const (
+	MetadataContainer    = "container"
+	MetadataDuration     = "duration"
+	MetadataBitRate      = "bit_rate"
+	MetadataCreationTime = "creation_time"
+
+	MetadataHasVideo     = "has_video"
+	MetadataVideoCodec   = "video_codec"
+	MetadataVideoProfile = "video_profile"
+	MetadataPixelFormat  = "pixel_format"
+	MetadataVideoBitRate = "video_bit_rate"
+	// Размеры кадра исходника с учетом поворота
+	MetadataWidth  = "width"
+	MetadataHeight = "height"
+	MetadataFPS    = "fps"
+	// MetadataRotation - поворот исходника по часовой стрелке в градусах
+	MetadataRotation       = "rotation"
+	MetadataColorPrimaries = "color_primaries"
+	MetadataColorTransfer  = "color_transfer"
+	MetadataHDR            = "hdr"
+	// MetadataHDRFormat - hdr10 или hlg
+	MetadataHDRFormat = "hdr_format"
+
+	MetadataHasAudio        = "has_audio"
+	MetadataAudioCodec      = "audio_codec"
+	MetadataAudioChannels   = "audio_channels"
+	MetadataAudioSampleRate = "audio_sample_rate"
+	MetadataAudioBitRate    = "audio_bit_rate"
+
+	// Номера основных потоков в файле, конвертация выбирает их явно
+	MetadataVideoStreamIndex = "video_stream_index"
+	MetadataAudioStreamIndex = "audio_stream_index"
+	// Языки всех звуковых дорожек и субтитров исходника, пустая строка - язык не указан
+	MetadataAudioLanguages    = "audio_languages"
+	MetadataSubtitleLanguages = "subtitle_languages"
+
+	// Замеры громкости первым проходом loudnorm
+	MetadataLoudnessIntegrated = "loudness_integrated"
+	MetadataLoudnessTruePeak   = "loudness_true_peak"
+	MetadataLoudnessRange      = "loudness_range"
+	MetadataLoudnessThreshold  = "loudness_threshold"
+	MetadataLoudnessOffset     = "loudness_offset"
+)
+
+// Форматы HDR по характеристике передачи (color_transfer)
+const (
+	HDRFormatHDR10 = "hdr10"
+	HDRFormatHLG   = "hlg"
+)
@@ -0,0 +1,40 @@
+package constants
+
+import "time"
+
+// Статусы возобновляемой загрузки
+const (
+	UploadStatusUploading  = "uploading"
+	UploadStatusCompleted  = "completed"
+	UploadStatusTerminated = "terminated"
+	UploadStatusExpired    = "expired"
+)
+
+// Способы загрузки исходника: через API по протоколу tus или формой прямо в хранилище
+const (
+	UploadKindTus    = "tus"
+	UploadKindDirect = "direct"
+)
+
+// Протокол tus 1.0
+const (
+	TusVersion = "1.0.0"
+	// TusExtensions - поддерживаемые расширения протокола
+	TusExtensions = "creation,termination,expiration"
+	// TusContentType - тип тела запроса PATCH
+	TusContentType = "application/offset+octet-stream"
+)
+
+const (
+	// UploadPartSize - размер части, которой куски tus пишутся в составную загрузку хранилища.
+	// Меньшие куски копятся в хвосте до размера части, ограничение S3 - 5 МБ
+	UploadPartSize = 16 << 20
+	// UploadExpiration - сколько живет незавершенная загрузка после последнего куска
+	UploadExpiration = 24 * time.Hour
+	// UploadCleanupInterval - как часто удаляются просроченные загрузки
+	UploadCleanupInterval = 10 * time.Minute
+	// UploadsStoragePath - папка хвостов загрузок в UploadsBucket
+	UploadsStoragePath = "tus"
+	// DirectUploadPolicyExpiration - сколько действует подписанная форма загрузки в хранилище
+	DirectUploadPolicyExpiration = time.Hour
+)
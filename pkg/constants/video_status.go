@@ -6,6 +6,15 @@ import "time"
 type VideoStatus string
 
 const (
+	// VideoStatusUploading - видео создано, исходник еще загружается
+	VideoStatusUploading VideoStatus = "uploading"
+
+	// VideoStatusImporting - видео создано, исходник скачивается по ссылке
+	VideoStatusImporting VideoStatus = "importing"
+
+	// VideoStatusImportFailed - исходник не удалось скачать по ссылке, причина в error_message
+	VideoStatusImportFailed VideoStatus = "import_failed"
+
 	// VideoStatusUploaded - видео загружено, но еще не в обработке
 	VideoStatusUploaded VideoStatus = "uploaded"
 
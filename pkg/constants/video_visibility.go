@@ -0,0 +1,47 @@
+package constants
+
+import "time"
+
+// Видимость видео
+const (
+	// VideoVisibilityPublic - видео в списках и доступно всем
+	VideoVisibilityPublic = "public"
+
+	// VideoVisibilityUnlisted - видео доступно по ссылке, но не попадает в списки
+	VideoVisibilityUnlisted = "unlisted"
+
+	// VideoVisibilityPrivate - видео доступно владельцу, администраторам, пользователям с выданным доступом и по ссылкам доступа
+	VideoVisibilityPrivate = "private"
+
+	// VideoVisibilityScheduled - видео доступно как приватное до publish_at, затем становится публичным
+	VideoVisibilityScheduled = "scheduled"
+)
+
+// VideoPublishCheckInterval - как часто публикуются видео, время публикации которых наступило
+const VideoPublishCheckInterval = time.Minute
+
+// Ссылки доступа к неопубликованным видео
+const (
+	// DefaultShareLinkTTL - срок действия ссылки, если владелец его не указал
+	DefaultShareLinkTTL = 7 * 24 * time.Hour
+
+	// MaxShareLinkTTL - максимальный срок действия ссылки
+	MaxShareLinkTTL = 365 * 24 * time.Hour
+
+	// ShareViewerTokenTTL - срок токена зрителя, который выдается в обмен на верный пароль ссылки
+	ShareViewerTokenTTL = 2 * time.Hour
+
+	// SharePasswordMaxAttempts - сколько неверных паролей ссылки можно ввести с одного IP за SharePasswordAttemptsWindow
+	SharePasswordMaxAttempts    = 5
+	SharePasswordAttemptsWindow = 15 * time.Minute
+
+	// SharePasswordAttemptsKey - счетчик неверных паролей ссылки с IP в Redis
+	SharePasswordAttemptsKey = "share:password_attempts:%s:%s"
+
+	// ShareViewWindow - сколько после списания просмотра ссылки с лимитом зритель с того же IP
+	// запрашивает плейлисты и файлы без нового списания
+	ShareViewWindow = 2 * time.Hour
+
+	// ShareViewKey - отметка в Redis о просмотре ссылки, уже списанном для IP
+	ShareViewKey = "share:view:%s:%s"
+)
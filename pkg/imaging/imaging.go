@@ -0,0 +1,90 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+)
+
+// Decode читает изображение в формате JPEG, PNG или GIF и возвращает его формат
+func Decode(reader io.Reader) (image.Image, string, error) {
+	return image.Decode(reader)
+}
+
+// DecodeConfig читает только размеры изображения, не декодируя его целиком
+func DecodeConfig(reader io.Reader) (image.Config, string, error) {
+	return image.DecodeConfig(reader)
+}
+
+// EncodeJPEG сохраняет изображение в JPEG с указанным качеством
+func EncodeJPEG(writer io.Writer, img image.Image, quality int) error {
+	return jpeg.Encode(writer, img, &jpeg.Options{Quality: quality})
+}
+
+// Cover обрезает изображение по центру до пропорций width x height
+// и масштабирует его до этого размера билинейной интерполяцией
+func Cover(src image.Image, width, height int) *image.RGBA {
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+
+	// Область исходника с пропорциями результата
+	cropWidth, cropHeight := srcWidth, srcWidth*height/width
+	if cropHeight > srcHeight {
+		cropWidth, cropHeight = srcHeight*width/height, srcHeight
+	}
+	offsetX := bounds.Min.X + (srcWidth-cropWidth)/2
+	offsetY := bounds.Min.Y + (srcHeight-cropHeight)/2
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	scaleX := float64(cropWidth) / float64(width)
+	scaleY := float64(cropHeight) / float64(height)
+
+	for y := 0; y < height; y++ {
+		sy := (float64(y)+0.5)*scaleY - 0.5
+		y0, fy := split(sy, cropHeight)
+		y1 := min(y0+1, cropHeight-1)
+
+		for x := 0; x < width; x++ {
+			sx := (float64(x)+0.5)*scaleX - 0.5
+			x0, fx := split(sx, cropWidth)
+			x1 := min(x0+1, cropWidth-1)
+
+			c00 := rgba(src, offsetX+x0, offsetY+y0)
+			c10 := rgba(src, offsetX+x1, offsetY+y0)
+			c01 := rgba(src, offsetX+x0, offsetY+y1)
+			c11 := rgba(src, offsetX+x1, offsetY+y1)
+
+			var out [4]uint8
+			for i := range out {
+				top := c00[i]*(1-fx) + c10[i]*fx
+				bottom := c01[i]*(1-fx) + c11[i]*fx
+				out[i] = uint8((top*(1-fy)+bottom*fy)/257 + 0.5)
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: out[0], G: out[1], B: out[2], A: out[3]})
+		}
+	}
+
+	return dst
+}
+
+// split возвращает целую часть координаты в пределах [0, size) и дробную часть
+func split(value float64, size int) (int, float64) {
+	if value < 0 {
+		return 0, 0
+	}
+
+	index := int(value)
+	if index >= size-1 {
+		return size - 1, 0
+	}
+
+	return index, value - float64(index)
+}
+
+func rgba(img image.Image, x, y int) [4]float64 {
+	r, g, b, a := img.At(x, y).RGBA()
+	return [4]float64{float64(r), float64(g), float64(b), float64(a)}
+}
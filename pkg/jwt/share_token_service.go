@@ -0,0 +1,120 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// ErrShareTokenExpired срок действия токена ссылки истек
+var ErrShareTokenExpired = errors.New("share token expired")
+
+// viewerAudience отличает токен зрителя, уже введшего пароль ссылки, от токена самой ссылки
+const viewerAudience = "share-viewer"
+
+// ShareClaims данные проверенного токена ссылки
+type ShareClaims struct {
+	LinkID  uuid.UUID
+	VideoID uuid.UUID
+	// PasswordVerified - токен зрителя, выданный в обмен на верный пароль ссылки
+	PasswordVerified bool
+}
+
+// ShareTokenService подписывает токены ссылок доступа к видео.
+// Токен содержит ID ссылки и видео, а срок действия совпадает со сроком ссылки
+type ShareTokenService struct {
+	secret []byte
+}
+
+// NewShareTokenService создает новый ShareTokenService
+func NewShareTokenService(secret string) *ShareTokenService {
+	return &ShareTokenService{
+		secret: []byte(secret),
+	}
+}
+
+// GenerateShareToken создает токен ссылки. Для одной ссылки токен всегда одинаковый,
+// поэтому его можно показать владельцу повторно
+func (s *ShareTokenService) GenerateShareToken(linkID, videoID uuid.UUID, issuedAt, expiresAt time.Time) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   videoID.String(),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(issuedAt),
+		ID:        linkID.String(),
+		Issuer:    "video-hosting",
+	})
+
+	tokenStr, err := token.SignedString(s.secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign share token: %w", err)
+	}
+
+	return tokenStr, nil
+}
+
+// GenerateViewerToken создает токен зрителя ссылки с паролем. Пароль проверяется один раз при выдаче,
+// дальше плеер передает этот токен вместо токена ссылки
+func (s *ShareTokenService) GenerateViewerToken(linkID, videoID uuid.UUID, expiresAt time.Time) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   videoID.String(),
+		Audience:  jwt.ClaimStrings{viewerAudience},
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ID:        linkID.String(),
+		Issuer:    "video-hosting",
+	})
+
+	tokenStr, err := token.SignedString(s.secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign viewer token: %w", err)
+	}
+
+	return tokenStr, nil
+}
+
+// ValidateShareToken проверяет подпись и срок токена ссылки или токена зрителя
+func (s *ShareTokenService) ValidateShareToken(tokenString string) (*ShareClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.secret, nil
+	})
+
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrShareTokenExpired
+		}
+		return nil, fmt.Errorf("invalid share token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid share token claims")
+	}
+
+	linkID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid share token id: %w", err)
+	}
+	videoID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid share token subject: %w", err)
+	}
+
+	passwordVerified := false
+	for _, audience := range claims.Audience {
+		if audience == viewerAudience {
+			passwordVerified = true
+		}
+	}
+
+	return &ShareClaims{
+		LinkID:           linkID,
+		VideoID:          videoID,
+		PasswordVerified: passwordVerified,
+	}, nil
+}
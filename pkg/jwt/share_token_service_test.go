@@ -0,0 +1,107 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestShareTokenService(t *testing.T) {
+	service := NewShareTokenService("secret")
+	other := NewShareTokenService("other-secret")
+
+	linkID := uuid.New()
+	videoID := uuid.New()
+	now := time.Now()
+
+	mustToken := func(token string, err error) string {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("failed to generate token: %v", err)
+		}
+		return token
+	}
+
+	linkToken := mustToken(service.GenerateShareToken(linkID, videoID, now, now.Add(time.Hour)))
+	viewerToken := mustToken(service.GenerateViewerToken(linkID, videoID, now.Add(time.Hour)))
+
+	noneToken := mustToken(jwt.NewWithClaims(jwt.SigningMethodNone, jwt.RegisteredClaims{
+		Subject:   videoID.String(),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		ID:        linkID.String(),
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType))
+
+	badIDToken := mustToken(jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   videoID.String(),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		ID:        "not-a-uuid",
+	}).SignedString([]byte("secret")))
+
+	tests := []struct {
+		name             string
+		token            string
+		wantErr          bool
+		wantExpired      bool
+		passwordVerified bool
+	}{
+		{name: "link token", token: linkToken},
+		{name: "viewer token", token: viewerToken, passwordVerified: true},
+		{name: "expired", token: mustToken(service.GenerateShareToken(linkID, videoID, now.Add(-2*time.Hour), now.Add(-time.Hour))), wantErr: true, wantExpired: true},
+		{name: "expired viewer token", token: mustToken(service.GenerateViewerToken(linkID, videoID, now.Add(-time.Minute))), wantErr: true, wantExpired: true},
+		{name: "other secret", token: mustToken(other.GenerateShareToken(linkID, videoID, now, now.Add(time.Hour))), wantErr: true},
+		{name: "tampered", token: linkToken[:len(linkToken)-2] + "xx", wantErr: true},
+		{name: "unsigned", token: noneToken, wantErr: true},
+		{name: "malformed id", token: badIDToken, wantErr: true},
+		{name: "garbage", token: "not-a-token", wantErr: true},
+		{name: "empty", token: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := service.ValidateShareToken(tt.token)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ValidateShareToken() = %+v, want error", claims)
+				}
+				if got := errors.Is(err, ErrShareTokenExpired); got != tt.wantExpired {
+					t.Errorf("ValidateShareToken() error = %v, expired = %v, want %v", err, got, tt.wantExpired)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateShareToken(): %v", err)
+			}
+			if claims.LinkID != linkID || claims.VideoID != videoID {
+				t.Errorf("ValidateShareToken() = %+v, want link %s video %s", claims, linkID, videoID)
+			}
+			if claims.PasswordVerified != tt.passwordVerified {
+				t.Errorf("ValidateShareToken() PasswordVerified = %v, want %v", claims.PasswordVerified, tt.passwordVerified)
+			}
+		})
+	}
+}
+
+func TestShareTokenDeterministic(t *testing.T) {
+	service := NewShareTokenService("secret")
+
+	linkID := uuid.New()
+	videoID := uuid.New()
+	createdAt := time.Now().Add(-time.Hour)
+	expiresAt := time.Now().Add(time.Hour)
+
+	first, err := service.GenerateShareToken(linkID, videoID, createdAt, expiresAt)
+	if err != nil {
+		t.Fatalf("GenerateShareToken(): %v", err)
+	}
+	second, err := service.GenerateShareToken(linkID, videoID, createdAt, expiresAt)
+	if err != nil {
+		t.Fatalf("GenerateShareToken(): %v", err)
+	}
+
+	if first != second {
+		t.Errorf("GenerateShareToken() is not deterministic: %q != %q", first, second)
+	}
+}
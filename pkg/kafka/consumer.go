@@ -0,0 +1,126 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// retryBackoff пауза перед повторным чтением после ошибки обработки
+const retryBackoff = 10 * time.Second
+
+// VideoProcessingHandler обрабатывает сообщение об обработке видео
+type VideoProcessingHandler func(ctx context.Context, msg VideoProcessingMessage) error
+
+// Consumer читает сообщения об обработке видео в составе consumer group
+type Consumer struct {
+	group sarama.ConsumerGroup
+	topic string
+}
+
+// NewConsumer создает новый Kafka Consumer
+func NewConsumer(brokers []string, groupID, topic string) (*Consumer, error) {
+	config := sarama.NewConfig()
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	// Оффсет фиксируется вручную и только после успешной обработки
+	config.Consumer.Offsets.AutoCommit.Enable = false
+	config.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{
+		sarama.NewBalanceStrategyRoundRobin(),
+	}
+
+	group, err := sarama.NewConsumerGroup(brokers, groupID, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka consumer group: %w", err)
+	}
+
+	return &Consumer{
+		group: group,
+		topic: topic,
+	}, nil
+}
+
+// Consume читает сообщения до отмены контекста.
+// При ошибке обработчика оффсет не фиксируется и сообщение будет прочитано повторно
+func (c *Consumer) Consume(ctx context.Context, handler VideoProcessingHandler) error {
+	groupHandler := &consumerGroupHandler{handler: handler}
+
+	for {
+		groupHandler.failed.Store(false)
+		if err := c.group.Consume(ctx, []string{c.topic}, groupHandler); err != nil {
+			if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+				return nil
+			}
+			log.Printf("Kafka consume error: %v", err)
+			groupHandler.failed.Store(true)
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if groupHandler.failed.Load() {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(retryBackoff):
+			}
+		}
+	}
+}
+
+// Close закрывает соединение с Kafka
+func (c *Consumer) Close() error {
+	return c.group.Close()
+}
+
+type consumerGroupHandler struct {
+	handler VideoProcessingHandler
+	// failed выставляется из горутин разных партиций
+	failed atomic.Bool
+}
+
+func (h *consumerGroupHandler) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *consumerGroupHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for {
+		select {
+		case <-session.Context().Done():
+			return nil
+		case message, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+
+			var msg VideoProcessingMessage
+			if err := json.Unmarshal(message.Value, &msg); err != nil {
+				// Битое сообщение повторно не обработать, пропускаем его
+				log.Printf("Skipping malformed message at %s/%d/%d: %v", message.Topic, message.Partition, message.Offset, err)
+				session.MarkMessage(message, "")
+				session.Commit()
+				continue
+			}
+
+			if err := h.handler(session.Context(), msg); err != nil {
+				// Завершаем сессию без фиксации, сообщение придет снова после повторного подключения
+				log.Printf("Failed to process message for video %s: %v", msg.VideoID, err)
+				h.failed.Store(true)
+				return err
+			}
+
+			session.MarkMessage(message, "")
+			session.Commit()
+		}
+	}
+}
@@ -20,6 +20,17 @@ type VideoProcessingMessage struct {
 	Filename     string    `json:"filename"`
 }
 
+// ConversionDeadLetterMessage сообщение о задании конвертации, исчерпавшем попытки
+type ConversionDeadLetterMessage struct {
+	DeadLetterID uuid.UUID `json:"dead_letter_id"`
+	JobID        uuid.UUID `json:"job_id"`
+	VideoID      uuid.UUID `json:"video_id"`
+	FailureClass string    `json:"failure_class"`
+	Error        string    `json:"error"`
+	Attempts     int       `json:"attempts"`
+	FailedAt     time.Time `json:"failed_at"`
+}
+
 // Producer клиент для отправки сообщений в Kafka
 type Producer struct {
 	producer sarama.SyncProducer
@@ -68,6 +79,27 @@ func (p *Producer) SendVideoProcessingMessage(ctx context.Context, msg VideoProc
 	return nil
 }
 
+// SendConversionDeadLetterMessage отправляет сообщение о задании конвертации в dead letter топик
+func (p *Producer) SendConversionDeadLetterMessage(ctx context.Context, msg ConversionDeadLetterMessage) error {
+	value, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	kafkaMsg := &sarama.ProducerMessage{
+		Topic: p.topic,
+		Key:   sarama.StringEncoder(msg.VideoID.String()),
+		Value: sarama.ByteEncoder(value),
+	}
+
+	_, _, err = p.producer.SendMessage(kafkaMsg)
+	if err != nil {
+		return fmt.Errorf("failed to send message to Kafka: %w", err)
+	}
+
+	return nil
+}
+
 // Close закрывает соединение с Kafka
 func (p *Producer) Close() error {
 	return p.producer.Close()
@@ -0,0 +1,129 @@
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// Ошибки проверки адресов
+var (
+	ErrBlockedAddress = errors.New("address is not allowed")
+	ErrInvalidURL     = errors.New("only http and https URLs are allowed")
+)
+
+// blockedPrefixes - диапазоны, которых нет среди методов netip.Addr, но которые не должны быть доступны по ссылке пользователя
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "эта" сеть
+	netip.MustParsePrefix("100.64.0.0/10"),   // CGNAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),   // тестирование производительности
+	netip.MustParsePrefix("240.0.0.0/4"),     // зарезервировано, включая broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64 ведет на IPv4 адреса, в том числе внутренние
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // локальный NAT64
+	netip.MustParsePrefix("2001:db8::/32"),   // документация
+	netip.MustParsePrefix("2002::/16"),       // 6to4 содержит IPv4 адрес
+	netip.MustParsePrefix("fec0::/10"),       // устаревшие site-local
+	netip.MustParsePrefix("100::/64"),        // discard-only
+	netip.MustParsePrefix("2001::/32"),       // Teredo содержит IPv4 адрес
+	netip.MustParsePrefix("::ffff:0:0:0/96"), // IPv4-translated
+}
+
+// IsPublicAddress проверяет, что адрес маршрутизируется в интернете: не loopback, не частная сеть,
+// не link-local (в том числе метаданные облака 169.254.169.254), не multicast и не зарезервированный диапазон
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ValidateURL проверяет схему и хост ссылки. Адрес, заданный IP, проверяется сразу, имя хоста - при подключении
+func ValidateURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, ErrInvalidURL
+	}
+	// Логин и пароль в ссылке попали бы в журналы и ответы API
+	if u.User != nil {
+		return nil, fmt.Errorf("%w: credentials in URL are not allowed", ErrInvalidURL)
+	}
+
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil && !IsPublicAddress(addr) {
+		return nil, ErrBlockedAddress
+	}
+
+	return u, nil
+}
+
+// NewClient создает HTTP клиент, который подключается только к публичным адресам.
+// Адрес проверяется после разрешения имени, непосредственно перед подключением, поэтому DNS rebinding
+// и перенаправления на внутренние адреса не проходят. Прокси из окружения не используется, он обошел бы проверку
+func NewClient(timeout time.Duration, maxRedirects int) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   controlAddress,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: time.Minute,
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if _, err := ValidateURL(req.URL.String()); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+}
+
+// controlAddress вызывается для каждого разрешенного адреса перед подключением
+func controlAddress(network, address string, _ syscall.RawConn) error {
+	if network != "tcp4" && network != "tcp6" {
+		return fmt.Errorf("%w: network %s", ErrBlockedAddress, network)
+	}
+
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBlockedAddress, err)
+	}
+
+	if !IsPublicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addrPort.Addr())
+	}
+
+	return nil
+}
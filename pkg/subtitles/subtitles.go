@@ -0,0 +1,244 @@
+package subtitles
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// Ошибки разбора и проверки субтитров
+var (
+	ErrInvalidFormat = errors.New("invalid subtitles format")
+	ErrInvalidTiming = errors.New("invalid subtitles timing")
+	ErrNoCues        = errors.New("subtitles have no cues")
+)
+
+// Cue фраза субтитров
+type Cue struct {
+	// ID - идентификатор фразы WebVTT, у SRT пустой
+	ID    string
+	Start time.Duration
+	End   time.Duration
+	// Settings - настройки расположения фразы WebVTT
+	Settings string
+	Text     string
+}
+
+// timingLine строка времени фразы: SRT использует запятую, WebVTT точку, часы в WebVTT необязательны
+var timingLine = regexp.MustCompile(`^((?:\d+:)?\d{1,2}:\d{2}[.,]\d{1,3})\s+-->\s+((?:\d+:)?\d{1,2}:\d{2}[.,]\d{1,3})(.*)$`)
+
+// fontTag - теги font из SRT, в WebVTT их нет
+var fontTag = regexp.MustCompile(`(?i)</?font[^>]*>`)
+
+// Parse разбирает субтитры в формате SRT или WebVTT, формат определяется по заголовку WEBVTT
+func Parse(data []byte) ([]Cue, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	blocks := splitBlocks(text)
+	if len(blocks) == 0 {
+		return nil, ErrNoCues
+	}
+
+	isVTT := strings.HasPrefix(blocks[0][0], "WEBVTT")
+	if isVTT {
+		blocks = blocks[1:]
+	}
+
+	cues := make([]Cue, 0, len(blocks))
+	for i, lines := range blocks {
+		if isVTT && isVTTMetaBlock(lines[0]) {
+			continue
+		}
+
+		cue, err := parseCue(lines, isVTT)
+		if err != nil {
+			return nil, fmt.Errorf("%w: block %d: %v", ErrInvalidFormat, i+1, err)
+		}
+		// Фразы без текста плееру не нужны
+		if cue.Text == "" {
+			continue
+		}
+		cues = append(cues, cue)
+	}
+
+	if len(cues) == 0 {
+		return nil, ErrNoCues
+	}
+
+	return cues, nil
+}
+
+// Validate проверяет, что фразы идут по порядку, заканчиваются после начала
+// и не выходят за длительность видео. Длительность 0 не проверяется
+func Validate(cues []Cue, duration time.Duration) error {
+	if len(cues) == 0 {
+		return ErrNoCues
+	}
+
+	// Длительность видео округлена до секунд
+	limit := duration + time.Second
+	for i, cue := range cues {
+		if cue.End <= cue.Start {
+			return fmt.Errorf("%w: cue %d ends before it starts", ErrInvalidTiming, i+1)
+		}
+		if i > 0 && cue.Start < cues[i-1].Start {
+			return fmt.Errorf("%w: cue %d starts before the previous one", ErrInvalidTiming, i+1)
+		}
+		if duration > 0 && cue.End > limit {
+			return fmt.Errorf("%w: cue %d ends after the video", ErrInvalidTiming, i+1)
+		}
+	}
+
+	return nil
+}
+
+// WriteVTT формирует WebVTT из фраз
+func WriteVTT(cues []Cue) []byte {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+
+	for _, cue := range cues {
+		if cue.ID != "" {
+			b.WriteString(cue.ID + "\n")
+		}
+		fmt.Fprintf(&b, "%s --> %s", FormatTime(cue.Start), FormatTime(cue.End))
+		if cue.Settings != "" {
+			b.WriteString(" " + cue.Settings)
+		}
+		b.WriteString("\n" + cue.Text + "\n\n")
+	}
+
+	return []byte(b.String())
+}
+
+// NormalizeLanguage приводит тег языка к каноническому BCP 47: eng -> en, PT-br -> pt-BR
+func NormalizeLanguage(tag string) (string, error) {
+	parsed, err := language.Parse(strings.TrimSpace(tag))
+	if err != nil {
+		return "", err
+	}
+	return parsed.String(), nil
+}
+
+// FormatTime форматирует время фразы WebVTT (HH:MM:SS.mmm)
+func FormatTime(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// splitBlocks делит текст на блоки строк, разделенные пустыми строками
+func splitBlocks(text string) [][]string {
+	var blocks [][]string
+	var current []string
+
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, current)
+	}
+
+	return blocks
+}
+
+// isVTTMetaBlock - блоки WebVTT, которые не являются фразами
+func isVTTMetaBlock(first string) bool {
+	for _, prefix := range []string{"NOTE", "STYLE", "REGION"} {
+		if first == prefix || strings.HasPrefix(first, prefix+" ") || strings.HasPrefix(first, prefix+"\t") {
+			return true
+		}
+	}
+	return false
+}
+
+func parseCue(lines []string, isVTT bool) (Cue, error) {
+	var cue Cue
+
+	// Перед строкой времени может быть номер SRT или идентификатор WebVTT
+	if !strings.Contains(lines[0], "-->") {
+		if isVTT {
+			cue.ID = strings.TrimSpace(lines[0])
+		}
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return cue, errors.New("timing line is missing")
+	}
+
+	match := timingLine.FindStringSubmatch(strings.TrimSpace(lines[0]))
+	if match == nil {
+		return cue, fmt.Errorf("invalid timing line %q", lines[0])
+	}
+
+	var err error
+	if cue.Start, err = parseTime(match[1]); err != nil {
+		return cue, err
+	}
+	if cue.End, err = parseTime(match[2]); err != nil {
+		return cue, err
+	}
+	// Координаты SRT (X1:... Y2:...) в WebVTT не переносятся
+	if isVTT {
+		cue.Settings = strings.TrimSpace(match[3])
+	}
+
+	text := strings.TrimSpace(strings.Join(lines[1:], "\n"))
+	if !isVTT {
+		text = fontTag.ReplaceAllString(text, "")
+	}
+	// Стрелка в тексте ломает разбор WebVTT
+	cue.Text = strings.ReplaceAll(text, "-->", "--&gt;")
+
+	return cue, nil
+}
+
+// parseTime разбирает время фразы [HH:]MM:SS[.,]mmm
+func parseTime(value string) (time.Duration, error) {
+	value = strings.Replace(value, ",", ".", 1)
+	clock, fraction, _ := strings.Cut(value, ".")
+
+	parts := strings.Split(clock, ":")
+	if len(parts) == 2 {
+		parts = append([]string{"0"}, parts...)
+	}
+
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid hours in %q", value)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes > 59 {
+		return 0, fmt.Errorf("invalid minutes in %q", value)
+	}
+	seconds, err := strconv.Atoi(parts[2])
+	if err != nil || seconds > 59 {
+		return 0, fmt.Errorf("invalid seconds in %q", value)
+	}
+
+	// Доли секунды с 1-2 цифрами дополняем до миллисекунд
+	fraction = (fraction + "00")[:3]
+	millis, err := strconv.Atoi(fraction)
+	if err != nil {
+		return 0, fmt.Errorf("invalid milliseconds in %q", value)
+	}
+
+	return time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds)*time.Second +
+		time.Duration(millis)*time.Millisecond, nil
+}